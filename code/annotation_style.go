@@ -0,0 +1,86 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+)
+
+// annotationColor is a colour the image editor can draw with
+type annotationColor struct {
+	Name  string
+	Color color.RGBA
+}
+
+// annotationPalette is selected with the number keys 1-5; red is the default
+var annotationPalette = []annotationColor{
+	{"red", color.RGBA{R: 255, A: 255}},
+	{"yellow", color.RGBA{R: 255, G: 221, A: 255}},
+	{"green", color.RGBA{G: 200, B: 60, A: 255}},
+	{"blue", color.RGBA{R: 30, G: 110, B: 255, A: 255}},
+	{"white", color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+}
+
+// Line widths available in the image editor, in pixels
+const (
+	defaultAnnotationWidth = 2
+	minAnnotationWidth     = 1
+	maxAnnotationWidth     = 12
+)
+
+// paletteKeys maps the number keys to palette entries
+var paletteKeys = map[fyne.KeyName]int{
+	fyne.Key1: 0,
+	fyne.Key2: 1,
+	fyne.Key3: 2,
+	fyne.Key4: 3,
+	fyne.Key5: 4,
+}
+
+// colorName returns the palette name of c, or its hex value if it is not in the palette
+func colorName(c color.RGBA) string {
+	for _, entry := range annotationPalette {
+		if entry.Color == c {
+			return entry.Name
+		}
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// styleDescription describes the current drawing style, e.g. "red, 2px"
+func (c *imageEditorCanvas) styleDescription() string {
+	return fmt.Sprintf("%s, %dpx", colorName(c.drawColor), c.drawWidth)
+}
+
+// handleStyleKey changes the drawing colour (1-5) or width (+/-) for annotations drawn
+// from now on. It returns false for keys that are not style keys.
+func (c *imageEditorCanvas) handleStyleKey(key fyne.KeyName) bool {
+	if index, ok := paletteKeys[key]; ok {
+		c.drawColor = annotationPalette[index].Color
+	} else {
+		switch key {
+		case fyne.KeyEqual, fyne.KeyPlus:
+			c.drawWidth = min(c.drawWidth+1, maxAnnotationWidth)
+		case fyne.KeyMinus:
+			c.drawWidth = max(c.drawWidth-1, minAnnotationWidth)
+		default:
+			return false
+		}
+	}
+	Debugf("Image editor: drawing style set to %s", c.styleDescription())
+	return true
+}
@@ -0,0 +1,145 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+)
+
+// Annotation is a shape drawn over a screenshot in the image editor
+type Annotation interface {
+	// Extend updates the shape while the mouse is dragged to (x, y)
+	Extend(x, y int)
+	// Draw renders the shape onto img
+	Draw(img *image.RGBA)
+}
+
+// annotationTool selects which shape dragging in the image editor creates
+type annotationTool int
+
+const (
+	toolArrow annotationTool = iota // Default
+	toolRectangle
+	toolFreehand
+	toolRedact
+	toolText
+	toolCount // Number of tools, not a tool itself
+)
+
+// String returns the name of the tool
+func (t annotationTool) String() string {
+	switch t {
+	case toolRectangle:
+		return "rectangle"
+	case toolFreehand:
+		return "freehand"
+	case toolRedact:
+		return "redact"
+	case toolText:
+		return "text"
+	default:
+		return "arrow"
+	}
+}
+
+// next returns the tool after t, wrapping around to the arrow
+func (t annotationTool) next() annotationTool {
+	return (t + 1) % toolCount
+}
+
+// Extend implements Annotation by moving the arrow's head
+func (a *Arrow) Extend(x, y int) {
+	a.EndX, a.EndY = x, y
+}
+
+// Draw implements Annotation
+func (a *Arrow) Draw(img *image.RGBA) {
+	drawArrow(img, *a)
+}
+
+// Rectangle is an outlined rectangle between two corners
+type Rectangle struct {
+	StartX, StartY int
+	EndX, EndY     int
+	Color          color.RGBA
+	Width          int
+}
+
+// Extend implements Annotation by moving the opposite corner
+func (r *Rectangle) Extend(x, y int) {
+	r.EndX, r.EndY = x, y
+}
+
+// Draw implements Annotation
+func (r *Rectangle) Draw(img *image.RGBA) {
+	width := max(r.Width, minAnnotationWidth)
+	drawLine(img, r.StartX, r.StartY, r.EndX, r.StartY, r.Color, width)
+	drawLine(img, r.EndX, r.StartY, r.EndX, r.EndY, r.Color, width)
+	drawLine(img, r.EndX, r.EndY, r.StartX, r.EndY, r.Color, width)
+	drawLine(img, r.StartX, r.EndY, r.StartX, r.StartY, r.Color, width)
+}
+
+// Freehand is a polyline following the mouse while it was dragged
+type Freehand struct {
+	Points []image.Point
+	Color  color.RGBA
+	Width  int
+}
+
+// Extend implements Annotation by adding a point when the mouse has moved
+func (f *Freehand) Extend(x, y int) {
+	point := image.Pt(x, y)
+	if len(f.Points) > 0 && f.Points[len(f.Points)-1] == point {
+		return
+	}
+	f.Points = append(f.Points, point)
+}
+
+// Draw implements Annotation
+func (f *Freehand) Draw(img *image.RGBA) {
+	width := max(f.Width, minAnnotationWidth)
+	for i := 1; i < len(f.Points); i++ {
+		from, to := f.Points[i-1], f.Points[i]
+		drawLine(img, from.X, from.Y, to.X, to.Y, f.Color, width)
+	}
+}
+
+// newAnnotation starts a shape of the current tool and style at (x, y)
+func (c *imageEditorCanvas) newAnnotation(x, y int) Annotation {
+	switch c.tool {
+	case toolRectangle:
+		return &Rectangle{StartX: x, StartY: y, EndX: x, EndY: y, Color: c.drawColor, Width: c.drawWidth}
+	case toolFreehand:
+		return &Freehand{Points: []image.Point{image.Pt(x, y)}, Color: c.drawColor, Width: c.drawWidth}
+	case toolRedact:
+		return &Redaction{StartX: x, StartY: y, EndX: x, EndY: y}
+	case toolText:
+		return &TextAnnotation{X: x, Y: y, Color: c.drawColor, Size: textSizeForWidth(c.drawWidth)}
+	default:
+		return &Arrow{StartX: x, StartY: y, EndX: x, EndY: y, Color: c.drawColor, Width: c.drawWidth}
+	}
+}
+
+// handleToolKey switches to the next drawing tool when T is pressed
+func (c *imageEditorCanvas) handleToolKey(key fyne.KeyName) bool {
+	if key != fyne.KeyT {
+		return false
+	}
+	c.tool = c.tool.next()
+	Debugf("Image editor: tool set to %s", c.tool)
+	return true
+}
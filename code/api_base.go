@@ -0,0 +1,46 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// defaultOpenAIBaseURL is the official API root, used when OPENAI_BASE_URL is unset
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// resolveBaseURL validates an OpenAI-compatible API root such as
+// "http://localhost:4000/v1" and returns it without a trailing slash.
+// An empty value selects the official API.
+func resolveBaseURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultOpenAIBaseURL, nil
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid API base URL %q: %v", raw, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("invalid API base URL %q: scheme must be http or https", raw)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("invalid API base URL %q: missing host", raw)
+	}
+
+	return strings.TrimRight(raw, "/"), nil
+}
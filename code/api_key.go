@@ -0,0 +1,193 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// apiKeyPrefKey is the preferences key storing an API key entered in the window
+const apiKeyPrefKey = "openaiAPIKey"
+
+// errMissingAPIKey is returned by createClients when an OpenAI backend is
+// configured but no API key is available
+var errMissingAPIKey = errors.New("OPENAI_API_KEY is not set")
+
+// loadSavedAPIKey sets OPENAI_API_KEY from the key saved in the preferences when
+// the environment doesn't provide one. The environment variable takes precedence.
+func loadSavedAPIKey(prefs fyne.Preferences) {
+	if os.Getenv("OPENAI_API_KEY") != "" {
+		return
+	}
+	if key := prefs.String(apiKeyPrefKey); key != "" {
+		os.Setenv("OPENAI_API_KEY", key)
+		Infof("Using the OpenAI API key saved in preferences")
+	}
+}
+
+// createClients creates the transcription and correction backends. It returns
+// errMissingAPIKey, leaving both nil, when an OpenAI backend has no key.
+func (a *AppState) createClients() error {
+	if a.config.usesOpenAI() && os.Getenv("OPENAI_API_KEY") == "" {
+		return errMissingAPIKey
+	}
+
+	// Create the transcription backend (OpenAI unless a local one is configured)
+	transcriber, err := newTranscriber(a.config, a.audioStorage)
+	if err != nil {
+		return fmt.Errorf("failed to create transcriber: %v", err)
+	}
+
+	// Create LLM client for text correction
+	llmClient, err := newCorrector(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %v", err)
+	}
+
+	a.setClients(transcriber, llmClient)
+	return nil
+}
+
+// clients returns the transcription and correction backends. Both are nil while
+// there is no usable API key.
+func (a *AppState) clients() (Transcriber, Corrector) {
+	a.clientsMutex.Lock()
+	defer a.clientsMutex.Unlock()
+	return a.transcriber, a.llmClient
+}
+
+// setClients replaces both backends, applying the custom correction instructions
+// to the new corrector. Requests already running keep the clients they started with.
+func (a *AppState) setClients(transcriber Transcriber, llmClient Corrector) {
+	a.clientsMutex.Lock()
+	defer a.clientsMutex.Unlock()
+	if llmClient != nil && a.instructions != "" {
+		llmClient.SetInstructions(a.instructions)
+	}
+	a.transcriber = transcriber
+	a.llmClient = llmClient
+}
+
+// clientsReady reports whether transcription is available, telling the user to
+// enter an API key if it isn't
+func (a *AppState) clientsReady() bool {
+	if transcriber, _ := a.clients(); transcriber != nil {
+		return true
+	}
+	setStatusText(a.statusLabel, "Enter your OpenAI API key above to start")
+	return false
+}
+
+// clientsCreated enables recording and hides the API key banner once the
+// clients exist after the user entered a key
+func (a *AppState) clientsCreated() {
+	a.setRecordingEnabled(true)
+	if a.apiKeyBanner != nil {
+		a.apiKeyBanner.Hide()
+	}
+}
+
+// showAPIKeyBanner shows the API key banner with text and disables recording
+func (a *AppState) showAPIKeyBanner(text string) {
+	a.setRecordingEnabled(false)
+	if a.apiKeyBanner == nil {
+		return
+	}
+	a.apiKeyMessage.SetText(text)
+	a.apiKeyBanner.Show()
+}
+
+// setRecordingEnabled enables or disables the buttons that start a recording
+func (a *AppState) setRecordingEnabled(enabled bool) {
+	for _, button := range []*widget.Button{a.recordButton, a.addButton, a.liveButton} {
+		if button == nil {
+			continue
+		}
+		if enabled {
+			button.Enable()
+		} else {
+			button.Disable()
+		}
+	}
+}
+
+// newAPIKeyBanner builds the banner asking for an API key, shown only while the
+// clients could not be created for lack of one. Saving a key that works creates
+// the clients, stores the key in the preferences and enables recording.
+func (a *AppState) newAPIKeyBanner(prefs fyne.Preferences) fyne.CanvasObject {
+	message := widget.NewLabel("No OpenAI API key found. Paste your key to enable transcription and correction:")
+	message.Importance = widget.DangerImportance
+	message.Wrapping = fyne.TextWrapWord
+
+	entry := widget.NewPasswordEntry()
+	entry.SetPlaceHolder("sk-...")
+
+	var saveButton *widget.Button
+	saveKey := func() {
+		key := strings.TrimSpace(entry.Text)
+		if key == "" {
+			return
+		}
+		saveButton.Disable()
+		message.SetText("Checking the key...")
+		go func() {
+			defer fyne.Do(saveButton.Enable)
+			if err := validateAPIKey(a.ctx, a.config.OpenAIBaseURL, key); err != nil {
+				Warnf("Entered API key failed validation: %v", err)
+				fyne.Do(func() { message.SetText(describeKeyError(err)) })
+				return
+			}
+
+			os.Setenv("OPENAI_API_KEY", key)
+			if err := a.createClients(); err != nil {
+				Errorf("Failed to create clients with the entered API key: %v", err)
+				fyne.Do(func() { message.SetText(fmt.Sprintf("Could not use this key: %v", err)) })
+				return
+			}
+			prefs.SetString(apiKeyPrefKey, key)
+			prefs.SetString(apiKeyCheckedPrefKey, apiKeyFingerprint(key))
+			Infof("OpenAI API key verified and saved")
+			fyne.Do(func() {
+				a.clientsCreated()
+				entry.SetText("")
+				setStatusText(a.statusLabel, "API key verified and saved, ready")
+			})
+		}()
+	}
+	entry.OnSubmitted = func(string) { saveKey() }
+	saveButton = widget.NewButton("Save key", saveKey)
+
+	banner := container.NewVBox(
+		message,
+		container.NewBorder(nil, nil, nil, saveButton, entry),
+		widget.NewSeparator(),
+	)
+
+	a.apiKeyBanner = banner
+	a.apiKeyMessage = message
+	if transcriber, _ := a.clients(); transcriber != nil {
+		banner.Hide()
+	} else {
+		a.setRecordingEnabled(false)
+		setStatusText(a.statusLabel, "Enter your OpenAI API key above to start")
+	}
+	return banner
+}
@@ -0,0 +1,59 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+)
+
+// appDataDir returns the per-user folder Fyne keeps this application's files in
+// (e.g. ~/.config/fyne/com.voicetranscriber.app on Linux). Without an app it
+// falls back to the working directory, where these files used to live.
+func appDataDir() string {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return "."
+	}
+	dir := app.Storage().RootURI().Path()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		Warnf("Failed to create the application data folder %s, using the working directory: %v", dir, err)
+		return "."
+	}
+	return dir
+}
+
+// moveToAppData moves name from the working directory into dir, so files
+// written by earlier versions are found after an update. An existing file in
+// dir is never replaced.
+func moveToAppData(dir string, name string) {
+	target := filepath.Join(dir, name)
+	if filepath.Clean(dir) == "." {
+		return
+	}
+	if _, err := os.Stat(name); err != nil {
+		return
+	}
+	if _, err := os.Stat(target); !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err := os.Rename(name, target); err != nil {
+		Warnf("Failed to move %s to %s: %v", name, dir, err)
+		return
+	}
+	Infof("Moved %s to %s", name, dir)
+}
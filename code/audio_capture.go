@@ -0,0 +1,142 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// capturedFrameSlots is how many callback buffers can wait for the capture
+// worker; at 1024 frames per buffer that is several seconds of audio
+const capturedFrameSlots = 256
+
+// initialBufferSeconds is the audio audioBuffer has room for when a recording
+// starts, so short dictations never reallocate it
+const initialBufferSeconds = 60
+
+// audioCapture hands audio from the PortAudio callback to a worker goroutine.
+// The callback only copies into a recycled buffer and queues it; appending to
+// audioBuffer, which may reallocate, and level analysis happen on the worker.
+type audioCapture struct {
+	frames  chan []int16  // Filled buffers, in order, for the worker
+	free    chan []int16  // Recycled buffers for the callback
+	done    chan struct{} // Closed when the worker has drained frames
+	dropped atomic.Int64  // Buffers lost because the worker fell behind
+}
+
+// newAudioCapture allocates the buffers for callbacks of up to bufferSamples samples
+func newAudioCapture(bufferSamples int) *audioCapture {
+	c := &audioCapture{
+		frames: make(chan []int16, capturedFrameSlots),
+		free:   make(chan []int16, capturedFrameSlots),
+		done:   make(chan struct{}),
+	}
+	for i := 0; i < capturedFrameSlots; i++ {
+		c.free <- make([]int16, 0, bufferSamples)
+	}
+	return c
+}
+
+// startAudioCapture prepares audioBuffer and the capture worker for a new
+// recording; it must run before the stream is started
+func (a *AppState) startAudioCapture(framesPerBuffer int) {
+	a.audioMutex.Lock()
+	a.audioBuffer = make([]int16, 0, a.samplesPerSecond()*initialBufferSeconds)
+	a.audioMutex.Unlock()
+
+	capture := newAudioCapture(framesPerBuffer * int(a.channels))
+	a.capture.Store(capture)
+	go a.runAudioCapture(capture, time.Now())
+}
+
+// stopAudioCapture waits until every buffer the callback queued is in
+// audioBuffer. The stream must be closed, so no more callbacks arrive.
+func (a *AppState) stopAudioCapture() {
+	capture := a.capture.Swap(nil)
+	if capture == nil {
+		return
+	}
+	close(capture.frames)
+	<-capture.done
+}
+
+// takeAudioBuffer hands the captured audio over to the caller, which owns it
+// exclusively from then on, and leaves audioBuffer empty
+func (a *AppState) takeAudioBuffer() []int16 {
+	a.audioMutex.Lock()
+	defer a.audioMutex.Unlock()
+	samples := a.audioBuffer
+	a.audioBuffer = nil
+	return samples
+}
+
+// audioCallback is called by PortAudio for each audio frame. It runs on the
+// realtime audio thread, so it only copies the samples for the capture worker.
+func (a *AppState) audioCallback(in []int16) {
+	capture := a.capture.Load()
+	if capture == nil {
+		return
+	}
+
+	var buf []int16
+	select {
+	case buf = <-capture.free:
+	default:
+		// Every slot is waiting for the worker; drop the block rather than allocate here
+		capture.dropped.Add(1)
+		return
+	}
+	buf = append(buf[:0], in...)
+
+	select {
+	case capture.frames <- buf:
+	default:
+		capture.dropped.Add(1)
+		capture.free <- buf // There is room, the buffer was just taken from free
+	}
+}
+
+// runAudioCapture appends queued buffers to audioBuffer until the capture is
+// stopped. When audioBuffer is full it grows to the length the recording is
+// heading for, estimated from the time elapsed, instead of doubling blindly.
+func (a *AppState) runAudioCapture(capture *audioCapture, started time.Time) {
+	defer close(capture.done)
+
+	for buf := range capture.frames {
+		a.audioMutex.Lock()
+		if len(a.audioBuffer)+len(buf) > cap(a.audioBuffer) {
+			elapsed := time.Since(started) + initialBufferSeconds*time.Second
+			estimate := int(elapsed.Seconds()) * a.samplesPerSecond()
+			grown := make([]int16, len(a.audioBuffer), max(estimate, 2*cap(a.audioBuffer), len(a.audioBuffer)+len(buf)))
+			copy(grown, a.audioBuffer)
+			a.audioBuffer = grown
+		}
+		a.audioBuffer = append(a.audioBuffer, buf...)
+		a.audioMutex.Unlock()
+
+		a.recordPeak(buf)
+		a.trackSilence(buf)
+		a.trackRecordedSamples(buf)
+
+		select {
+		case capture.free <- buf:
+		default:
+		}
+	}
+
+	if dropped := capture.dropped.Load(); dropped > 0 {
+		Warnf("Audio capture fell behind and dropped %d buffers", dropped)
+	}
+}
@@ -0,0 +1,207 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testFramesPerBuffer = 1024
+
+// testAudioState returns an AppState set up for 16 kHz mono capture
+func testAudioState() *AppState {
+	a := newTestAppState(context.Background())
+	a.sampleRate = 16000
+	a.channels = 1
+	return a
+}
+
+// feedAudio passes blocks of samples numbered from first to the audio callback,
+// waiting for a free capture buffer before each one the way a real-time stream
+// would, so no block is dropped
+func feedAudio(a *AppState, first, blocks int) {
+	capture := a.capture.Load()
+	in := make([]int16, testFramesPerBuffer)
+	for i := first; i < first+blocks; i++ {
+		for len(capture.free) == 0 {
+			runtime.Gosched()
+		}
+		for j := range in {
+			in[j] = int16(i)
+		}
+		a.audioCallback(in)
+	}
+}
+
+func TestAudioCaptureKeepsEveryBlockInOrder(t *testing.T) {
+	a := testAudioState()
+	const blocks = 3 * capturedFrameSlots
+
+	a.startAudioCapture(testFramesPerBuffer)
+	capture := a.capture.Load()
+	feedAudio(a, 0, blocks)
+	a.stopAudioCapture()
+
+	if dropped := capture.dropped.Load(); dropped != 0 {
+		t.Errorf("dropped %d blocks, want 0", dropped)
+	}
+	samples := a.takeAudioBuffer()
+	if len(samples) != blocks*testFramesPerBuffer {
+		t.Fatalf("captured %d samples, want %d", len(samples), blocks*testFramesPerBuffer)
+	}
+	for i, s := range samples {
+		if want := int16(i / testFramesPerBuffer); s != want {
+			t.Fatalf("sample %d = %d, want %d", i, s, want)
+		}
+	}
+	if got := a.recordedSamples.Load(); got != int64(len(samples)) {
+		t.Errorf("recordedSamples = %d, want %d", got, len(samples))
+	}
+	if a.takeAudioBuffer() != nil {
+		t.Error("takeAudioBuffer should leave the buffer empty")
+	}
+}
+
+func TestAudioCaptureDropsWhenWorkerFallsBehind(t *testing.T) {
+	var buf bytes.Buffer
+	GetLogger().logger.SetOutput(&buf)
+	defer GetLogger().logger.SetOutput(os.Stderr)
+
+	a := testAudioState()
+	a.startAudioCapture(testFramesPerBuffer)
+	capture := a.capture.Load()
+
+	// Holding the lock stalls the worker, so every capture buffer fills up and
+	// the callback must drop blocks rather than block or allocate
+	a.audioMutex.Lock()
+	in := make([]int16, testFramesPerBuffer)
+	for i := 0; i < capturedFrameSlots+10; i++ {
+		a.audioCallback(in)
+	}
+	a.audioMutex.Unlock()
+	a.stopAudioCapture()
+
+	if dropped := capture.dropped.Load(); dropped != 10 {
+		t.Errorf("dropped %d blocks, want 10", dropped)
+	}
+	if got := len(a.takeAudioBuffer()); got != capturedFrameSlots*testFramesPerBuffer {
+		t.Errorf("captured %d samples, want %d", got, capturedFrameSlots*testFramesPerBuffer)
+	}
+	if !strings.Contains(buf.String(), "dropped 10 buffers") {
+		t.Errorf("expected a warning about the dropped buffers, got %q", buf.String())
+	}
+}
+
+func TestAudioCallbackWithoutCapture(t *testing.T) {
+	a := testAudioState()
+	a.audioCallback(make([]int16, testFramesPerBuffer)) // Must not panic
+	a.stopAudioCapture()
+	if len(a.takeAudioBuffer()) != 0 {
+		t.Error("a callback with no capture running should not record anything")
+	}
+}
+
+// TestAudioCaptureRecordingsDoNotShareBuffers runs back-to-back recordings the way
+// StartRecording and StopRecording do, processing each one's samples while the
+// next is already capturing. Run with -race.
+func TestAudioCaptureRecordingsDoNotShareBuffers(t *testing.T) {
+	a := testAudioState()
+	const recordings = 20
+	const blocks = 2 * capturedFrameSlots
+
+	var processing sync.WaitGroup
+	for r := 0; r < recordings; r++ {
+		a.startAudioCapture(testFramesPerBuffer)
+
+		// The stream's callbacks run on their own thread, alongside level and
+		// duration reads from the UI
+		streamDone := make(chan struct{})
+		go func() {
+			defer close(streamDone)
+			feedAudio(a, r, blocks)
+		}()
+		for !isClosed(streamDone) {
+			a.recordedDuration()
+			a.inputPeak.Load()
+			runtime.Gosched()
+		}
+
+		a.stopAudioCapture()
+		samples := a.takeAudioBuffer()
+		processing.Add(1)
+		go func(r int) {
+			defer processing.Done()
+			for i, s := range samples {
+				if s != int16(r+i/testFramesPerBuffer) {
+					t.Errorf("recording %d: sample %d = %d, another recording wrote into its buffer", r, i, s)
+					return
+				}
+			}
+			if pcm := samplesToPCM(samples); len(pcm) != 2*blocks*testFramesPerBuffer {
+				t.Errorf("recording %d: %d bytes of PCM, want %d", r, len(pcm), 2*blocks*testFramesPerBuffer)
+			}
+		}(r)
+	}
+	processing.Wait()
+}
+
+// isClosed reports whether done has been closed, without blocking
+func isClosed(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// BenchmarkAudioCapture records five minutes of audio through the callback and
+// the worker and takes the finished buffer
+func BenchmarkAudioCapture(b *testing.B) {
+	a := testAudioState()
+	blocks := 5 * 60 * a.samplesPerSecond() / testFramesPerBuffer
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		a.startAudioCapture(testFramesPerBuffer)
+		feedAudio(a, 0, blocks)
+		a.stopAudioCapture()
+		if got := len(a.takeAudioBuffer()); got != blocks*testFramesPerBuffer {
+			b.Fatalf("captured %d samples, want %d", got, blocks*testFramesPerBuffer)
+		}
+	}
+}
+
+// BenchmarkAudioCallback measures the work done on the PortAudio thread per block
+func BenchmarkAudioCallback(b *testing.B) {
+	a := testAudioState()
+	a.startAudioCapture(testFramesPerBuffer)
+	defer a.stopAudioCapture()
+	capture := a.capture.Load()
+	in := make([]int16, testFramesPerBuffer)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for len(capture.free) == 0 {
+			runtime.Gosched()
+		}
+		a.audioCallback(in)
+	}
+}
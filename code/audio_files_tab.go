@@ -0,0 +1,220 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// formatAudioFileDetails describes the size and duration of a stored audio file
+func formatAudioFileDetails(file AudioFile) string {
+	return fmt.Sprintf("%s: %.1f KB, %s, recorded %s",
+		file.Filename, float64(file.Size)/1024, file.Duration.Round(time.Second),
+		file.Timestamp.Format("2006-01-02 15:04:05"))
+}
+
+// openWithDefaultPlayer opens path in the system's default application
+func openWithDefaultPlayer(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	// Reap the launcher process without blocking the UI
+	go cmd.Wait()
+	return nil
+}
+
+// playStoredAudio opens a stored recording in the default player
+func (a *AppState) playStoredAudio(filename string) {
+	path := a.audioStorage.GetAudioFilePath(filename)
+	if _, err := os.Stat(path); err != nil {
+		Errorf("Cannot play %s: %v", filename, err)
+		setStatusText(a.statusLabel, fmt.Sprintf("Recording %s no longer exists", filename))
+		a.storedAudioList.Refresh()
+		return
+	}
+	if err := openWithDefaultPlayer(path); err != nil {
+		Errorf("Playback failed: %v", err)
+		setStatusText(a.statusLabel, fmt.Sprintf("Playback error: %v", err))
+	}
+}
+
+// deleteStoredAudio deletes a stored recording and refreshes the list.
+// A file that is already gone is treated as deleted.
+func (a *AppState) deleteStoredAudio(filename string) {
+	err := a.audioStorage.DeleteAudioFile(filename)
+	switch {
+	case err == nil:
+		setStatusText(a.statusLabel, fmt.Sprintf("Deleted %s", filename))
+	case os.IsNotExist(err):
+		Infof("Recording %s was already removed", filename)
+		setStatusText(a.statusLabel, fmt.Sprintf("Recording %s was already removed", filename))
+	default:
+		Errorf("Failed to delete %s: %v", filename, err)
+		setStatusText(a.statusLabel, fmt.Sprintf("Delete error: %v", err))
+		return
+	}
+
+	a.storedAudioList.UnselectAll()
+	a.audioDetailsLabel.SetText("")
+	a.storedAudioList.Refresh()
+}
+
+// onExportSubtitlesClick asks for a subtitle format and exports the selected recording
+func (a *AppState) onExportSubtitlesClick() {
+	filename := a.selectedAudioFile
+	if filename == "" {
+		setStatusText(a.statusLabel, "Select a recording to export subtitles")
+		return
+	}
+
+	formatSelect := widget.NewSelect([]string{"SRT", "WebVTT"}, nil)
+	formatSelect.SetSelected("SRT")
+	dialog.ShowCustomConfirm("Export subtitles", "Export", "Cancel",
+		widget.NewForm(widget.NewFormItem("Format", formatSelect)),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			format := SubtitleFormatSRT
+			if formatSelect.Selected == "WebVTT" {
+				format = SubtitleFormatVTT
+			}
+			if !a.clientsReady() {
+				return
+			}
+			go a.exportSubtitles(filename, format)
+		}, a.mainWindow)
+}
+
+// exportSubtitles transcribes a stored recording with segment timestamps and
+// writes the subtitles next to it, e.g. recording_..._128kbps.srt. It runs in
+// its own goroutine.
+func (a *AppState) exportSubtitles(filename string, format string) {
+	path := a.audioStorage.GetAudioFilePath(filename)
+	audioData, err := os.ReadFile(path)
+	if err != nil {
+		Errorf("Subtitle export: failed to read %s: %v", filename, err)
+		postStatusText(a.statusLabel, fmt.Sprintf("Cannot read %s", filename))
+		return
+	}
+
+	postStatusText(a.statusLabel, fmt.Sprintf("Transcribing %s for subtitles...", filename))
+	transcriber, _ := a.clients()
+	if transcriber == nil {
+		postStatusText(a.statusLabel, "Enter your OpenAI API key above to start")
+		return
+	}
+	_, segments, err := transcriber.TranscribeWithSegments(a.requestContext(), audioData, filename, a.selectedLanguage, a.transcriptionPrompt())
+	if err != nil {
+		Errorf("Subtitle export: transcription failed: %v", err)
+		postStatusText(a.statusLabel, fmt.Sprintf("Subtitle export failed: %s", transcriptionFailureReason(err)))
+		return
+	}
+	a.usage.addTranscription(a.storedAudioDuration(filename))
+	if len(segments) == 0 {
+		postStatusText(a.statusLabel, "No speech detected, no subtitles written")
+		return
+	}
+
+	subtitles, err := ExportSubtitles(segments, format)
+	if err != nil {
+		Errorf("Subtitle export: %v", err)
+		postStatusText(a.statusLabel, fmt.Sprintf("Subtitle export failed: %v", err))
+		return
+	}
+
+	outPath := strings.TrimSuffix(path, filepath.Ext(path)) + "." + format
+	if err := os.WriteFile(outPath, subtitles, 0644); err != nil {
+		Errorf("Subtitle export: failed to write %s: %v", outPath, err)
+		postStatusText(a.statusLabel, fmt.Sprintf("Subtitle export failed: %v", err))
+		return
+	}
+
+	Infof("Subtitle export: wrote %d segments to %s", len(segments), outPath)
+	postStatusText(a.statusLabel, fmt.Sprintf("Subtitles saved to %s", filepath.Base(outPath)))
+}
+
+// newStoredAudioList builds the Audio Files list with play, re-transcribe and delete buttons per item.
+// Selecting an item shows its size and duration in audioDetailsLabel.
+func (a *AppState) newStoredAudioList() *widget.List {
+	list := widget.NewList(
+		func() int {
+			audioFiles, _ := a.audioStorage.GetStoredAudioFiles()
+			return len(audioFiles)
+		},
+		func() fyne.CanvasObject {
+			playButton := widget.NewButton("Play", nil)
+			retranscribeButton := widget.NewButton("Re-transcribe", nil)
+			deleteButton := widget.NewButton("Delete", nil)
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(playButton, retranscribeButton, deleteButton),
+				widget.NewLabel("Template"))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			audioFiles, _ := a.audioStorage.GetStoredAudioFiles()
+			if id >= len(audioFiles) {
+				return
+			}
+			file := audioFiles[id]
+
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(formatAudioFileLabel(file))
+			buttons := row.Objects[1].(*fyne.Container)
+			buttons.Objects[0].(*widget.Button).OnTapped = func() {
+				a.playStoredAudio(file.Filename)
+			}
+			buttons.Objects[1].(*widget.Button).OnTapped = func() {
+				a.retranscribeStoredAudio(file.Filename)
+			}
+			buttons.Objects[2].(*widget.Button).OnTapped = func() {
+				a.deleteStoredAudio(file.Filename)
+			}
+		},
+	)
+
+	list.OnSelected = func(id widget.ListItemID) {
+		audioFiles, err := a.audioStorage.GetStoredAudioFiles()
+		if err != nil || id >= len(audioFiles) {
+			a.selectedAudioFile = ""
+			a.audioDetailsLabel.SetText("")
+			return
+		}
+		a.selectedAudioFile = audioFiles[id].Filename
+		a.audioDetailsLabel.SetText(formatAudioFileDetails(audioFiles[id]))
+	}
+	list.OnUnselected = func(widget.ListItemID) {
+		a.selectedAudioFile = ""
+	}
+	return list
+}
@@ -0,0 +1,85 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"math"
+)
+
+// noiseGateWindow is the length of the blocks the noise gate opens and closes
+// on, and noiseGateHold how many quiet blocks around sound are kept so word
+// onsets and tails aren't clipped
+const (
+	noiseGateWindow = 10 // ms
+	noiseGateHold   = 5  // windows
+)
+
+// highPassFilter removes rumble and mains hum below cutoffHz from samples in place
+// with a first-order RC filter. The filter starts settled on the first sample so
+// a chunk doesn't begin with a click.
+func highPassFilter(samples []int16, sampleRate int, cutoffHz float64) {
+	if cutoffHz <= 0 || sampleRate <= 0 || len(samples) == 0 {
+		return
+	}
+	rc := 1 / (2 * math.Pi * cutoffHz)
+	dt := 1 / float64(sampleRate)
+	alpha := rc / (rc + dt)
+
+	prevIn := float64(samples[0])
+	prevOut := 0.0
+	for i, sample := range samples {
+		in := float64(sample)
+		out := alpha * (prevOut + in - prevIn)
+		prevIn, prevOut = in, out
+		samples[i] = int16(max(math.MinInt16, min(math.MaxInt16, math.Round(out))))
+	}
+}
+
+// noiseGate silences stretches of samples whose RMS level stays below threshold,
+// such as fan noise between sentences. It works on noiseGateWindow blocks rather
+// than single samples, which would distort quiet parts of speech.
+func noiseGate(samples []int16, sampleRate int, threshold int) {
+	window := sampleRate * noiseGateWindow / 1000
+	if threshold <= 0 || window <= 0 || len(samples) == 0 {
+		return
+	}
+
+	windows := (len(samples) + window - 1) / window
+	loud := make([]bool, windows)
+	for w := range loud {
+		block := samples[w*window : min((w+1)*window, len(samples))]
+		loud[w] = computeAudioLevel(block).RMS >= float64(threshold)
+	}
+
+	for w := range loud {
+		open := false
+		for n := max(0, w-noiseGateHold); n <= min(windows-1, w+noiseGateHold); n++ {
+			if loud[n] {
+				open = true
+				break
+			}
+		}
+		if !open {
+			clear(samples[w*window : min((w+1)*window, len(samples))])
+		}
+	}
+}
+
+// filterAudio applies the configured high-pass filter and noise gate to mono
+// samples before they are transcribed. The hum is removed first so it can't
+// hold the gate open.
+func (a *AppState) filterAudio(samples []int16) {
+	highPassFilter(samples, int(a.sampleRate), float64(a.config.HighPassCutoff))
+	noiseGate(samples, int(a.sampleRate), a.config.NoiseGate)
+}
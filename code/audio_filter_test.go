@@ -0,0 +1,172 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+const filterTestRate = 16000
+
+// sineSamples returns seconds of a sine tone at freq Hz with the given peak amplitude
+func sineSamples(freq float64, amplitude float64, seconds float64) []int16 {
+	samples := make([]int16, int(seconds*filterTestRate))
+	for i := range samples {
+		samples[i] = int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/filterTestRate))
+	}
+	return samples
+}
+
+// filteredGain returns the RMS level of a tone after the high-pass filter
+// relative to before, skipping the first tenth of a second while the filter settles
+func filteredGain(freq float64, cutoff float64) float64 {
+	samples := sineSamples(freq, 10000, 1)
+	before := computeAudioLevel(samples[filterTestRate/10:]).RMS
+	highPassFilter(samples, filterTestRate, cutoff)
+	return computeAudioLevel(samples[filterTestRate/10:]).RMS / before
+}
+
+func TestHighPassFilterAttenuatesLowFrequencies(t *testing.T) {
+	const cutoff = 100
+	tests := []struct {
+		name     string
+		freq     float64
+		min, max float64
+	}{
+		// A first-order filter passes about f/sqrt(f² + fc²) of the level; the
+		// discrete version loses a few percent more in the passband
+		{"30 Hz rumble", 30, 0, 0.35},
+		{"50 Hz mains hum", 50, 0, 0.5},
+		{"300 Hz voice fundamental", 300, 0.9, 1.01},
+		{"1 kHz speech", 1000, 0.95, 1.01},
+		{"3 kHz consonants", 3000, 0.95, 1.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gain := filteredGain(tt.freq, cutoff); gain < tt.min || gain > tt.max {
+				t.Errorf("gain at %v Hz = %.3f, want %.2f-%.2f", tt.freq, gain, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestHighPassFilterRemovesDCOffset(t *testing.T) {
+	samples := sineSamples(1000, 2000, 1)
+	for i := range samples {
+		samples[i] += 3000
+	}
+	highPassFilter(samples, filterTestRate, 100)
+	var sum float64
+	for _, s := range samples[filterTestRate/2:] {
+		sum += float64(s)
+	}
+	if mean := sum / float64(filterTestRate/2); math.Abs(mean) > 50 {
+		t.Errorf("mean after filtering = %.1f, want about 0", mean)
+	}
+}
+
+func TestHighPassFilterClampsInsteadOfWrapping(t *testing.T) {
+	// Full-scale swings at the Nyquist frequency overshoot the int16 range
+	samples := make([]int16, 1000)
+	for i := range samples {
+		samples[i] = math.MaxInt16
+		if i%2 == 1 {
+			samples[i] = math.MinInt16
+		}
+	}
+	highPassFilter(samples, filterTestRate, 100)
+	for i, s := range samples[1:] {
+		if (s > 0) != (i%2 == 1) {
+			t.Fatalf("sample %d = %d has the wrong sign, the output wrapped around", i+1, s)
+		}
+	}
+}
+
+func TestHighPassFilterDisabled(t *testing.T) {
+	samples := sineSamples(50, 10000, 0.1)
+	want := slices.Clone(samples)
+	highPassFilter(samples, filterTestRate, 0)
+	if !slices.Equal(samples, want) {
+		t.Error("a zero cutoff changed the samples")
+	}
+	highPassFilter(nil, filterTestRate, 100) // Must not panic
+}
+
+func TestNoiseGate(t *testing.T) {
+	const window = filterTestRate * noiseGateWindow / 1000
+	rng := rand.New(rand.NewSource(1))
+
+	// Half a second of quiet noise on either side of a fifth of a second of speech
+	var samples []int16
+	for i := 0; i < filterTestRate/2; i++ {
+		samples = append(samples, int16(rng.Intn(201)-100))
+	}
+	samples = append(samples, sineSamples(1000, 5000, 0.2)...)
+	for i := 0; i < filterTestRate/2; i++ {
+		samples = append(samples, int16(rng.Intn(201)-100))
+	}
+	original := slices.Clone(samples)
+	noiseGate(samples, filterTestRate, 300)
+
+	// The speech starts at window 50 and ends after window 69; the gate holds
+	// open for noiseGateHold windows on each side
+	openFrom := (50 - noiseGateHold) * window
+	openTo := (70 + noiseGateHold) * window
+	for i, s := range samples {
+		inside := i >= openFrom && i < openTo
+		if inside && s != original[i] {
+			t.Fatalf("sample %d changed to %d inside the open gate", i, s)
+		}
+		if !inside && s != 0 {
+			t.Fatalf("sample %d = %d, want silence outside the open gate", i, s)
+		}
+	}
+}
+
+func TestNoiseGateDisabled(t *testing.T) {
+	samples := sineSamples(1000, 100, 0.1)
+	want := slices.Clone(samples)
+	noiseGate(samples, filterTestRate, 0)
+	if !slices.Equal(samples, want) {
+		t.Error("a zero threshold changed the samples")
+	}
+}
+
+func TestFilterAudioRemovesHumBeforeGating(t *testing.T) {
+	// Loud enough on its own to hold the gate open, but not once it is filtered
+	hum := sineSamples(50, 1000, 0.5)
+	if computeAudioLevel(hum).RMS < 300 {
+		t.Fatal("test hum is too quiet to hold the gate open")
+	}
+	speech := sineSamples(1000, 5000, 0.5)
+
+	a := newTestAppState(context.Background())
+	a.sampleRate = filterTestRate
+	a.config.HighPassCutoff = 150
+	a.config.NoiseGate = 300
+
+	a.filterAudio(hum)
+	if level := computeAudioLevel(hum).RMS; level != 0 {
+		t.Errorf("hum level after filtering = %.1f, want silence", level)
+	}
+
+	before := computeAudioLevel(speech).RMS
+	a.filterAudio(speech)
+	if ratio := computeAudioLevel(speech).RMS / before; ratio < 0.95 {
+		t.Errorf("speech kept %.2f of its level, want at least 0.95", ratio)
+	}
+}
@@ -0,0 +1,96 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// AudioLevel summarizes the signal level of a block of samples
+type AudioLevel struct {
+	Min     int16
+	Max     int16
+	RMS     float64
+	Samples int
+}
+
+// computeAudioLevel returns min/max/RMS statistics for samples
+func computeAudioLevel(samples []int16) AudioLevel {
+	if len(samples) == 0 {
+		return AudioLevel{}
+	}
+
+	level := AudioLevel{
+		Min:     samples[0],
+		Max:     samples[0],
+		Samples: len(samples),
+	}
+
+	var sumSquares float64
+	for _, sample := range samples {
+		if sample < level.Min {
+			level.Min = sample
+		}
+		if sample > level.Max {
+			level.Max = sample
+		}
+		sumSquares += float64(sample) * float64(sample)
+	}
+	level.RMS = math.Sqrt(sumSquares / float64(len(samples)))
+
+	return level
+}
+
+// monitorAudioLevel logs input level statistics once per second while the
+// given stream is recording. It only runs when DEBUG logging is enabled.
+func (a *AppState) monitorAudioLevel(stream *portaudio.Stream) {
+	logger := GetLogger()
+	if logger.GetLevel() > DEBUG {
+		return
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	processed := 0
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Stop once this recording has ended (the stream is replaced or cleared)
+		if !a.isCurrentRecording(stream) {
+			return
+		}
+
+		// Copy the new samples while holding the lock; once it is released the
+		// buffer may be handed to processAudio, which owns it from then on
+		a.audioMutex.Lock()
+		if len(a.audioBuffer) < processed {
+			processed = 0
+		}
+		samples := append([]int16(nil), a.audioBuffer[processed:]...)
+		processed = len(a.audioBuffer)
+		a.audioMutex.Unlock()
+
+		level := computeAudioLevel(samples)
+
+		logger.LogAudioLevel(level.Min, level.Max, level.RMS, level.Samples)
+	}
+}
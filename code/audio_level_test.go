@@ -0,0 +1,63 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestComputeAudioLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []int16
+		want    AudioLevel
+	}{
+		{"empty", nil, AudioLevel{}},
+		{"silence", []int16{0, 0, 0, 0}, AudioLevel{Min: 0, Max: 0, RMS: 0, Samples: 4}},
+		{"square wave", []int16{1000, -1000, 1000, -1000}, AudioLevel{Min: -1000, Max: 1000, RMS: 1000, Samples: 4}},
+		{"full scale", []int16{32767, -32768}, AudioLevel{Min: -32768, Max: 32767, RMS: math.Sqrt((32767.0*32767 + 32768.0*32768) / 2), Samples: 2}},
+		{"mixed", []int16{3, -4, 0, 12}, AudioLevel{Min: -4, Max: 12, RMS: math.Sqrt((9 + 16 + 0 + 144) / 4.0), Samples: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeAudioLevel(tt.samples)
+			if got.Min != tt.want.Min || got.Max != tt.want.Max || got.Samples != tt.want.Samples {
+				t.Errorf("computeAudioLevel = %+v, want %+v", got, tt.want)
+			}
+			if math.Abs(got.RMS-tt.want.RMS) > 1e-9 {
+				t.Errorf("computeAudioLevel RMS = %v, want %v", got.RMS, tt.want.RMS)
+			}
+		})
+	}
+}
+
+func TestLogAudioLevelOnlyAtDebug(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf, INFO, LogFormatText)
+	logger.LogAudioLevel(-10, 10, 7.5, 16000)
+	if buf.Len() != 0 {
+		t.Errorf("LogAudioLevel wrote at INFO level: %q", buf.String())
+	}
+
+	logger.SetLevel(DEBUG)
+	logger.LogAudioLevel(-10, 10, 7.5, 16000)
+	for _, want := range []string{"Audio level", "min", "-10", "rms", "7.5", "16000"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("DEBUG audio level line %q does not contain %q", buf.String(), want)
+		}
+	}
+}
@@ -15,19 +15,33 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// AudioStorage manages storage of audio files with different bitrates
+// AudioStorage manages storage of recorded audio files
 type AudioStorage struct {
-	baseDir string
+	baseDir   string
+	sortOrder string // Order of GetStoredAudioFiles results (see AudioSort* constants)
 }
 
+// Sort orders for stored audio files
+const (
+	AudioSortNewest   = "newest"   // Most recent first
+	AudioSortOldest   = "oldest"   // Oldest first
+	AudioSortSize     = "size"     // Largest first
+	AudioSortDuration = "duration" // Longest first
+)
+
 // AudioFile represents a stored audio file with metadata
 type AudioFile struct {
 	Filename   string
@@ -38,6 +52,16 @@ type AudioFile struct {
 	Size       int64
 }
 
+// TranscriptMetadata describes a completed transcription of a stored recording
+type TranscriptMetadata struct {
+	Recording   string    `json:"recording"`
+	Text        string    `json:"text"`
+	Language    string    `json:"language"`
+	Mode        string    `json:"mode"`
+	WindowTitle string    `json:"window_title,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
 // NewAudioStorage creates a new audio storage manager
 func NewAudioStorage() *AudioStorage {
 	// Use recordings folder in the current directory
@@ -47,165 +71,271 @@ func NewAudioStorage() *AudioStorage {
 	os.MkdirAll(baseDir, 0755)
 
 	return &AudioStorage{
-		baseDir: baseDir,
+		baseDir:   baseDir,
+		sortOrder: AudioSortNewest,
 	}
 }
 
-// RecreateRecordingsFolder removes and recreates the recordings folder
-func (as *AudioStorage) RecreateRecordingsFolder() error {
-	// Remove the entire recordings folder if it exists
-	if err := os.RemoveAll(as.baseDir); err != nil {
-		log.Printf("Failed to remove recordings folder: %v", err)
-		return err
+// SetSortOrder sets the order used by GetStoredAudioFiles.
+// Unknown orders fall back to newest first.
+func (as *AudioStorage) SetSortOrder(order string) {
+	switch order {
+	case AudioSortNewest, AudioSortOldest, AudioSortSize, AudioSortDuration:
+		as.sortOrder = order
+	default:
+		Warnf("Unknown audio sort order %q, using %q", order, AudioSortNewest)
+		as.sortOrder = AudioSortNewest
 	}
-
-	// Create the folder again
-	if err := os.MkdirAll(as.baseDir, 0755); err != nil {
-		log.Printf("Failed to create recordings folder: %v", err)
-		return err
-	}
-
-	log.Printf("Recordings folder recreated successfully")
-	return nil
 }
 
-// StoreAudio stores audio data as MP3 with different bitrates
-func (as *AudioStorage) StoreAudio(pcmData []byte, sampleRate uint32) ([]AudioFile, error) {
-	timestamp := time.Now()
-	var storedFiles []AudioFile
-
-	// Define different bitrates to store
-	bitrates := []int{128, 192, 256, 320} // kbps
-
-	for _, bitrate := range bitrates {
-		// Create filename with timestamp and bitrate
-		filename := fmt.Sprintf("recording_%s_%dkbps.mp3",
-			timestamp.Format("20060102_150405"), bitrate)
-		filepath := filepath.Join(as.baseDir, filename)
-
-		// Convert PCM to MP3 using ffmpeg
-		mp3Data, err := as.convertPCMToMP3(pcmData, sampleRate, bitrate)
-		if err != nil {
-			log.Printf("Failed to convert PCM to MP3 at %dkbps: %v (skipping this bitrate)", bitrate, err)
-			continue // Skip this bitrate if conversion fails
+// sortAudioFiles sorts files in place according to order
+func sortAudioFiles(files []AudioFile, order string) {
+	sort.SliceStable(files, func(i, j int) bool {
+		switch order {
+		case AudioSortOldest:
+			return files[i].Timestamp.Before(files[j].Timestamp)
+		case AudioSortSize:
+			return files[i].Size > files[j].Size
+		case AudioSortDuration:
+			return files[i].Duration > files[j].Duration
+		default:
+			return files[i].Timestamp.After(files[j].Timestamp)
 		}
+	})
+}
 
-		// Write MP3 file
-		err = os.WriteFile(filepath, mp3Data, 0644)
-		if err != nil {
-			log.Printf("Failed to write MP3 file at %dkbps: %v (skipping this bitrate)", bitrate, err)
-			continue // Skip this bitrate if write fails
-		}
+// ClearRecordings deletes all stored recordings and their transcript metadata.
+// Screenshots and quarantined files are kept.
+func (as *AudioStorage) ClearRecordings() (int, error) {
+	files, err := as.GetStoredAudioFiles()
+	if err != nil {
+		return 0, err
+	}
 
-		// Get file info
-		fileInfo, err := os.Stat(filepath)
-		if err != nil {
-			log.Printf("Failed to stat MP3 file at %dkbps: %v (skipping this bitrate)", bitrate, err)
+	removed := 0
+	for _, file := range files {
+		if err := as.DeleteAudioFile(file.Filename); err != nil {
+			Errorf("Failed to delete recording %s: %v", file.Filename, err)
 			continue
 		}
+		removed++
+	}
 
-		// Calculate duration (approximate)
-		duration := time.Duration(len(pcmData)) * time.Second / time.Duration(sampleRate*2) // 2 bytes per sample
+	Infof("Cleared %d recordings", removed)
+	return removed, nil
+}
 
-		storedFiles = append(storedFiles, AudioFile{
-			Filename:   filename,
-			Bitrate:    bitrate,
-			SampleRate: int(sampleRate),
-			Duration:   duration,
-			Timestamp:  timestamp,
-			Size:       fileInfo.Size(),
-		})
+// ApplyRetention deletes recordings beyond the newest keepLast files or older than
+// maxAge. A zero value disables the respective limit.
+func (as *AudioStorage) ApplyRetention(keepLast int, maxAge time.Duration) (int, error) {
+	if keepLast <= 0 && maxAge <= 0 {
+		return 0, nil
 	}
 
-	return storedFiles, nil
+	files, err := as.GetStoredAudioFiles()
+	if err != nil {
+		return 0, err
+	}
+	sortAudioFiles(files, AudioSortNewest)
+
+	removed := 0
+	for i, file := range files {
+		tooMany := keepLast > 0 && i >= keepLast
+		tooOld := maxAge > 0 && time.Since(file.Timestamp) > maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := as.DeleteAudioFile(file.Filename); err != nil {
+			Errorf("Retention: failed to delete %s: %v", file.Filename, err)
+			continue
+		}
+		removed++
+	}
+
+	Infof("Retention: removed %d of %d recordings (keep last %d, max age %v)", removed, len(files), keepLast, maxAge)
+	return removed, nil
 }
 
-// SaveLastRecording saves the recording as MP3 128kbps to the recordings folder
-func (as *AudioStorage) SaveLastRecording(pcmData []byte, sampleRate uint32) (string, error) {
+// StoreAudioAt stores a recording of interleaved PCM as MP3 at the given bitrate, encoding it once.
+// Audio the MP3 encoder cannot take (e.g. 96 kHz) is kept as an uncompressed WAV file instead.
+func (as *AudioStorage) StoreAudioAt(pcmData []byte, sampleRate uint32, numChannels uint16, bitrate int) (AudioFile, error) {
 	timestamp := time.Now()
 	baseFilename := fmt.Sprintf("recording_%s", timestamp.Format("20060102_150405"))
 
-	// Save only MP3 128kbps (used for transcription)
-	mp3Filename := baseFilename + ".mp3"
-	mp3Filepath := filepath.Join(as.baseDir, mp3Filename)
-
-	mp3Data, err := as.convertPCMToMP3(pcmData, sampleRate, 128)
-	if err != nil {
-		return "", fmt.Errorf("failed to convert to MP3: %v", err)
+	stored := AudioFile{
+		Filename:   fmt.Sprintf("%s_%dkbps.mp3", baseFilename, bitrate),
+		Bitrate:    bitrate,
+		SampleRate: int(sampleRate),
+		Duration:   time.Duration(len(pcmData)) * time.Second / time.Duration(sampleRate*2*uint32(numChannels)), // 2 bytes per sample
+		Timestamp:  timestamp,
 	}
 
-	err = os.WriteFile(mp3Filepath, mp3Data, 0644)
-	if err != nil {
-		return "", fmt.Errorf("failed to write MP3 file: %v", err)
+	data, err := as.convertPCMToMP3(pcmData, sampleRate, numChannels, bitrate)
+	if errors.Is(err, errMP3FormatUnsupported) {
+		stored.Filename = baseFilename + ".wav"
+		stored.Bitrate = 0
+		data = CreateWAVFile(pcmData, sampleRate, numChannels)
+	} else if err != nil {
+		return AudioFile{}, fmt.Errorf("failed to convert to MP3: %v", err)
 	}
 
-	// Verify MP3 file was written correctly
-	mp3Info, err := os.Stat(mp3Filepath)
-	if err != nil {
-		log.Printf("Failed to stat MP3 file: %v", err)
-	} else {
-		log.Printf("MP3 file saved: %s (size: %d bytes, bitrate: 128 kbps)", mp3Filepath, mp3Info.Size())
+	path := filepath.Join(as.baseDir, stored.Filename)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return AudioFile{}, fmt.Errorf("failed to write %s: %v", stored.Filename, err)
 	}
+	stored.Size = int64(len(data))
 
-	return mp3Filename, nil
+	Infof("Recording saved: %s (size: %d bytes, bitrate: %d kbps)", path, stored.Size, stored.Bitrate)
+	return stored, nil
 }
 
-// ConvertToMP3 converts PCM data to MP3 format using ffmpeg (public method)
+// ConvertToMP3 converts mono PCM data to MP3 format (public method)
 func (as *AudioStorage) ConvertToMP3(pcmData []byte, sampleRate uint32, bitrate int) ([]byte, error) {
-	return as.convertPCMToMP3(pcmData, sampleRate, bitrate)
+	return as.convertPCMToMP3(pcmData, sampleRate, 1, bitrate)
 }
 
-// convertPCMToMP3 converts PCM data to MP3 format using ffmpeg
-func (as *AudioStorage) convertPCMToMP3(pcmData []byte, sampleRate uint32, bitrate int) ([]byte, error) {
-	// First, create a temporary WAV file from PCM data
-	wavData := CreateWAVFile(pcmData, sampleRate, 1)
+// convertPCMToMP3 converts interleaved PCM data to MP3 format using ffmpeg, or the
+// built-in encoder if ffmpeg is not installed. The WAV data is piped to ffmpeg's
+// stdin and the MP3 read from its stdout, so no temporary files are written.
+func (as *AudioStorage) convertPCMToMP3(pcmData []byte, sampleRate uint32, numChannels uint16, bitrate int) ([]byte, error) {
+	if !ffmpegAvailable() {
+		return encodeMP3(pcmData, sampleRate, numChannels, bitrate)
+	}
 
-	// Create temporary files for input (WAV) and output (MP3)
-	tmpWavFile, err := os.CreateTemp("", "temp_*.wav")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp WAV file: %v", err)
+	cmd := exec.Command("ffmpeg",
+		"-f", "wav",
+		"-i", "pipe:0",
+		"-codec:a", "libmp3lame",
+		"-b:a", fmt.Sprintf("%dk", bitrate),
+		"-f", "mp3",
+		"pipe:1",
+	)
+	cmd.Stdin = bytes.NewReader(CreateWAVFile(pcmData, sampleRate, numChannels))
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		Errorf("ffmpeg conversion failed: %v, stderr: %s", err, stderr.String())
+		return nil, fmt.Errorf("ffmpeg conversion failed: %v (ffmpeg may not be installed)", err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// ConvertToOpus converts mono PCM data to Opus in an Ogg container using ffmpeg's libopus encoder
+func (as *AudioStorage) ConvertToOpus(pcmData []byte, sampleRate uint32, bitrate int) ([]byte, error) {
+	if !ffmpegAvailable() {
+		return nil, errFFmpegMissing
+	}
+
+	cmd := exec.Command("ffmpeg",
+		"-f", "wav",
+		"-i", "pipe:0",
+		"-codec:a", "libopus",
+		"-b:a", fmt.Sprintf("%dk", bitrate),
+		"-f", "ogg",
+		"pipe:1",
+	)
+	cmd.Stdin = bytes.NewReader(CreateWAVFile(pcmData, sampleRate, 1))
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		Errorf("ffmpeg opus encoding failed: %v, stderr: %s", err, stderr.String())
+		return nil, fmt.Errorf("ffmpeg opus encoding failed: %v (ffmpeg or libopus may not be installed)", err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// SaveTranscriptMetadata writes transcript metadata next to the recording as a JSON sidecar file
+func (as *AudioStorage) SaveTranscriptMetadata(meta TranscriptMetadata) error {
+	if meta.Recording == "" {
+		return fmt.Errorf("recording filename is empty")
 	}
-	defer os.Remove(tmpWavFile.Name())
-	defer tmpWavFile.Close()
 
-	tmpMp3File, err := os.CreateTemp("", "temp_*.mp3")
+	data, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp MP3 file: %v", err)
+		return fmt.Errorf("failed to marshal transcript metadata: %v", err)
 	}
-	defer os.Remove(tmpMp3File.Name())
-	defer tmpMp3File.Close()
 
-	// Write WAV data to temp file
-	if _, err := tmpWavFile.Write(wavData); err != nil {
-		return nil, fmt.Errorf("failed to write WAV data: %v", err)
+	metaFilename := strings.TrimSuffix(meta.Recording, filepath.Ext(meta.Recording)) + ".json"
+	if err := os.WriteFile(filepath.Join(as.baseDir, metaFilename), data, 0644); err != nil {
+		return fmt.Errorf("failed to write transcript metadata: %v", err)
+	}
+
+	return nil
+}
+
+// DecodeToPCM converts audio in any ffmpeg-supported format to 16-bit mono PCM at sampleRate
+func (as *AudioStorage) DecodeToPCM(audioData []byte, sampleRate uint32) ([]byte, error) {
+	if !ffmpegAvailable() {
+		return nil, errFFmpegMissing
 	}
-	tmpWavFile.Close()
 
-	// Use ffmpeg to convert WAV to MP3
 	cmd := exec.Command("ffmpeg",
-		"-i", tmpWavFile.Name(),
-		"-codec:a", "libmp3lame",
-		"-b:a", fmt.Sprintf("%dk", bitrate),
-		"-y", // Overwrite output file
-		tmpMp3File.Name(),
+		"-i", "pipe:0",
+		"-f", "s16le",
+		"-acodec", "pcm_s16le",
+		"-ac", "1",
+		"-ar", fmt.Sprintf("%d", sampleRate),
+		"pipe:1",
 	)
+	cmd.Stdin = bytes.NewReader(audioData)
 
-	var stderr bytes.Buffer
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		// If ffmpeg is not available, return error
-		log.Printf("ffmpeg conversion failed: %v, stderr: %s", err, stderr.String())
-		return nil, fmt.Errorf("ffmpeg conversion failed: %v (ffmpeg may not be installed)", err)
+		Errorf("ffmpeg decoding failed: %v, stderr: %s", err, stderr.String())
+		return nil, fmt.Errorf("ffmpeg decoding failed: %v (ffmpeg may not be installed)", err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// SaveScreenshot stores PNG data in the screenshots subfolder with a timestamped name
+func (as *AudioStorage) SaveScreenshot(pngData []byte, timestamp time.Time) (string, error) {
+	screenshotsDir := filepath.Join(as.baseDir, "screenshots")
+	if err := os.MkdirAll(screenshotsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create screenshots folder: %v", err)
 	}
 
-	// Read the MP3 file
-	mp3Data, err := os.ReadFile(tmpMp3File.Name())
+	filename := fmt.Sprintf("screenshot_%s.png", timestamp.Format("20060102_150405"))
+	if err := os.WriteFile(filepath.Join(screenshotsDir, filename), pngData, 0644); err != nil {
+		return "", fmt.Errorf("failed to write screenshot: %v", err)
+	}
+
+	return filename, nil
+}
+
+// recordingFilenamePattern matches recording_YYYYMMDD_HHMMSS.mp3, recording_YYYYMMDD_HHMMSS_XXXkbps.mp3
+// and the WAV files saved when ffmpeg is missing (recording_YYYYMMDD_HHMMSS.wav)
+var recordingFilenamePattern = regexp.MustCompile(`^recording_(\d{8}_\d{6})(?:_(\d+)kbps)?\.(?:mp3|wav)$`)
+
+// parseRecordingFilename extracts the recording time (local time) and bitrate from a
+// recording filename. The bitrate is 0 (unknown) when the name has no _XXXkbps suffix.
+func parseRecordingFilename(name string) (time.Time, int, bool) {
+	match := recordingFilenamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return time.Time{}, 0, false
+	}
+
+	timestamp, err := time.ParseInLocation("20060102_150405", match[1], time.Local)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read MP3 file: %v", err)
+		return time.Time{}, 0, false
 	}
 
-	return mp3Data, nil
+	bitrate := 0
+	if match[2] != "" {
+		bitrate, _ = strconv.Atoi(match[2])
+	}
+	return timestamp, bitrate, true
 }
 
 // GetStoredAudioFiles returns all stored audio files
@@ -217,36 +347,52 @@ func (as *AudioStorage) GetStoredAudioFiles() ([]AudioFile, error) {
 
 	var audioFiles []AudioFile
 	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".mp3" {
+		if ext := filepath.Ext(file.Name()); ext == ".mp3" || ext == ".wav" {
 			fileInfo, err := file.Info()
-			if err != nil {
+			if err != nil || fileInfo.Size() == 0 {
 				continue
 			}
 
-			// Parse filename to extract metadata
+			// Parse filename to extract metadata, falling back to the modification time
 			audioFile := AudioFile{
 				Filename:  file.Name(),
 				Timestamp: fileInfo.ModTime(),
 				Size:      fileInfo.Size(),
 			}
+			if timestamp, bitrate, ok := parseRecordingFilename(file.Name()); ok {
+				audioFile.Timestamp = timestamp
+				audioFile.Bitrate = bitrate
+			}
 
-			// Extract bitrate from filename (simplified)
-			if len(file.Name()) > 10 {
-				// Assume format: recording_YYYYMMDD_HHMMSS_XXXkbps.mp3
-				audioFile.Bitrate = 128 // Default, would parse from filename in real implementation
+			// Estimate duration from the constant bitrate, or read it from the WAV header
+			if ext == ".wav" {
+				if duration, err := wavDuration(filepath.Join(as.baseDir, file.Name())); err == nil {
+					audioFile.Duration = duration
+				}
+			} else if audioFile.Bitrate > 0 {
+				audioFile.Duration = time.Duration(audioFile.Size*8) * time.Second / time.Duration(audioFile.Bitrate*1000)
 			}
 
 			audioFiles = append(audioFiles, audioFile)
 		}
 	}
 
+	sortAudioFiles(audioFiles, as.sortOrder)
+
 	return audioFiles, nil
 }
 
-// DeleteAudioFile deletes a stored audio file
+// DeleteAudioFile deletes a stored audio file and its transcript metadata, if any
 func (as *AudioStorage) DeleteAudioFile(filename string) error {
-	filepath := filepath.Join(as.baseDir, filename)
-	return os.Remove(filepath)
+	if err := os.Remove(filepath.Join(as.baseDir, filename)); err != nil {
+		return err
+	}
+
+	metaFilename := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".json"
+	if err := os.Remove(filepath.Join(as.baseDir, metaFilename)); err != nil && !os.IsNotExist(err) {
+		Errorf("Failed to delete transcript metadata %s: %v", metaFilename, err)
+	}
+	return nil
 }
 
 // GetAudioFilePath returns the full path to an audio file
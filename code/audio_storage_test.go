@@ -0,0 +1,325 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeTestFile creates name in dir with the given contents
+func writeTestFile(t *testing.T, dir, name string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func TestSortAudioFiles(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	files := []AudioFile{
+		{Filename: "b", Timestamp: base.Add(time.Minute), Size: 300, Duration: 10 * time.Second},
+		{Filename: "a", Timestamp: base, Size: 100, Duration: 30 * time.Second},
+		{Filename: "c", Timestamp: base.Add(2 * time.Minute), Size: 200, Duration: 20 * time.Second},
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{AudioSortNewest, []string{"c", "b", "a"}},
+		{AudioSortOldest, []string{"a", "b", "c"}},
+		{AudioSortSize, []string{"b", "c", "a"}},
+		{AudioSortDuration, []string{"a", "c", "b"}},
+		{"unknown", []string{"c", "b", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			sorted := append([]AudioFile(nil), files...)
+			sortAudioFiles(sorted, tt.order)
+			for i, name := range tt.want {
+				if sorted[i].Filename != name {
+					t.Fatalf("order %q: position %d is %q, want %q", tt.order, i, sorted[i].Filename, name)
+				}
+			}
+		})
+	}
+}
+
+func TestSortAudioFilesIsStable(t *testing.T) {
+	files := []AudioFile{
+		{Filename: "first", Size: 100},
+		{Filename: "second", Size: 100},
+		{Filename: "third", Size: 100},
+	}
+	sortAudioFiles(files, AudioSortSize)
+	for i, name := range []string{"first", "second", "third"} {
+		if files[i].Filename != name {
+			t.Fatalf("position %d is %q, want %q", i, files[i].Filename, name)
+		}
+	}
+}
+
+func TestSetSortOrder(t *testing.T) {
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{AudioSortNewest, AudioSortNewest},
+		{AudioSortOldest, AudioSortOldest},
+		{AudioSortSize, AudioSortSize},
+		{AudioSortDuration, AudioSortDuration},
+		{"", AudioSortNewest},
+		{"alphabetical", AudioSortNewest},
+	}
+
+	for _, tt := range tests {
+		as := &AudioStorage{sortOrder: AudioSortOldest}
+		as.SetSortOrder(tt.order)
+		if as.sortOrder != tt.want {
+			t.Errorf("SetSortOrder(%q) = %q, want %q", tt.order, as.sortOrder, tt.want)
+		}
+	}
+}
+
+func TestGetStoredAudioFilesUsesSortOrder(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	for name, modTime := range map[string]time.Time{
+		"recording_20240501_120000_64kbps.mp3": base,
+		"recording_20240501_130000_64kbps.mp3": base.Add(time.Hour),
+		"recording_20240501_110000_64kbps.mp3": base.Add(-time.Hour),
+	} {
+		writeTestFile(t, dir, name, []byte{0xFF, 0xFB, 0x90, 0x00})
+		if err := os.Chtimes(filepath.Join(dir, name), modTime, modTime); err != nil {
+			t.Fatalf("Chtimes %s: %v", name, err)
+		}
+	}
+
+	as := &AudioStorage{baseDir: dir}
+	as.SetSortOrder(AudioSortOldest)
+	files, err := as.GetStoredAudioFiles()
+	if err != nil {
+		t.Fatalf("GetStoredAudioFiles: %v", err)
+	}
+	want := []string{
+		"recording_20240501_110000_64kbps.mp3",
+		"recording_20240501_120000_64kbps.mp3",
+		"recording_20240501_130000_64kbps.mp3",
+	}
+	if len(files) != len(want) {
+		t.Fatalf("got %d files, want %d", len(files), len(want))
+	}
+	for i, name := range want {
+		if files[i].Filename != name {
+			t.Errorf("position %d is %q, want %q", i, files[i].Filename, name)
+		}
+	}
+}
+
+func TestParseRecordingFilename(t *testing.T) {
+	at := time.Date(2024, 5, 1, 13, 4, 5, 0, time.Local)
+	tests := []struct {
+		name        string
+		filename    string
+		wantTime    time.Time
+		wantBitrate int
+		wantOK      bool
+	}{
+		{"with bitrate", "recording_20240501_130405_320kbps.mp3", at, 320, true},
+		{"low bitrate", "recording_20240501_130405_32kbps.mp3", at, 32, true},
+		{"bare mp3", "recording_20240501_130405.mp3", at, 0, true},
+		{"wav fallback", "recording_20240501_130405.wav", at, 0, true},
+		{"other prefix", "voice_20240501_130405.mp3", time.Time{}, 0, false},
+		{"missing time", "recording_20240501.mp3", time.Time{}, 0, false},
+		{"invalid date", "recording_20241301_130405.mp3", time.Time{}, 0, false},
+		{"sidecar", "recording_20240501_130405_64kbps.json", time.Time{}, 0, false},
+		{"bad suffix", "recording_20240501_130405_fastkbps.mp3", time.Time{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp, bitrate, ok := parseRecordingFilename(tt.filename)
+			if ok != tt.wantOK || bitrate != tt.wantBitrate || !timestamp.Equal(tt.wantTime) {
+				t.Errorf("parseRecordingFilename(%q) = (%v, %d, %v), want (%v, %d, %v)",
+					tt.filename, timestamp, bitrate, ok, tt.wantTime, tt.wantBitrate, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGetStoredAudioFilesParsesFilenames(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "recording_20240501_130405_64kbps.mp3", make([]byte, 8000))
+	writeTestFile(t, dir, "recording_20240502_090000.mp3", make([]byte, 100))
+	writeTestFile(t, dir, "dictation.mp3", make([]byte, 100))
+
+	// A name without a timestamp falls back to the modification time
+	modTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.Local)
+	if err := os.Chtimes(filepath.Join(dir, "dictation.mp3"), modTime, modTime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	as := &AudioStorage{baseDir: dir, sortOrder: AudioSortNewest}
+	files, err := as.GetStoredAudioFiles()
+	if err != nil {
+		t.Fatalf("GetStoredAudioFiles: %v", err)
+	}
+	byName := map[string]AudioFile{}
+	for _, file := range files {
+		byName[file.Filename] = file
+	}
+
+	tests := []struct {
+		filename     string
+		wantTime     time.Time
+		wantBitrate  int
+		wantDuration time.Duration
+	}{
+		// 8000 bytes at 64 kbps is one second
+		{"recording_20240501_130405_64kbps.mp3", time.Date(2024, 5, 1, 13, 4, 5, 0, time.Local), 64, time.Second},
+		{"recording_20240502_090000.mp3", time.Date(2024, 5, 2, 9, 0, 0, 0, time.Local), 0, 0},
+		{"dictation.mp3", modTime, 0, 0},
+	}
+	for _, tt := range tests {
+		file, ok := byName[tt.filename]
+		if !ok {
+			t.Errorf("%s is not listed", tt.filename)
+			continue
+		}
+		if !file.Timestamp.Equal(tt.wantTime) || file.Bitrate != tt.wantBitrate || file.Duration != tt.wantDuration {
+			t.Errorf("%s: got (%v, %d kbps, %v), want (%v, %d kbps, %v)", tt.filename,
+				file.Timestamp, file.Bitrate, file.Duration, tt.wantTime, tt.wantBitrate, tt.wantDuration)
+		}
+	}
+}
+
+// testTonePCM returns seconds of a 440 Hz mono tone as 16-bit PCM
+func testTonePCM(sampleRate int, seconds float64) []byte {
+	samples := make([]int16, int(float64(sampleRate)*seconds))
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/float64(sampleRate)))
+	}
+	return samplesToPCM(samples)
+}
+
+// withoutFFmpeg hides ffmpeg from the lookup for the rest of the test, so the
+// built-in encoder is used
+func withoutFFmpeg(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	ffmpegOnce = sync.Once{}
+	t.Cleanup(func() { ffmpegOnce = sync.Once{} })
+}
+
+// countMP3Frames walks the MPEG-2 Layer III frames of a 16 kHz stream and
+// returns their number, failing on anything that is not a frame header
+func countMP3Frames(data []byte) (int, error) {
+	frames := 0
+	for offset := 0; offset < len(data); frames++ {
+		header := data[offset:]
+		if !isMP3FrameHeader(header) {
+			return frames, fmt.Errorf("invalid frame header at byte %d", offset)
+		}
+		bitrate := mpeg2Bitrates[header[2]>>4-1]
+		padding := int(header[2]>>1) & 1
+		offset += 72*bitrate*1000/recordingSampleRate + padding
+	}
+	return frames, nil
+}
+
+func TestConvertToMP3WritesFrameHeader(t *testing.T) {
+	withoutFFmpeg(t)
+
+	as := &AudioStorage{baseDir: t.TempDir()}
+	tests := []struct {
+		bitrate          int
+		wantBitrateIndex byte
+	}{
+		{32, 4},
+		{64, 8},
+		{128, 11}, // 16 kHz mono frames above 112 kbps are larger than the encoder can fill
+	}
+	for _, tt := range tests {
+		data, err := as.ConvertToMP3(testTonePCM(recordingSampleRate, 0.5), recordingSampleRate, tt.bitrate)
+		if err != nil {
+			t.Fatalf("ConvertToMP3 at %d kbps: %v", tt.bitrate, err)
+		}
+		name := fmt.Sprintf("tone_%d.mp3", tt.bitrate)
+		writeTestFile(t, as.baseDir, name, data)
+		if err := checkMP3File(filepath.Join(as.baseDir, name)); err != nil {
+			t.Fatalf("ConvertToMP3 at %d kbps: %v", tt.bitrate, err)
+		}
+		if got := data[2] >> 4; got != tt.wantBitrateIndex {
+			t.Errorf("ConvertToMP3 at %d kbps: bitrate index = %d, want %d", tt.bitrate, got, tt.wantBitrateIndex)
+		}
+
+		// 8000 samples fill 13 frames of 576 and part of a 14th
+		if frames, err := countMP3Frames(data); err != nil || frames != 14 {
+			t.Errorf("ConvertToMP3 at %d kbps: %d frames (%v), want 14", tt.bitrate, frames, err)
+		}
+	}
+}
+
+func TestStoreAudioWithoutFFmpeg(t *testing.T) {
+	withoutFFmpeg(t)
+
+	as := &AudioStorage{baseDir: t.TempDir()}
+	pcm := testTonePCM(recordingSampleRate, 0.5)
+
+	stored, err := as.StoreAudioAt(pcm, recordingSampleRate, 1, 64)
+	if err != nil {
+		t.Fatalf("StoreAudioAt: %v", err)
+	}
+	if filepath.Ext(stored.Filename) != ".mp3" || stored.Bitrate != 64 {
+		t.Errorf("stored %s at %d kbps, want an MP3 at 64 kbps", stored.Filename, stored.Bitrate)
+	}
+	if err := checkMP3File(filepath.Join(as.baseDir, stored.Filename)); err != nil {
+		t.Errorf("stored MP3: %v", err)
+	}
+}
+
+func TestStoreAudioUnsupportedRateAsWAV(t *testing.T) {
+	withoutFFmpeg(t)
+
+	// MP3 has no 96 kHz sample rate
+	const sampleRate = 96000
+	as := &AudioStorage{baseDir: t.TempDir()}
+	pcm := testTonePCM(sampleRate, 0.5)
+
+	stored, err := as.StoreAudioAt(pcm, sampleRate, 1, 64)
+	if err != nil {
+		t.Fatalf("StoreAudioAt: %v", err)
+	}
+	if filepath.Ext(stored.Filename) != ".wav" || stored.Bitrate != 0 {
+		t.Errorf("stored %s at %d kbps, want a WAV with unknown bitrate", stored.Filename, stored.Bitrate)
+	}
+	if stored.Duration != 500*time.Millisecond {
+		t.Errorf("stored duration = %v, want 500ms", stored.Duration)
+	}
+
+	data, err := os.ReadFile(filepath.Join(as.baseDir, stored.Filename))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(data, CreateWAVFile(pcm, sampleRate, 1)) {
+		t.Error("stored WAV does not hold the original samples")
+	}
+}
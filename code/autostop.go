@@ -0,0 +1,108 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/gordonklaus/portaudio"
+)
+
+// autoStopEnabled reports whether the current recording should stop on silence.
+// Live recordings are excluded since pauses there are expected.
+func (a *AppState) autoStopEnabled() bool {
+	return a.config.AutoStopSilence > 0 && !a.continuous
+}
+
+// trackSilence updates the rolling silence duration from an incoming buffer.
+// It is called from the audio callback.
+func (a *AppState) trackSilence(samples []int16) {
+	if !a.autoStopEnabled() || len(samples) == 0 {
+		return
+	}
+	if computeAudioLevel(samples).RMS < float64(a.config.AutoStopThreshold) {
+		a.silentSamples.Add(int64(len(samples)))
+		return
+	}
+	a.silentSamples.Store(0)
+	a.heardSpeech.Store(true)
+}
+
+// resetSilenceTracking clears the silence state before a new recording starts
+func (a *AppState) resetSilenceTracking() {
+	a.silentSamples.Store(0)
+	a.heardSpeech.Store(false)
+}
+
+// runAutoStop stops the given recording once speech has been followed by the
+// configured period of silence. Silence before the first words is ignored, and the
+// recording must already be long enough to pass the minimum-length check.
+func (a *AppState) runAutoStop(stream *portaudio.Stream) {
+	if !a.autoStopEnabled() {
+		return
+	}
+	timeout := a.config.AutoStopSilence
+	Infof("Auto-stop: stopping after %v of silence (RMS < %d)", timeout, a.config.AutoStopThreshold)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !a.isCurrentRecording(stream) {
+			return
+		}
+		if !a.heardSpeech.Load() {
+			continue
+		}
+
+		silence := time.Duration(a.silentSamples.Load()) * time.Second / time.Duration(a.samplesPerSecond())
+		if silence < timeout {
+			continue
+		}
+
+		a.audioMutex.Lock()
+		buffered := len(a.audioBuffer)
+		a.audioMutex.Unlock()
+		if buffered < a.samplesPerSecond()*minRecordingSeconds {
+			continue
+		}
+
+		Infof("Auto-stop: %v of silence detected, stopping recording", silence.Round(100*time.Millisecond))
+		a.stopRecordingOnUI(stream, "Silence detected, transcribing...")
+		return
+	}
+}
+
+// stopRecordingOnUI stops the given recording from a monitoring goroutine and
+// shows status. The stop runs on the UI goroutine, where it is skipped if the
+// user already stopped the recording or started a new one in the meantime.
+func (a *AppState) stopRecordingOnUI(stream *portaudio.Stream, status string) {
+	fyne.Do(func() {
+		if a.stream != stream || !a.isRecording {
+			Debugf("stopRecordingOnUI: recording already stopped, not stopping again")
+			return
+		}
+		setStatusText(a.statusLabel, status)
+		if err := a.StopRecording(); err != nil {
+			Errorf("Failed to stop recording: %v", err)
+		}
+	})
+}
@@ -0,0 +1,185 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+)
+
+// chunkOverlap is how much audio consecutive parts share when no pause is found
+// near a boundary, so a word cut in half is heard completely by one of them
+const chunkOverlap = time.Second
+
+// maxOverlapWords limits how many repeated words are looked for at a boundary
+const maxOverlapWords = 8
+
+// audioSegment is a range of sample indices [Start, End) within a recording
+type audioSegment struct {
+	Start int
+	End   int
+}
+
+// splitSegments splits samples into parts of about chunkSamples. Each cut is made
+// at a pause when possible; otherwise the next part starts overlapSamples early.
+// A short tail is merged into the last part instead of being sent on its own.
+func splitSegments(samples []int16, sampleRate int, chunkSamples int, overlapSamples int) []audioSegment {
+	var segments []audioSegment
+	start := 0
+	for chunkSamples > 0 && len(samples)-start > chunkSamples*3/2 {
+		window := samples[start : start+chunkSamples]
+		if cut, ok := findSilenceBoundary(window, sampleRate, chunkSamples/2); ok {
+			segments = append(segments, audioSegment{Start: start, End: start + cut})
+			start += cut
+			continue
+		}
+		segments = append(segments, audioSegment{Start: start, End: start + chunkSamples})
+		start += chunkSamples - overlapSamples
+	}
+	return append(segments, audioSegment{Start: start, End: len(samples)})
+}
+
+// normalizeWord lowercases a word and strips punctuation for overlap matching
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// trimOverlap removes words at the start of next that repeat the end of prev,
+// which happens when two parts share overlapping audio
+func trimOverlap(prev string, next string) string {
+	prevWords := strings.Fields(prev)
+	nextWords := strings.Fields(next)
+
+	for k := min(maxOverlapWords, len(prevWords), len(nextWords)); k > 0; k-- {
+		match := true
+		for i := 0; i < k; i++ {
+			if normalizeWord(prevWords[len(prevWords)-k+i]) != normalizeWord(nextWords[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return strings.Join(nextWords[k:], " ")
+		}
+	}
+	return next
+}
+
+// chunkSegments returns the parts a finished recording should be transcribed in,
+// or nil when chunked transcription is off or the recording is short
+func (a *AppState) chunkSegments(samples []int16) []audioSegment {
+	if !a.config.ChunkedTranscription || a.continuous {
+		return nil
+	}
+	rate := int(a.sampleRate)
+	chunkSamples := int(time.Duration(rate) * a.config.ChunkLength / time.Second)
+	overlapSamples := int(time.Duration(rate) * chunkOverlap / time.Second)
+
+	segments := splitSegments(samples, rate, chunkSamples, overlapSamples)
+	if len(segments) < 2 {
+		return nil
+	}
+	Infof("Chunked transcription: splitting %d samples into %d parts", len(samples), len(segments))
+	return segments
+}
+
+// processChunkedJob transcribes the job's parts in order and appends each result
+// to the editor as it arrives. A failure or cancel keeps the parts shown so far.
+func (a *AppState) processChunkedJob(job transcriptionJob) {
+	var parts []string
+	var corrections []*CorrectionJSON
+	language := ""
+	inserted := false
+	completed := true
+
+	for i, seg := range job.segments {
+		postStatusText(a.statusLabel, fmt.Sprintf("Transcribing part %d/%d...", i+1, len(job.segments)))
+
+		part := job
+		part.audioData = job.audioData[seg.Start*2 : seg.End*2]
+		part.segments = nil
+		result := a.transcribeJob(part)
+
+		if result.Canceled {
+			postStatusText(a.statusLabel, fmt.Sprintf("Transcription canceled after %d/%d parts", i, len(job.segments)))
+			completed = false
+			break
+		}
+		if result.Err != nil {
+			Errorf("Chunked transcription: part %d failed: %v", i+1, result.Err)
+			postStatusText(a.statusLabel, fmt.Sprintf("Transcription failed at part %d/%d: %s",
+				i+1, len(job.segments), transcriptionFailureReason(result.Err)))
+			completed = false
+			break
+		}
+
+		text := result.Text
+		if len(parts) > 0 && seg.Start < job.segments[i-1].End {
+			text = trimOverlap(parts[len(parts)-1], text)
+		}
+		if text == "" {
+			continue
+		}
+		language = result.Language
+		if result.Correction != nil {
+			corrections = append(corrections, result.Correction)
+		}
+
+		// The first part is inserted like a normal result, later ones are appended
+		first := !inserted
+		fyne.Do(func() {
+			if first {
+				a.correctedText.SetText(applyTranscription(a.correctedText.Text, text, job.mode))
+				if job.mode == "add" {
+					a.addSpaceReserved = false
+				}
+			} else {
+				a.correctedText.SetText(a.correctedText.Text + " " + text)
+			}
+		})
+		inserted = true
+		parts = append(parts, text)
+	}
+
+	if !inserted {
+		if job.mode == "add" {
+			fyne.Do(a.unreserveAddSpace)
+		}
+		if completed {
+			postStatusText(a.statusLabel, "No speech detected")
+		}
+		return
+	}
+
+	fullText := strings.Join(parts, " ")
+	Infof("Chunked transcription: inserted %d parts (%d characters)", len(parts), len(fullText))
+	fyne.Do(func() {
+		if err := copyToClipboard(a.correctedText.Text); err != nil {
+			Errorf("Failed to copy to clipboard: %v", err)
+		}
+	})
+	a.saveJobTranscript(job, fullText, language)
+	a.recordHistory(job, fullText, language)
+	a.showCorrection(mergeCorrections(corrections))
+
+	if completed {
+		postStatusText(a.statusLabel, fmt.Sprintf("Transcription completed (%d parts)", len(job.segments)))
+	}
+}
@@ -0,0 +1,89 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errNoClipboardAudio is returned when the clipboard holds no audio data
+var errNoClipboardAudio = errors.New("clipboard has no audio")
+
+// errClipboardReadUnsupported is returned where the clipboard can only be written
+var errClipboardReadUnsupported = errors.New("reading audio from the clipboard is not supported on this platform")
+
+// pipeToCommand runs cmd with data written to its standard input
+func pipeToCommand(cmd *exec.Cmd, data []byte) error {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if _, err := stdin.Write(data); err != nil {
+		return err
+	}
+
+	if err := stdin.Close(); err != nil {
+		return err
+	}
+
+	return cmd.Wait()
+}
+
+// readClipboardAudio reads audio bytes from the clipboard (xclip or wl-paste on Linux).
+// It returns the data and its MIME type, or errNoClipboardAudio if the
+// clipboard does not offer any audio/* target.
+func readClipboardAudio() ([]byte, string, error) {
+	listCmd, _, err := clipboardReadCommands("")
+	if err != nil {
+		return nil, "", err
+	}
+	targets, err := listCmd.Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list clipboard targets: %v", err)
+	}
+
+	mimeType := ""
+	for _, target := range strings.Split(string(targets), "\n") {
+		target = strings.TrimSpace(target)
+		if strings.HasPrefix(target, "audio/") {
+			mimeType = target
+			break
+		}
+	}
+	if mimeType == "" {
+		return nil, "", errNoClipboardAudio
+	}
+
+	_, readCmd, err := clipboardReadCommands(mimeType)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := readCmd.Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s from clipboard: %v", mimeType, err)
+	}
+	if len(data) == 0 {
+		return nil, "", errNoClipboardAudio
+	}
+
+	return data, mimeType, nil
+}
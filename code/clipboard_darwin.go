@@ -0,0 +1,53 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// writeClipboard puts data on the macOS clipboard. Text goes through pbcopy;
+// PNG images are written to a temporary file and loaded with osascript.
+func writeClipboard(data []byte, mimeType string) error {
+	switch mimeType {
+	case "":
+		return pipeToCommand(exec.Command("pbcopy"), data)
+	case "image/png":
+		tmpFile, err := os.CreateTemp("", "micapp_clipboard_*.png")
+		if err != nil {
+			return fmt.Errorf("failed to create temp image file: %v", err)
+		}
+		defer os.Remove(tmpFile.Name())
+		if _, err := tmpFile.Write(data); err != nil {
+			tmpFile.Close()
+			return fmt.Errorf("failed to write temp image file: %v", err)
+		}
+		tmpFile.Close()
+
+		script := fmt.Sprintf(`set the clipboard to (read (POSIX file %q) as «class PNGf»)`, tmpFile.Name())
+		if out, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+			return fmt.Errorf("osascript failed: %v: %s", err, out)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported clipboard type %q", mimeType)
+	}
+}
+
+// clipboardReadCommands is not available on macOS
+func clipboardReadCommands(mimeType string) (*exec.Cmd, *exec.Cmd, error) {
+	return nil, nil, errClipboardReadUnsupported
+}
@@ -0,0 +1,96 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+//go:build !darwin && !windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// errNoClipboardTool is returned when neither xclip nor wl-clipboard is installed
+var errNoClipboardTool = errors.New("no clipboard tool found: install xclip (X11) or wl-clipboard (Wayland)")
+
+// Clipboard backends
+const (
+	clipboardXclip   = "xclip"
+	clipboardWayland = "wl-copy"
+)
+
+var (
+	clipboardBackendOnce sync.Once
+	clipboardBackendName string
+)
+
+// clipboardBackend picks the clipboard tool once per run: wl-copy on Wayland
+// sessions, xclip otherwise, falling back to whichever is installed.
+// It returns an empty string if neither is available.
+func clipboardBackend() string {
+	clipboardBackendOnce.Do(func() {
+		wayland := os.Getenv("WAYLAND_DISPLAY") != "" || os.Getenv("XDG_SESSION_TYPE") == "wayland"
+		candidates := []string{clipboardXclip, clipboardWayland}
+		if wayland {
+			candidates = []string{clipboardWayland, clipboardXclip}
+		}
+		for _, tool := range candidates {
+			if _, err := exec.LookPath(tool); err == nil {
+				clipboardBackendName = tool
+				break
+			}
+		}
+		Infof("Clipboard backend: %q (wayland session: %v)", clipboardBackendName, wayland)
+	})
+	return clipboardBackendName
+}
+
+// writeClipboard puts data on the clipboard. mimeType may be empty for plain text.
+func writeClipboard(data []byte, mimeType string) error {
+	var cmd *exec.Cmd
+	switch clipboardBackend() {
+	case clipboardXclip:
+		args := []string{"-selection", "clipboard"}
+		if mimeType != "" {
+			args = append(args, "-t", mimeType)
+		}
+		cmd = exec.Command("xclip", args...)
+	case clipboardWayland:
+		var args []string
+		if mimeType != "" {
+			args = append(args, "--type", mimeType)
+		}
+		cmd = exec.Command("wl-copy", args...)
+	default:
+		return errNoClipboardTool
+	}
+
+	return pipeToCommand(cmd, data)
+}
+
+// clipboardReadCommands returns the commands listing the clipboard's MIME types
+// and reading one of them for the selected backend
+func clipboardReadCommands(mimeType string) (list *exec.Cmd, read *exec.Cmd, err error) {
+	switch clipboardBackend() {
+	case clipboardXclip:
+		return exec.Command("xclip", "-selection", "clipboard", "-t", "TARGETS", "-o"),
+			exec.Command("xclip", "-selection", "clipboard", "-t", mimeType, "-o"), nil
+	case clipboardWayland:
+		return exec.Command("wl-paste", "--list-types"),
+			exec.Command("wl-paste", "--no-newline", "--type", mimeType), nil
+	default:
+		return nil, nil, errNoClipboardTool
+	}
+}
@@ -0,0 +1,61 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// writeClipboard puts data on the Windows clipboard using PowerShell.
+// Text is read from stdin as UTF-8 so non-ASCII characters survive (clip.exe
+// would mangle them); PNG images are loaded from a temporary file.
+func writeClipboard(data []byte, mimeType string) error {
+	switch mimeType {
+	case "":
+		cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command",
+			"[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())")
+		return pipeToCommand(cmd, data)
+	case "image/png":
+		tmpFile, err := os.CreateTemp("", "micapp_clipboard_*.png")
+		if err != nil {
+			return fmt.Errorf("failed to create temp image file: %v", err)
+		}
+		defer os.Remove(tmpFile.Name())
+		if _, err := tmpFile.Write(data); err != nil {
+			tmpFile.Close()
+			return fmt.Errorf("failed to write temp image file: %v", err)
+		}
+		tmpFile.Close()
+
+		// Clipboard access from Windows Forms requires a single-threaded apartment
+		path := strings.ReplaceAll(tmpFile.Name(), "'", "''")
+		script := fmt.Sprintf("Add-Type -AssemblyName System.Windows.Forms,System.Drawing; "+
+			"$img = [System.Drawing.Image]::FromFile('%s'); "+
+			"[System.Windows.Forms.Clipboard]::SetImage($img); $img.Dispose()", path)
+		if out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-STA", "-Command", script).CombinedOutput(); err != nil {
+			return fmt.Errorf("powershell failed: %v: %s", err, out)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported clipboard type %q", mimeType)
+	}
+}
+
+// clipboardReadCommands is not available on Windows
+func clipboardReadCommands(mimeType string) (*exec.Cmd, *exec.Cmd, error) {
+	return nil, nil, errClipboardReadUnsupported
+}
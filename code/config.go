@@ -0,0 +1,312 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds user-configurable application settings
+type Config struct {
+	OpenAIBaseURL   string // OpenAI-compatible API root for transcription and correction, empty for the official API
+	WhisperModel    string // Transcription model, e.g. whisper-1 or gpt-4o-transcribe
+	CorrectionModel string // Chat model for text correction, e.g. gpt-4o-mini
+
+	Transcriber      string // Transcription backend: "openai", "whisper-cpp" or "whisper-server"
+	WhisperCppBinary string // whisper.cpp command-line tool, a name in PATH or a path
+	WhisperCppModel  string // Path to the ggml model file whisper.cpp loads, e.g. ggml-base.bin
+	WhisperServerURL string // API root of the local OpenAI-compatible transcription server
+
+	Corrector     string // Correction backend: "openai" or "local"
+	LocalLLMURL   string // API root of the local OpenAI-compatible chat server, e.g. Ollama
+	LocalLLMModel string // Model the local chat server corrects with
+
+	CaptureEnabled   bool     // Run the global keyboard and mouse hook that screenshot capture needs
+	CaptureKey       string   // Key that arms region capture (e.g. "printscreen"), empty to disable
+	CaptureDisplay   int      // Display index selections are constrained to, -1 for all displays
+	EmbedTranscript  bool     // Embed the transcript as PNG text metadata when saving edited screenshots
+	MaxEditorWindows int      // Maximum number of image editor windows open at once
+	DefaultMode      string   // Recording mode of the main button: "start" (replace) or "add" (append)
+	LogLevel         LogLevel // Minimum level written by the structured logger
+	LogKeystrokes    bool     // Log global key events at DEBUG level (for diagnosing hotkeys)
+	LanguageCheck    bool     // Warn when the transcription is not in the requested language
+	WindowPosition   bool     // Save and restore the main window position with wmctrl/xdotool (Linux only)
+
+	ReviewCorrections bool          // Ask before replacing the raw transcription with the LLM correction
+	ReviewTimeout     time.Duration // Accept a correction under review after this long, 0 to wait indefinitely
+
+	TimeLapseRegion   string        // Default time-lapse region as "x,y,width,height"
+	TimeLapseInterval time.Duration // Default delay between time-lapse captures
+
+	ContinuousInterval time.Duration // Target chunk length for live (continuous) transcription
+	UploadCodec        string        // Codec audio is uploaded in for transcription: "mp3" or "opus"
+
+	FormatParagraphs     bool          // Split long transcriptions into paragraphs after correction
+	VoiceCommands        bool          // Carry out spoken commands such as "new line" in transcriptions
+	VoiceCommandsFile    string        // JSON file extending the built-in voice command vocabulary
+	ChunkedTranscription bool          // Transcribe long recordings in parts shown as they arrive
+	ChunkLength          time.Duration // Target length of each part for chunked transcription
+
+	AutoStopSilence   time.Duration // Stop recording after this much silence following speech, 0 to disable
+	AutoStopThreshold int           // RMS level below which input counts as silence for auto-stop
+	MaxRecording      time.Duration // Stop and transcribe a recording after this long, 0 for no limit (Live is exempt)
+	HighPassCutoff    int           // Filter out rumble and hum below this many Hz before transcribing, 0 to disable
+	NoiseGate         int           // Silence stretches quieter than this RMS level before transcribing, 0 to disable
+
+	AudioSortOrder   string        // Order of the Audio Files list: newest, oldest, size or duration
+	RecordingBitrate int           // MP3 bitrate in kbps recordings are stored at
+	SampleRate       int           // Sample rate in Hz requested from the microphone
+	Channels         int           // Input channels to record: 1 (mono) or 2 (stereo)
+	RetentionKeep    int           // Keep only the newest N recordings at startup, 0 for unlimited
+	RetentionMaxAge  time.Duration // Delete recordings older than this at startup, 0 to keep all
+	HistoryMaxKB     int           // Size in KB at which the transcription history is rotated, 0 to never rotate
+
+	PNGCompression png.CompressionLevel // Screenshot PNG compression: speed vs file size
+	CaptureScale   float64              // Physical pixels per logical pixel when cropping screenshots, 0 to detect
+}
+
+// LoadConfig reads the configuration from MICAPP_* environment variables,
+// falling back to defaults for anything that is unset or invalid
+func LoadConfig() *Config {
+	return &Config{
+		OpenAIBaseURL:   envString("OPENAI_BASE_URL", ""),
+		WhisperModel:    envString("WHISPER_MODEL", defaultWhisperModel),
+		CorrectionModel: envString("CORRECTION_MODEL", defaultCorrectionModel),
+
+		Transcriber:      envTranscriber("MICAPP_TRANSCRIBER", TranscriberOpenAI),
+		WhisperCppBinary: envString("MICAPP_WHISPER_CPP_BIN", defaultWhisperCppBinary),
+		WhisperCppModel:  envString("MICAPP_WHISPER_CPP_MODEL", ""),
+		WhisperServerURL: envString("MICAPP_WHISPER_SERVER_URL", defaultWhisperServerURL),
+
+		Corrector:     envCorrector("MICAPP_CORRECTOR", CorrectorOpenAI),
+		LocalLLMURL:   envString("MICAPP_LOCAL_LLM_URL", defaultLocalLLMURL),
+		LocalLLMModel: envString("MICAPP_LOCAL_LLM_MODEL", defaultLocalLLMModel),
+
+		CaptureEnabled:   envBool("MICAPP_SCREENSHOT_CAPTURE", true),
+		CaptureKey:       strings.ToLower(envString("MICAPP_CAPTURE_KEY", "")),
+		CaptureDisplay:   envInt("MICAPP_CAPTURE_DISPLAY", allDisplays),
+		EmbedTranscript:  envBool("MICAPP_EMBED_TRANSCRIPT", false),
+		MaxEditorWindows: envInt("MICAPP_MAX_EDITOR_WINDOWS", 1),
+		DefaultMode:      envMode("MICAPP_DEFAULT_MODE", "start"),
+		LogLevel:         envLogLevel("MICAPP_LOG_LEVEL", INFO),
+		LogKeystrokes:    envBool("MICAPP_LOG_KEYSTROKES", false),
+		LanguageCheck:    envBool("MICAPP_LANGUAGE_CHECK", false),
+		WindowPosition:   envBool("MICAPP_WINDOW_POSITION", false),
+
+		ReviewCorrections: envBool("MICAPP_REVIEW_CORRECTIONS", false),
+		ReviewTimeout:     time.Duration(envInt("MICAPP_REVIEW_TIMEOUT", 20)) * time.Second,
+
+		TimeLapseRegion:   envString("MICAPP_TIMELAPSE_REGION", ""),
+		TimeLapseInterval: time.Duration(envInt("MICAPP_TIMELAPSE_INTERVAL", 60)) * time.Second,
+
+		ContinuousInterval: time.Duration(envInt("MICAPP_CONTINUOUS_INTERVAL", 10)) * time.Second,
+		UploadCodec:        envUploadCodec("MICAPP_UPLOAD_CODEC", UploadCodecMP3),
+
+		FormatParagraphs:     envBool("MICAPP_FORMAT_PARAGRAPHS", false),
+		VoiceCommands:        envBool("MICAPP_VOICE_COMMANDS", false),
+		VoiceCommandsFile:    envString("MICAPP_VOICE_COMMANDS_FILE", ""),
+		ChunkedTranscription: envBool("MICAPP_CHUNKED_TRANSCRIPTION", false),
+		ChunkLength:          time.Duration(envInt("MICAPP_CHUNK_SECONDS", 15)) * time.Second,
+
+		AutoStopSilence:   time.Duration(envInt("MICAPP_AUTO_STOP_SILENCE", 0)) * time.Second,
+		AutoStopThreshold: envInt("MICAPP_AUTO_STOP_THRESHOLD", silenceRMSThreshold),
+		MaxRecording:      time.Duration(envInt("MICAPP_MAX_RECORDING_MINUTES", 10)) * time.Minute,
+		HighPassCutoff:    envInt("MICAPP_HIGHPASS_HZ", 0),
+		NoiseGate:         envInt("MICAPP_NOISE_GATE", 0),
+
+		AudioSortOrder:   strings.ToLower(envString("MICAPP_AUDIO_SORT", AudioSortNewest)),
+		RecordingBitrate: envBitrate("MICAPP_RECORDING_BITRATE", 128),
+		SampleRate:       envSampleRate("MICAPP_SAMPLE_RATE", recordingSampleRate),
+		Channels:         envChannels("MICAPP_CHANNELS", 1),
+		RetentionKeep:    envInt("MICAPP_RETENTION_KEEP", 0),
+		RetentionMaxAge:  time.Duration(envInt("MICAPP_RETENTION_DAYS", 0)) * 24 * time.Hour,
+		HistoryMaxKB:     envInt("MICAPP_HISTORY_MAX_KB", 5120),
+
+		PNGCompression: envPNGCompression("MICAPP_PNG_COMPRESSION", png.DefaultCompression),
+		CaptureScale:   envFloat("MICAPP_CAPTURE_SCALE", 0),
+	}
+}
+
+// usesOpenAI reports whether transcription or correction goes to the OpenAI
+// API, which needs OPENAI_API_KEY
+func (c *Config) usesOpenAI() bool {
+	return c.Transcriber == TranscriberOpenAI || c.Corrector == CorrectorOpenAI
+}
+
+// envPNGCompression returns a PNG compression level ("default", "speed", "best" or "none")
+// from an environment variable or def if unset or invalid
+func envPNGCompression(name string, def png.CompressionLevel) png.CompressionLevel {
+	value := strings.ToLower(envString(name, ""))
+	switch value {
+	case "":
+		return def
+	case "default":
+		return png.DefaultCompression
+	case "speed":
+		return png.BestSpeed
+	case "best":
+		return png.BestCompression
+	case "none":
+		return png.NoCompression
+	default:
+		Warnf("Invalid PNG compression for %s=%q, using default", name, value)
+		return def
+	}
+}
+
+// envUploadCodec returns an upload codec ("mp3" or "opus") from an environment variable
+func envUploadCodec(name string, def string) string {
+	value := strings.ToLower(envString(name, def))
+	if value != UploadCodecMP3 && value != UploadCodecOpus {
+		Warnf("Invalid upload codec for %s=%q, using default %q", name, value, def)
+		return def
+	}
+	return value
+}
+
+// envTranscriber returns a transcription backend ("openai", "whisper-cpp" or
+// "whisper-server") from an environment variable
+func envTranscriber(name string, def string) string {
+	value := strings.ToLower(envString(name, def))
+	switch value {
+	case TranscriberOpenAI, TranscriberWhisperCpp, TranscriberWhisperServer:
+		return value
+	default:
+		Warnf("Invalid transcriber for %s=%q, using default %q", name, value, def)
+		return def
+	}
+}
+
+// envCorrector returns a correction backend ("openai" or "local") from an environment variable
+func envCorrector(name string, def string) string {
+	value := strings.ToLower(envString(name, def))
+	if value != CorrectorOpenAI && value != CorrectorLocal {
+		Warnf("Invalid corrector for %s=%q, using default %q", name, value, def)
+		return def
+	}
+	return value
+}
+
+// envBitrate returns a standard MP3 bitrate in kbps from an environment variable
+func envBitrate(name string, def int) int {
+	value := envInt(name, def)
+	switch value {
+	case 32, 48, 64, 96, 128, 160, 192, 256, 320:
+		return value
+	default:
+		Warnf("Unsupported MP3 bitrate for %s=%d, using default %d", name, value, def)
+		return def
+	}
+}
+
+// envSampleRate returns a recording sample rate in Hz from an environment variable
+func envSampleRate(name string, def int) int {
+	value := envInt(name, def)
+	switch value {
+	case 8000, 16000, 22050, 24000, 32000, 44100, 48000:
+		return value
+	default:
+		Warnf("Unsupported sample rate for %s=%d, using default %d", name, value, def)
+		return def
+	}
+}
+
+// envChannels returns an input channel count (1 or 2) from an environment variable
+func envChannels(name string, def int) int {
+	value := envInt(name, def)
+	if value != 1 && value != 2 {
+		Warnf("Unsupported channel count for %s=%d, using default %d", name, value, def)
+		return def
+	}
+	return value
+}
+
+// envLogLevel returns a log level from an environment variable or def if unset or invalid
+func envLogLevel(name string, def LogLevel) LogLevel {
+	value := envString(name, "")
+	if value == "" {
+		return def
+	}
+	level, err := ParseLogLevel(value)
+	if err != nil {
+		Warnf("Invalid log level for %s=%q, using default %s", name, value, def)
+		return def
+	}
+	return level
+}
+
+// envMode returns a recording mode ("start" or "add") from an environment variable
+func envMode(name string, def string) string {
+	value := strings.ToLower(envString(name, def))
+	if value != "start" && value != "add" {
+		Warnf("Invalid recording mode for %s=%q, using default %q", name, value, def)
+		return def
+	}
+	return value
+}
+
+// envString returns the trimmed value of an environment variable or def if unset
+func envString(name string, def string) string {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// envInt returns an environment variable parsed as int or def if unset or invalid
+func envInt(name string, def int) int {
+	value := envString(name, "")
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		Warnf("Invalid integer for %s=%q, using default %d", name, value, def)
+		return def
+	}
+	return parsed
+}
+
+// envFloat returns an environment variable parsed as float64 or def if unset or invalid
+func envFloat(name string, def float64) float64 {
+	value := envString(name, "")
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		Warnf("Invalid number for %s=%q, using default %v", name, value, def)
+		return def
+	}
+	return parsed
+}
+
+// envBool returns an environment variable parsed as bool or def if unset or invalid
+func envBool(name string, def bool) bool {
+	value := envString(name, "")
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		Warnf("Invalid boolean for %s=%q, using default %v", name, value, def)
+		return def
+	}
+	return parsed
+}
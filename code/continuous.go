@@ -0,0 +1,136 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/gordonklaus/portaudio"
+)
+
+// silenceRMSThreshold is the RMS level below which a window counts as a pause
+const silenceRMSThreshold = 500
+
+// samplesToPCM converts int16 samples to little-endian 16-bit PCM bytes
+func samplesToPCM(samples []int16) []byte {
+	pcm := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		pcm[i*2] = byte(sample & 0xFF)
+		pcm[i*2+1] = byte((sample >> 8) & 0xFF)
+	}
+	return pcm
+}
+
+// findSilenceBoundary looks for the latest pause after the first minSamples samples
+// and returns the index in the middle of it, so words are not cut in half.
+// It returns false when the audio has no pause to cut at.
+func findSilenceBoundary(samples []int16, sampleRate int, minSamples int) (int, bool) {
+	window := sampleRate / 5 // 200ms
+	if window <= 0 {
+		return 0, false
+	}
+	for end := len(samples); end-window >= minSamples; end -= window / 2 {
+		if computeAudioLevel(samples[end-window:end]).RMS < silenceRMSThreshold {
+			return end - window/2, true
+		}
+	}
+	return 0, false
+}
+
+// takeContinuousChunk removes the next chunk from the recording buffer once about
+// interval worth of audio is available. The cut is made at a pause when possible;
+// without one the whole buffer is taken after twice the interval.
+func (a *AppState) takeContinuousChunk(interval time.Duration) []int16 {
+	chunkSamples := int(time.Duration(a.samplesPerSecond()) * interval / time.Second)
+
+	a.audioMutex.Lock()
+	defer a.audioMutex.Unlock()
+
+	if len(a.audioBuffer) < chunkSamples {
+		return nil
+	}
+
+	cut, ok := findSilenceBoundary(a.audioBuffer, a.samplesPerSecond(), chunkSamples/2)
+	if ok {
+		// Never split a stereo frame between two chunks
+		cut -= cut % int(max(a.channels, 1))
+	} else {
+		if len(a.audioBuffer) < 2*chunkSamples {
+			return nil
+		}
+		cut = len(a.audioBuffer)
+	}
+
+	chunk := append([]int16(nil), a.audioBuffer[:cut]...)
+	a.audioBuffer = append(make([]int16, 0, len(a.audioBuffer)-cut), a.audioBuffer[cut:]...)
+	return chunk
+}
+
+// runContinuousSlicer transcribes the recording in chunks while the given stream
+// keeps recording, appending each chunk's text live
+func (a *AppState) runContinuousSlicer(stream *portaudio.Stream) {
+	interval := a.config.ContinuousInterval
+	Infof("Continuous mode: slicing every ~%v at pauses", interval)
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	chunks := 0
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Stop once this recording has ended; processAudio handles the remainder
+		if !a.isCurrentRecording(stream) {
+			Infof("Continuous mode: recording ended after %d chunks", chunks)
+			return
+		}
+
+		chunk := a.takeContinuousChunk(interval)
+		if chunk == nil {
+			continue
+		}
+		chunks++
+		Infof("Continuous mode: queuing chunk %d (%d samples)", chunks, len(chunk))
+
+		mono := downmixToMono(chunk, a.channels)
+		a.filterAudio(mono)
+
+		fyne.Do(a.reserveAddSpace)
+		a.addToQueue(transcriptionJob{
+			audioData:   samplesToPCM(mono),
+			sampleRate:  a.sampleRate,
+			mode:        "add",
+			windowTitle: a.recordingWindow,
+		})
+		postStatusText(a.statusLabel, fmt.Sprintf("Live: chunk %d sent, still recording...", chunks))
+	}
+}
+
+// onLiveButtonClick starts a continuous recording, or stops it and transcribes the rest
+func (a *AppState) onLiveButtonClick() {
+	if !a.isRecording {
+		a.beginRecording("add", a.liveButton)
+		return
+	}
+	if err := a.FinalizeRecording(); err != nil {
+		Errorf("Failed to stop live recording: %v", err)
+		setStatusText(a.statusLabel, fmt.Sprintf("Stop error: %v", err))
+	}
+}
@@ -0,0 +1,87 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newCorrectionPanel builds the collapsible panel listing what the LLM changed
+// in the last transcription. It starts collapsed.
+func (a *AppState) newCorrectionPanel() *widget.Accordion {
+	a.correctionDetails = widget.NewLabel("No corrections yet")
+	a.correctionDetails.Wrapping = fyne.TextWrapWord
+	a.correctionPanel = widget.NewAccordion(widget.NewAccordionItem("Corrections", a.correctionDetails))
+	return a.correctionPanel
+}
+
+// showCorrection stores the correction of the last transcription and lists its
+// changes in the corrections panel. nil means the text was not corrected.
+func (a *AppState) showCorrection(correction *CorrectionJSON) {
+	fyne.Do(func() {
+		a.lastCorrection = correction
+		if a.correctionPanel == nil {
+			return
+		}
+
+		title, details := "Corrections", "The last transcription was not corrected"
+		if correction != nil {
+			title = fmt.Sprintf("Corrections (%d, confidence %.0f%%)", len(correction.Changes), correction.Confidence*100)
+			details = formatCorrectionChanges(correction.Changes)
+		}
+		a.correctionPanel.Items[0].Title = title
+		a.correctionDetails.SetText(details)
+		a.correctionPanel.Refresh()
+	})
+}
+
+// formatCorrectionChanges lists changes one per line as "type: 'original' → 'corrected' (description)"
+func formatCorrectionChanges(changes []Change) string {
+	if len(changes) == 0 {
+		return "No changes were needed"
+	}
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		lines[i] = fmt.Sprintf("%s: '%s' → '%s'", change.Type, change.Original, change.Corrected)
+		if change.Description != "" {
+			lines[i] += " (" + change.Description + ")"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mergeCorrections combines the corrections of a recording transcribed in parts:
+// all changes in order, with the average confidence. It returns nil if no part
+// was corrected.
+func mergeCorrections(corrections []*CorrectionJSON) *CorrectionJSON {
+	if len(corrections) == 0 {
+		return nil
+	}
+	merged := &CorrectionJSON{}
+	var original, corrected []string
+	for _, correction := range corrections {
+		original = append(original, correction.OriginalText)
+		corrected = append(corrected, correction.CorrectedText)
+		merged.Changes = append(merged.Changes, correction.Changes...)
+		merged.Confidence += correction.Confidence
+	}
+	merged.OriginalText = strings.Join(original, " ")
+	merged.CorrectedText = strings.Join(corrected, " ")
+	merged.Confidence /= float64(len(corrections))
+	return merged
+}
@@ -0,0 +1,78 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// correctionInstructionsPrefKey is the preferences key storing custom correction instructions
+const correctionInstructionsPrefKey = "correctionInstructions"
+
+// defaultCorrectionInstructions is the system message used until the user writes their own
+const defaultCorrectionInstructions = "You correct speech-to-text transcriptions. " +
+	"Fix recognition errors, grammar, punctuation and capitalization while preserving the speaker's meaning."
+
+// correctionFormatReminder is appended to custom instructions so they cannot
+// break the JSON answer the correction requests rely on
+const correctionFormatReminder = "Always answer with a single JSON object in the format given in the user message."
+
+// correctionMessages returns the chat messages for a correction prompt: the
+// instructions as the system message, followed by the prompt itself
+func (c *LLMClient) correctionMessages(prompt string) []Message {
+	var messages []Message
+	if instructions := strings.TrimSpace(c.Instructions()); instructions != "" {
+		messages = append(messages, Message{
+			Role:    "system",
+			Content: instructions + "\n\n" + correctionFormatReminder,
+		})
+	}
+	return append(messages, Message{Role: "user", Content: prompt})
+}
+
+// setCorrectionInstructions remembers the instructions and applies them to the
+// corrector, if it has been created yet
+func (a *AppState) setCorrectionInstructions(instructions string) {
+	a.clientsMutex.Lock()
+	defer a.clientsMutex.Unlock()
+	a.instructions = instructions
+	if a.llmClient != nil {
+		a.llmClient.SetInstructions(instructions)
+	}
+}
+
+// newCorrectionInstructionsEntry builds the editor for custom correction
+// instructions with a button restoring the default, and applies the saved text
+func (a *AppState) newCorrectionInstructionsEntry(prefs fyne.Preferences) fyne.CanvasObject {
+	instructions := prefs.StringWithFallback(correctionInstructionsPrefKey, defaultCorrectionInstructions)
+	a.setCorrectionInstructions(instructions)
+
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("e.g. Keep medical terminology, use British spelling, don't rephrase")
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetText(instructions)
+	entry.OnChanged = func(text string) {
+		a.setCorrectionInstructions(text)
+		prefs.SetString(correctionInstructionsPrefKey, text)
+	}
+
+	resetButton := widget.NewButton("Reset to default", func() {
+		entry.SetText(defaultCorrectionInstructions)
+	})
+	return container.NewBorder(nil, nil, nil, resetButton, entry)
+}
@@ -0,0 +1,173 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// reviewDecision is the outcome of reviewing an LLM correction
+type reviewDecision int
+
+const (
+	reviewAccepted reviewDecision = iota // Use the corrected text
+	reviewRejected                       // Keep the raw transcription
+	reviewCanceled                       // Transcription was canceled while waiting
+)
+
+// reviewCorrection shows the original and corrected text side by side, with the
+// changed words highlighted, and sends the user's decision on the returned
+// channel. Without an answer the correction is accepted after the configured
+// timeout (never if it is 0). The dialog is built and shown on the UI goroutine,
+// so a worker can wait on the channel.
+func (a *AppState) reviewCorrection(correction *CorrectionJSON) <-chan reviewDecision {
+	decision := make(chan reviewDecision, 1)
+	if a.mainWindow == nil {
+		decision <- reviewAccepted
+		return decision
+	}
+
+	answer := make(chan bool, 1)
+	var review *dialog.ConfirmDialog
+	var countdown *widget.Label
+	fyne.Do(func() {
+		var originalPhrases, correctedPhrases []string
+		for _, change := range correction.Changes {
+			originalPhrases = append(originalPhrases, change.Original)
+			correctedPhrases = append(correctedPhrases, change.Corrected)
+		}
+		countdown = widget.NewLabel("")
+		content := container.NewBorder(nil, countdown, nil, nil,
+			container.NewGridWithColumns(2,
+				container.NewBorder(widget.NewLabel("Original"), nil, nil, nil,
+					container.NewVScroll(highlightedText(correction.OriginalText, originalPhrases))),
+				container.NewBorder(widget.NewLabel("Corrected"), nil, nil, nil,
+					container.NewVScroll(highlightedText(correction.CorrectedText, correctedPhrases))),
+			),
+		)
+		review = dialog.NewCustomConfirm("Review correction", "Accept", "Keep original", content,
+			func(accept bool) { answer <- accept },
+			a.mainWindow)
+		review.Resize(fyne.NewSize(600, 300))
+		review.Show()
+		setStatusText(a.statusLabel, "Review the correction...")
+	})
+
+	go func() {
+		decision <- a.awaitReview(answer, func(text string) {
+			fyne.Do(func() { countdown.SetText(text) })
+		}, func() {
+			fyne.Do(func() { review.Hide() })
+		})
+	}()
+	return decision
+}
+
+// awaitReview waits for the answer to a correction review, showing the time left
+// with setCountdown and closing the dialog with hide if the review times out or
+// the transcription is canceled
+func (a *AppState) awaitReview(answer <-chan bool, setCountdown func(string), hide func()) reviewDecision {
+	timeout := a.config.ReviewTimeout
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		if timeout > 0 {
+			remaining := time.Until(deadline).Round(time.Second)
+			setCountdown(fmt.Sprintf("Accepting automatically in %v", remaining))
+			if remaining <= 0 {
+				Infof("Correction review timed out, accepting the correction")
+				hide()
+				return reviewAccepted
+			}
+		}
+
+		select {
+		case accept := <-answer:
+			if accept {
+				return reviewAccepted
+			}
+			return reviewRejected
+		case <-a.ctx.Done():
+			hide()
+			return reviewCanceled
+		case <-ticker.C:
+			if a.processingCanceled() {
+				hide()
+				return reviewCanceled
+			}
+		}
+	}
+}
+
+// highlightedText returns wrapped text with the given phrases shown in bold in
+// the primary colour. Phrases are looked up in order, each after the previous
+// one, since the model lists its changes as they appear in the text.
+func highlightedText(text string, phrases []string) *widget.RichText {
+	runes := []rune(text)
+	plain := func(from, to int) widget.RichTextSegment {
+		return &widget.TextSegment{Style: widget.RichTextStyleInline, Text: string(runes[from:to])}
+	}
+
+	var segments []widget.RichTextSegment
+	last := 0
+	for _, m := range highlightRanges(text, phrases) {
+		if m.start > last {
+			segments = append(segments, plain(last, m.start))
+		}
+		segments = append(segments, &widget.TextSegment{
+			Style: widget.RichTextStyle{
+				ColorName: theme.ColorNamePrimary,
+				Inline:    true,
+				TextStyle: fyne.TextStyle{Bold: true},
+			},
+			Text: string(runes[m.start:m.end]),
+		})
+		last = m.end
+	}
+	if last < len(runes) || len(segments) == 0 {
+		segments = append(segments, plain(last, len(runes)))
+	}
+
+	rich := widget.NewRichText(segments...)
+	rich.Wrapping = fyne.TextWrapWord
+	return rich
+}
+
+// highlightRanges finds each phrase in text, as rune offsets in order. A phrase
+// is taken from the first occurrence after the previous one; phrases that are
+// empty or not found there are skipped.
+func highlightRanges(text string, phrases []string) []findMatch {
+	var ranges []findMatch
+	last := 0
+	for _, phrase := range phrases {
+		for _, m := range findMatches(text, strings.TrimSpace(phrase), true, false) {
+			if m.start >= last {
+				ranges = append(ranges, m)
+				last = m.end
+				break
+			}
+		}
+	}
+	return ranges
+}
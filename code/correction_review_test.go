@@ -0,0 +1,42 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHighlightRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		phrases []string
+		want    []findMatch
+	}{
+		{"no changes", "hello world", nil, nil},
+		{"in order", "Their going to the shop, their", []string{"Their", "their"}, []findMatch{{0, 5}, {25, 30}}},
+		{"cyrillic", "привет, мир", []string{"мир"}, []findMatch{{8, 11}}},
+		{"not found", "hello world", []string{"goodbye", "world"}, []findMatch{{6, 11}}},
+		{"empty phrase", "hello world", []string{" ", "hello"}, []findMatch{{0, 5}}},
+		{"out of order", "one two", []string{"two", "one"}, []findMatch{{4, 7}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightRanges(tt.text, tt.phrases); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("highlightRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Correction backends for MICAPP_CORRECTOR
+const (
+	CorrectorOpenAI = "openai" // OpenAI's chat API (or OPENAI_BASE_URL)
+	CorrectorLocal  = "local"  // Local OpenAI-compatible chat server such as Ollama
+)
+
+// Defaults for the local correction backend, matching a stock Ollama install
+const (
+	defaultLocalLLMURL   = "http://localhost:11434/v1"
+	defaultLocalLLMModel = "llama3.2"
+)
+
+// localCorrectionTimeout bounds a local correction; models running on the CPU
+// take far longer than the API
+const localCorrectionTimeout = 2 * time.Minute
+
+// errCorrectorUnreachable is returned when the local correction backend cannot be
+// contacted; the raw transcription is inserted instead
+var errCorrectorUnreachable = errors.New("correction backend unreachable")
+
+// Corrector polishes transcriptions with an LLM. AppState holds the backend
+// selected by MICAPP_CORRECTOR; all backends answer with the CorrectionJSON contract.
+type Corrector interface {
+	CorrectTextDetailed(ctx context.Context, transcribedText string) (*CorrectionJSON, error)
+	CorrectTextDetailedWithContext(ctx context.Context, transcribedText string, textContext string) (*CorrectionJSON, error)
+	SetInstructions(instructions string)
+}
+
+// LocalCorrector corrects text with a local OpenAI-compatible chat server
+// (e.g. Ollama at http://localhost:11434/v1). Failing to reach it is reported
+// as errCorrectorUnreachable so the raw transcription is used.
+type LocalCorrector struct {
+	*LLMClient
+}
+
+// newCorrector creates the correction backend selected in the config
+func newCorrector(config *Config) (Corrector, error) {
+	if config.Corrector == CorrectorLocal {
+		corrector, err := NewLocalCorrector(config.LocalLLMURL, config.LocalLLMModel)
+		if err != nil {
+			return nil, err
+		}
+		Infof("Using local correction backend at %s (model %s)", corrector.baseURL, corrector.Model)
+		return corrector, nil
+	}
+
+	client, err := NewLLMClient(config.OpenAIBaseURL, config.CorrectionModel)
+	if err != nil {
+		return nil, err
+	}
+	Infof("Using correction model %s", client.Model)
+	return client, nil
+}
+
+// NewLocalCorrector creates a corrector for the chat server at baseURL.
+// OPENAI_API_KEY is sent if set but not required.
+func NewLocalCorrector(baseURL string, model string) (*LocalCorrector, error) {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = defaultLocalLLMURL
+	}
+	baseURL, err := resolveBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if model == "" {
+		model = defaultLocalLLMModel
+	}
+
+	c := &LLMClient{
+		apiKey:  os.Getenv("OPENAI_API_KEY"),
+		baseURL: baseURL,
+		Model:   model,
+		client: &http.Client{
+			Timeout: localCorrectionTimeout,
+		},
+	}
+	c.SetInstructions(defaultCorrectionInstructions)
+	return &LocalCorrector{c}, nil
+}
+
+// CorrectTextDetailed corrects text with the local model
+func (l *LocalCorrector) CorrectTextDetailed(ctx context.Context, transcribedText string) (*CorrectionJSON, error) {
+	correction, err := l.LLMClient.CorrectTextDetailed(ctx, transcribedText)
+	return correction, l.checkReachable(err)
+}
+
+// CorrectTextDetailedWithContext corrects text with the local model, using the
+// preceding text as context
+func (l *LocalCorrector) CorrectTextDetailedWithContext(ctx context.Context, transcribedText string, textContext string) (*CorrectionJSON, error) {
+	correction, err := l.LLMClient.CorrectTextDetailedWithContext(ctx, transcribedText, textContext)
+	return correction, l.checkReachable(err)
+}
+
+// checkReachable turns connection failures into errCorrectorUnreachable and logs
+// them, so a stopped Ollama is easy to spot among other correction errors
+func (l *LocalCorrector) checkReachable(err error) error {
+	var opErr *net.OpError
+	if err == nil || !errors.As(err, &opErr) {
+		return err
+	}
+	Warnf("Local correction backend at %s is unreachable, passing the transcription through: %v", l.baseURL, err)
+	return fmt.Errorf("%w: %v", errCorrectorUnreachable, err)
+}
@@ -0,0 +1,55 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"image"
+	"math"
+)
+
+// captureScaleOverride is the physical-to-logical pixel ratio used when cropping
+// captured screens, or 0 to detect it from each capture (see Config.CaptureScale)
+var captureScaleOverride float64
+
+// bitmapScale returns how many bitmap pixels a captured image has per logical pixel
+// of the area it shows. Selections come from the mouse hook in logical pixels, while
+// on scaled (HiDPI) displays the captured bitmap is in physical pixels.
+func bitmapScale(img image.Image, logical image.Rectangle) float64 {
+	if captureScaleOverride > 0 {
+		return captureScaleOverride
+	}
+	if logical.Dx() <= 0 {
+		return 1
+	}
+	scale := float64(img.Bounds().Dx()) / float64(logical.Dx())
+	if scale < 0.5 || scale > 4 {
+		Infof("Ignoring implausible display scale %.2f (bitmap %v for %v)", scale, img.Bounds(), logical)
+		return 1
+	}
+	return scale
+}
+
+// scaleRect multiplies r by scale, rounding outwards so the scaled rectangle
+// still covers everything the original did
+func scaleRect(r image.Rectangle, scale float64) image.Rectangle {
+	if scale == 1 {
+		return r
+	}
+	return image.Rect(
+		int(math.Floor(float64(r.Min.X)*scale)),
+		int(math.Floor(float64(r.Min.Y)*scale)),
+		int(math.Ceil(float64(r.Max.X)*scale)),
+		int(math.Ceil(float64(r.Max.Y)*scale)),
+	)
+}
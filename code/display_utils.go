@@ -0,0 +1,135 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"image"
+
+	"fyne.io/fyne/v2/widget"
+	"github.com/go-vgo/robotgo"
+)
+
+// allDisplays is the CaptureDisplay value that leaves captures unconstrained
+const allDisplays = -1
+
+// displayBounds returns the rectangle of display index in global screen coordinates
+func displayBounds(index int) (image.Rectangle, error) {
+	count := robotgo.DisplaysNum()
+	if index < 0 || index >= count {
+		return image.Rectangle{}, fmt.Errorf("display %d not available (%d connected)", index, count)
+	}
+
+	x, y, w, h := robotgo.GetDisplayBounds(index)
+	if w <= 0 || h <= 0 {
+		return image.Rectangle{}, fmt.Errorf("display %d has empty bounds", index)
+	}
+	return image.Rect(x, y, x+w, y+h), nil
+}
+
+// virtualDesktopBounds returns the union of all display rectangles in global
+// screen coordinates, or an empty rectangle if no display could be queried
+func virtualDesktopBounds() image.Rectangle {
+	var desktop image.Rectangle
+	for i := 0; i < robotgo.DisplaysNum(); i++ {
+		if bounds, err := displayBounds(i); err == nil {
+			desktop = desktop.Union(bounds)
+		}
+	}
+	return desktop
+}
+
+// displayContaining returns the index of the display that region lies entirely on
+func displayContaining(region image.Rectangle) (int, bool) {
+	for i := 0; i < robotgo.DisplaysNum(); i++ {
+		if bounds, err := displayBounds(i); err == nil && region.In(bounds) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// clampToDisplay limits region to the display rectangle. Both are in global
+// screen coordinates, so displays with a non-zero origin work as expected.
+// The result is empty when the region lies entirely outside the display.
+func clampToDisplay(region image.Rectangle, display image.Rectangle) image.Rectangle {
+	return region.Canon().Intersect(display)
+}
+
+// captureDisplayRegion captures a region given in global coordinates from a single display.
+// Only that display is grabbed, and the region is offset into the display's own image.
+func captureDisplayRegion(index int, x, y, width, height int) ([]byte, error) {
+	display, err := displayBounds(index)
+	if err != nil {
+		return nil, err
+	}
+
+	region := clampToDisplay(image.Rect(x, y, x+width, y+height), display)
+	if region.Empty() {
+		return nil, fmt.Errorf("selection is outside display %d", index)
+	}
+	Infof("captureDisplayRegion: display %d at %v, region %v", index, display, region)
+
+	if data, ok := captureWayland(region.Min.X, region.Min.Y, region.Dx(), region.Dy()); ok {
+		return data, nil
+	}
+
+	screenBitmap := robotgo.CaptureScreen(display.Min.X, display.Min.Y, display.Dx(), display.Dy())
+	if screenBitmap == nil {
+		return nil, fmt.Errorf("failed to capture display %d", index)
+	}
+	defer robotgo.FreeBitmap(screenBitmap)
+
+	displayImg := robotgo.ToImage(screenBitmap)
+	if displayImg == nil {
+		return nil, fmt.Errorf("failed to convert display bitmap to image")
+	}
+
+	// On scaled displays the bitmap has more pixels than the logical display area
+	local := region.Sub(display.Min)
+	if scale := bitmapScale(displayImg, display); scale != 1 {
+		local = scaleRect(local, scale)
+		Infof("captureDisplayRegion: display scale %.2f, cropping %v", scale, local)
+	}
+	return cropImageToPNG(displayImg, local.Min.X, local.Min.Y, local.Dx(), local.Dy())
+}
+
+// captureRegion captures region from the targeted display, or the whole screen if none is set
+func (a *AppState) captureRegion(region image.Rectangle) ([]byte, error) {
+	if display := a.config.CaptureDisplay; display != allDisplays {
+		return captureDisplayRegion(display, region.Min.X, region.Min.Y, region.Dx(), region.Dy())
+	}
+	return captureScreenRegion(region.Min.X, region.Min.Y, region.Dx(), region.Dy())
+}
+
+// newDisplaySelect builds a selector for the display that captures are constrained to
+func (a *AppState) newDisplaySelect() *widget.Select {
+	options := []string{"All displays"}
+	for i := 0; i < robotgo.DisplaysNum(); i++ {
+		x, y, w, h := robotgo.GetDisplayBounds(i)
+		options = append(options, fmt.Sprintf("Display %d (%dx%d at %d,%d)", i, w, h, x, y))
+	}
+
+	displaySelect := widget.NewSelect(options, nil)
+	if a.config.CaptureDisplay >= 0 && a.config.CaptureDisplay+1 < len(options) {
+		displaySelect.SetSelectedIndex(a.config.CaptureDisplay + 1)
+	} else {
+		displaySelect.SetSelectedIndex(0)
+	}
+	displaySelect.OnChanged = func(string) {
+		a.config.CaptureDisplay = displaySelect.SelectedIndex() - 1
+		Infof("Capture display set to %d", a.config.CaptureDisplay)
+	}
+	return displaySelect
+}
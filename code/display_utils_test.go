@@ -0,0 +1,58 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestClampToDisplay(t *testing.T) {
+	// A 1280x1024 monitor to the right of a 1920x1080 primary, and one to its left
+	right := image.Rect(1920, 0, 3200, 1024)
+	left := image.Rect(-1280, -200, 0, 824)
+
+	tests := []struct {
+		name    string
+		region  image.Rectangle
+		display image.Rectangle
+		want    image.Rectangle
+	}{
+		{"inside", image.Rect(2000, 100, 2400, 500), right, image.Rect(2000, 100, 2400, 500)},
+		{"crosses from primary", image.Rect(1800, 100, 2100, 300), right, image.Rect(1920, 100, 2100, 300)},
+		{"past bottom right", image.Rect(3000, 900, 3500, 1200), right, image.Rect(3000, 900, 3200, 1024)},
+		{"reversed drag", image.Rect(2400, 500, 2000, 100), right, image.Rect(2000, 100, 2400, 500)},
+		{"negative origin", image.Rect(-1500, -300, -1000, 0), left, image.Rect(-1280, -200, -1000, 0)},
+		{"negative origin crossing into primary", image.Rect(-100, 700, 200, 900), left, image.Rect(-100, 700, 0, 824)},
+		{"outside", image.Rect(100, 100, 500, 500), right, image.Rectangle{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := clampToDisplay(tt.region, tt.display)
+			if tt.want.Empty() {
+				if !got.Empty() {
+					t.Errorf("clampToDisplay(%v, %v) = %v, want empty", tt.region, tt.display, got)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("clampToDisplay(%v, %v) = %v, want %v", tt.region, tt.display, got, tt.want)
+			}
+			if !got.In(tt.display) {
+				t.Errorf("clampToDisplay(%v, %v) = %v is not on the display", tt.region, tt.display, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fyne.io/fyne/v2"
+)
+
+// capWindows keeps at most max windows (oldest first) and returns the kept
+// windows and the oldest ones that have to be closed. max is at least 1.
+func capWindows(windows []fyne.Window, max int) (kept []fyne.Window, evicted []fyne.Window) {
+	if max < 1 {
+		max = 1
+	}
+	if len(windows) <= max {
+		return windows, nil
+	}
+	excess := len(windows) - max
+	evicted = append([]fyne.Window(nil), windows[:excess]...)
+	kept = append([]fyne.Window(nil), windows[excess:]...)
+	return kept, evicted
+}
+
+// trackEditorWindow registers a newly opened editor window and returns the oldest
+// editor windows that must be closed to stay within the configured cap.
+// The decision is made under the editor mutex so rapid captures cannot overshoot.
+func (a *AppState) trackEditorWindow(window fyne.Window) []fyne.Window {
+	a.editorMutex.Lock()
+	defer a.editorMutex.Unlock()
+
+	var evicted []fyne.Window
+	a.editorWindows, evicted = capWindows(append(a.editorWindows, window), a.config.MaxEditorWindows)
+	a.imageEditorWindow = window
+	return evicted
+}
+
+// untrackEditorWindow forgets a closed editor window
+func (a *AppState) untrackEditorWindow(window fyne.Window) {
+	a.editorMutex.Lock()
+	defer a.editorMutex.Unlock()
+
+	for i, w := range a.editorWindows {
+		if w == window {
+			a.editorWindows = append(a.editorWindows[:i], a.editorWindows[i+1:]...)
+			break
+		}
+	}
+	if a.imageEditorWindow == window {
+		a.imageEditorWindow = nil
+		if n := len(a.editorWindows); n > 0 {
+			a.imageEditorWindow = a.editorWindows[n-1]
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Exporter converts the editor text into a document format
+type Exporter interface {
+	Name() string      // Menu label, e.g. "Markdown"
+	Extension() string // File extension including the dot
+	Export(text string) ([]byte, error)
+}
+
+// exporters lists the formats offered by the "Export..." menu
+var exporters = []Exporter{markdownExporter{}, pdfExporter{}, docxExporter{}}
+
+// exportParagraphs splits text into paragraphs at blank lines. Each paragraph
+// keeps its single line breaks as separate lines.
+func exportParagraphs(text string) [][]string {
+	var paragraphs [][]string
+	var current []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+	return paragraphs
+}
+
+// markdownExporter writes paragraphs separated by blank lines, with line breaks
+// inside a paragraph kept as hard breaks
+type markdownExporter struct{}
+
+func (markdownExporter) Name() string      { return "Markdown" }
+func (markdownExporter) Extension() string { return ".md" }
+
+func (markdownExporter) Export(text string) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, paragraph := range exportParagraphs(text) {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		for j, line := range paragraph {
+			buf.WriteString(escapeMarkdownLine(line))
+			if j < len(paragraph)-1 {
+				buf.WriteString("  ")
+			}
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// markdownBlockMarker matches dictated text that Markdown would read as a heading,
+// list item or quote
+var markdownBlockMarker = regexp.MustCompile(`^(#{1,6}|[-+*>]|\d+[.)])(\s|$)`)
+
+// escapeMarkdownLine backslash-escapes a leading block marker so the line stays plain text
+func escapeMarkdownLine(line string) string {
+	match := markdownBlockMarker.FindStringSubmatchIndex(line)
+	if match == nil {
+		return line
+	}
+	markerEnd := match[3]
+	return line[:markerEnd-1] + `\` + line[markerEnd-1:]
+}
+
+// docxExporter writes a minimal WordprocessingML package with one Word paragraph
+// per paragraph of text
+type docxExporter struct{}
+
+func (docxExporter) Name() string      { return "Word (DOCX)" }
+func (docxExporter) Extension() string { return ".docx" }
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`</Types>`
+
+const docxRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`</Relationships>`
+
+func (docxExporter) Export(text string) ([]byte, error) {
+	var document bytes.Buffer
+	document.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	document.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	for _, paragraph := range exportParagraphs(text) {
+		document.WriteString(`<w:p><w:pPr><w:spacing w:after="200"/></w:pPr><w:r>`)
+		for i, line := range paragraph {
+			if i > 0 {
+				document.WriteString(`<w:br/>`)
+			}
+			document.WriteString(`<w:t xml:space="preserve">`)
+			if err := xml.EscapeText(&document, []byte(line)); err != nil {
+				return nil, err
+			}
+			document.WriteString(`</w:t>`)
+		}
+		document.WriteString(`</w:r></w:p>`)
+	}
+	document.WriteString(`<w:sectPr/></w:body></w:document>`)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, part := range []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(docxContentTypes)},
+		{"_rels/.rels", []byte(docxRelationships)},
+		{"word/document.xml", document.Bytes()},
+	} {
+		w, err := archive.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %v", part.name, err)
+		}
+		if _, err := w.Write(part.data); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %v", part.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish DOCX archive: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// newExportButton returns the "Export..." button, which pops up the list of formats
+func (a *AppState) newExportButton() *widget.Button {
+	var button *widget.Button
+	button = widget.NewButton("Export...", func() {
+		items := make([]*fyne.MenuItem, 0, len(exporters))
+		for _, exporter := range exporters {
+			items = append(items, fyne.NewMenuItem(exporter.Name(), func() {
+				a.showExportDialog(exporter)
+			}))
+		}
+		driver := fyne.CurrentApp().Driver()
+		position := driver.AbsolutePositionForObject(button).Add(fyne.NewPos(0, button.Size().Height))
+		widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), driver.CanvasForObject(button), position)
+	})
+	return button
+}
+
+// showExportDialog asks where to save the editor text in the exporter's format,
+// starting in the folder last saved to
+func (a *AppState) showExportDialog(exporter Exporter) {
+	if a.mainWindow == nil {
+		return
+	}
+	prefs := fyne.CurrentApp().Preferences()
+	text := a.correctedText.Text
+
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			Errorf("Export dialog failed: %v", err)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		prefs.SetString(screenshotDirPrefKey, filepath.Dir(writer.URI().Path()))
+		go a.writeExport(writer, exporter, text)
+	}, a.mainWindow)
+
+	save.SetFileName(fmt.Sprintf("transcript_%s%s", time.Now().Format("20060102_150405"), exporter.Extension()))
+	save.SetFilter(storage.NewExtensionFileFilter([]string{exporter.Extension()}))
+	setSaveLocation(save, prefs)
+	save.Resize(a.mainWindow.Canvas().Size())
+	save.Show()
+}
+
+// writeExport renders text with exporter and writes it to writer, reporting the
+// outcome in the status bar. It runs in its own goroutine.
+func (a *AppState) writeExport(writer fyne.URIWriteCloser, exporter Exporter, text string) {
+	defer writer.Close()
+
+	data, err := exporter.Export(text)
+	if err == nil {
+		_, err = writer.Write(data)
+	}
+	if err != nil {
+		Errorf("Failed to export %s to %s: %v", exporter.Name(), writer.URI().Path(), err)
+		postStatusText(a.statusLabel, fmt.Sprintf("Export failed: %v", err))
+		return
+	}
+	Infof("Exported %s to %s (%d bytes)", exporter.Name(), writer.URI().Path(), len(data))
+	postStatusText(a.statusLabel, fmt.Sprintf("Exported as %s", writer.URI().Name()))
+}
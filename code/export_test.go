@@ -0,0 +1,162 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestExportParagraphs(t *testing.T) {
+	got := exportParagraphs("  First line \r\nsecond line\n\n\n \t\nНовый абзац\n")
+	want := [][]string{{"First line", "second line"}, {"Новый абзац"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("exportParagraphs = %q, want %q", got, want)
+	}
+	if got := exportParagraphs(" \n\n "); got != nil {
+		t.Errorf("exportParagraphs of blank text = %q, want none", got)
+	}
+}
+
+func TestMarkdownExport(t *testing.T) {
+	text := "First line\nsecond line\n\n\n# not a heading\n- not an item\n1. not a list\n\n#hashtag, 3.5 and Привет"
+	want := "First line  \nsecond line\n" +
+		"\n" +
+		`\# not a heading  ` + "\n" + `\- not an item  ` + "\n" + `1\. not a list` + "\n" +
+		"\n" +
+		"#hashtag, 3.5 and Привет\n"
+
+	data, err := markdownExporter{}.Export(text)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("Markdown export =\n%q\nwant\n%q", data, want)
+	}
+}
+
+func TestEscapeMarkdownLine(t *testing.T) {
+	tests := map[string]string{
+		"plain text":    "plain text",
+		"### heading":   `##\# heading`,
+		"> quote":       `\> quote`,
+		"+ plus":        `\+ plus`,
+		"2) second":     `2\) second`,
+		"-":             `\-`,
+		"-5 degrees":    "-5 degrees",
+		"1.5 liters":    "1.5 liters",
+		"####### seven": "####### seven",
+	}
+	for line, want := range tests {
+		if got := escapeMarkdownLine(line); got != want {
+			t.Errorf("escapeMarkdownLine(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestDOCXExport(t *testing.T) {
+	data, err := docxExporter{}.Export("Tom & Jerry <3\nline two\n\nВторой абзац")
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("DOCX is not a zip archive: %v", err)
+	}
+	parts := make(map[string][]byte)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		parts[file.Name], err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/document.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("DOCX is missing %s", name)
+		}
+		if err := checkWellFormedXML(parts[name]); err != nil {
+			t.Errorf("%s is not well-formed: %v", name, err)
+		}
+	}
+
+	// Collect the text of each Word paragraph, with <w:br/> as a newline
+	var paragraphs []string
+	decoder := xml.NewDecoder(bytes.NewReader(parts["word/document.xml"]))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("document.xml: %v", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "p":
+				paragraphs = append(paragraphs, "")
+			case "br":
+				paragraphs[len(paragraphs)-1] += "\n"
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			inText = inText && token.Name.Local != "t"
+		case xml.CharData:
+			if inText {
+				paragraphs[len(paragraphs)-1] += string(token)
+			}
+		}
+	}
+	want := []string{"Tom & Jerry <3\nline two", "Второй абзац"}
+	if !reflect.DeepEqual(paragraphs, want) {
+		t.Errorf("DOCX paragraphs = %q, want %q", paragraphs, want)
+	}
+}
+
+// checkWellFormedXML reads every token of data
+func checkWellFormedXML(data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func TestExportersHaveDistinctExtensions(t *testing.T) {
+	seen := make(map[string]string)
+	for _, exporter := range exporters {
+		ext := exporter.Extension()
+		if len(ext) < 2 || ext[0] != '.' {
+			t.Errorf("%s: extension %q should start with a dot", exporter.Name(), ext)
+		}
+		if other, ok := seen[ext]; ok {
+			t.Errorf("%s and %s both use %q", other, exporter.Name(), ext)
+		}
+		seen[ext] = exporter.Name()
+	}
+}
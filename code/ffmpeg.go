@@ -0,0 +1,43 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+)
+
+// errFFmpegMissing is returned by conversions when ffmpeg is not installed
+var errFFmpegMissing = errors.New("ffmpeg not found in PATH")
+
+var (
+	ffmpegOnce  sync.Once
+	ffmpegFound bool
+)
+
+// ffmpegAvailable reports whether the ffmpeg binary can be run. The lookup is done
+// once, so machines without ffmpeg don't try (and fail) to spawn it per recording.
+func ffmpegAvailable() bool {
+	ffmpegOnce.Do(func() {
+		path, err := exec.LookPath("ffmpeg")
+		ffmpegFound = err == nil
+		if ffmpegFound {
+			Infof("Using ffmpeg at %s for audio encoding", path)
+		} else {
+			Warnf("ffmpeg not found; using the built-in MP3 encoder, Opus uploads and clipboard audio are unavailable")
+		}
+	})
+	return ffmpegFound
+}
@@ -0,0 +1,334 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// findMatch is one occurrence of the search text, as rune offsets into the editor text
+type findMatch struct {
+	start, end int
+}
+
+// findMatches returns the non-overlapping occurrences of query in text, in order.
+// Without matchCase, letters are compared by Unicode case folding, so "Привет"
+// finds "ПРИВЕТ" and "σ" finds "Σ" and "ς". With wholeWord, a match must not
+// have a letter or digit directly before or after it.
+func findMatches(text string, query string, matchCase bool, wholeWord bool) []findMatch {
+	if query == "" {
+		return nil
+	}
+	haystack, needle := []rune(text), []rune(query)
+	if !matchCase {
+		foldRunes(haystack)
+		foldRunes(needle)
+	}
+
+	var matches []findMatch
+	for i := 0; i+len(needle) <= len(haystack); {
+		end := i + len(needle)
+		if runesEqual(haystack[i:end], needle) && (!wholeWord || isWholeWord(haystack, i, end)) {
+			matches = append(matches, findMatch{i, end})
+			i = end
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// foldRunes replaces every rune with the smallest rune of its case-folding orbit
+// (e.g. "K", "k" and the Kelvin sign all become "K"), keeping offsets unchanged
+func foldRunes(runes []rune) {
+	for i, r := range runes {
+		folded := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < folded {
+				folded = f
+			}
+		}
+		runes[i] = folded
+	}
+}
+
+// runesEqual reports whether a and b hold the same runes
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isWholeWord reports whether runes[start:end] is not part of a longer word
+func isWholeWord(runes []rune, start, end int) bool {
+	return (start == 0 || !isWordRune(runes[start-1])) && (end == len(runes) || !isWordRune(runes[end]))
+}
+
+// replaceMatches returns text with every match replaced by replacement
+func replaceMatches(text string, matches []findMatch, replacement string) string {
+	runes := []rune(text)
+	result := make([]rune, 0, len(runes))
+	last := 0
+	for _, m := range matches {
+		result = append(result, runes[last:m.start]...)
+		result = append(result, []rune(replacement)...)
+		last = m.end
+	}
+	return string(append(result, runes[last:]...))
+}
+
+// moveEntryCursor puts a multi-line entry's cursor at a rune offset of its text.
+// Fyne 2.7 has no call for setting the selection, so the match is shown by the
+// cursor only. CursorRow counts the wrapped rows on screen, whose start offsets
+// CursorTextOffset reports, so the rows are walked until the one holding offset.
+func moveEntryCursor(entry *widget.Entry, offset int) {
+	row := 0
+	for next := 1; ; next++ {
+		entry.CursorRow, entry.CursorColumn = next, 0
+		start := entry.CursorTextOffset()
+		if start == 0 || start > offset {
+			break // Rows past the last one report offset 0
+		}
+		row = next
+	}
+	entry.CursorRow, entry.CursorColumn = row, 0
+	entry.CursorColumn = offset - entry.CursorTextOffset()
+	entry.Refresh()
+}
+
+// editorEntry is the main text editor. widget.Entry keeps every shortcut typed
+// while it has focus, so the window's own shortcuts are registered here too.
+type editorEntry struct {
+	widget.Entry
+	shortcuts map[string]func()
+}
+
+// newEditorEntry creates the multi-line editor
+func newEditorEntry() *editorEntry {
+	e := &editorEntry{shortcuts: make(map[string]func())}
+	e.MultiLine = true
+	e.Wrapping = fyne.TextWrapWord
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// addShortcut runs fn when shortcut is typed in the editor
+func (e *editorEntry) addShortcut(shortcut fyne.Shortcut, fn func()) {
+	e.shortcuts[shortcut.ShortcutName()] = fn
+}
+
+// TypedShortcut runs the editor's own shortcuts and leaves the rest to widget.Entry
+func (e *editorEntry) TypedShortcut(shortcut fyne.Shortcut) {
+	if fn, ok := e.shortcuts[shortcut.ShortcutName()]; ok {
+		fn()
+		return
+	}
+	e.Entry.TypedShortcut(shortcut)
+}
+
+// findBar is the find and replace bar above the editor, toggled with Ctrl+F.
+// The current match is shown by moving the editor's cursor to it.
+type findBar struct {
+	app         *AppState
+	content     *fyne.Container
+	query       *widget.Entry
+	replacement *widget.Entry
+	matchCase   *widget.Check
+	wholeWord   *widget.Check
+	count       *widget.Label
+	selected    int // Rune offset of the current match, -1 if none
+}
+
+// newFindBar creates the find and replace bar, initially hidden
+func (a *AppState) newFindBar() *findBar {
+	b := &findBar{app: a, selected: -1}
+
+	b.query = widget.NewEntry()
+	b.query.SetPlaceHolder("Find")
+	b.query.OnChanged = func(string) {
+		b.selected = -1
+		b.updateCount()
+	}
+	b.query.OnSubmitted = func(string) { b.next() }
+
+	b.replacement = widget.NewEntry()
+	b.replacement.SetPlaceHolder("Replace with")
+	b.replacement.OnSubmitted = func(string) { b.replace() }
+
+	b.matchCase = widget.NewCheck("Match case", func(bool) { b.updateCount() })
+	b.wholeWord = widget.NewCheck("Whole word", func(bool) { b.updateCount() })
+	b.count = widget.NewLabel("")
+
+	findRow := container.NewBorder(nil, nil, nil,
+		container.NewHBox(
+			b.count,
+			widget.NewButtonWithIcon("", theme.MoveUpIcon(), b.previous),
+			widget.NewButtonWithIcon("", theme.MoveDownIcon(), b.next),
+			widget.NewButtonWithIcon("", theme.CancelIcon(), b.hide),
+		),
+		b.query,
+	)
+	replaceRow := container.NewBorder(nil, nil, nil,
+		container.NewHBox(
+			b.matchCase,
+			b.wholeWord,
+			widget.NewButton("Replace", b.replace),
+			widget.NewButton("Replace all", b.replaceAll),
+		),
+		b.replacement,
+	)
+	b.content = container.NewVBox(findRow, replaceRow)
+	b.content.Hide()
+	return b
+}
+
+// toggle shows the bar, or hides it if it is already shown
+func (b *findBar) toggle() {
+	if b.content.Visible() {
+		b.hide()
+	} else {
+		b.show()
+	}
+}
+
+// show opens the bar, searching for the editor's selected text if there is any
+func (b *findBar) show() {
+	if selected := b.app.correctedText.SelectedText(); selected != "" {
+		b.query.SetText(selected)
+	}
+	b.content.Show()
+	b.updateCount()
+	if b.app.mainWindow != nil {
+		b.app.mainWindow.Canvas().Focus(b.query)
+	}
+}
+
+// hide closes the bar and returns to the editor
+func (b *findBar) hide() {
+	b.content.Hide()
+	b.selected = -1
+	if b.app.mainWindow != nil {
+		b.app.mainWindow.Canvas().Focus(b.app.correctedText)
+	}
+}
+
+// matches returns the current matches in the editor text
+func (b *findBar) matches() []findMatch {
+	return findMatches(b.app.correctedText.Text, b.query.Text, b.matchCase.Checked, b.wholeWord.Checked)
+}
+
+// next selects the first match after the selected one, wrapping around at the end
+func (b *findBar) next() {
+	b.selectFrom(b.selected + 1)
+}
+
+// selectFrom selects the first match starting at or after offset, wrapping around
+func (b *findBar) selectFrom(offset int) {
+	matches := b.matches()
+	if len(matches) == 0 {
+		b.selected = -1
+		b.updateCount()
+		return
+	}
+	for i, m := range matches {
+		if m.start >= offset {
+			b.selectMatch(matches, i)
+			return
+		}
+	}
+	b.selectMatch(matches, 0)
+}
+
+// previous selects the last match before the selected one, wrapping around at the start
+func (b *findBar) previous() {
+	matches := b.matches()
+	if len(matches) == 0 {
+		b.selected = -1
+		b.updateCount()
+		return
+	}
+	for i := len(matches) - 1; i >= 0; i-- {
+		if b.selected < 0 || matches[i].start < b.selected {
+			b.selectMatch(matches, i)
+			return
+		}
+	}
+	b.selectMatch(matches, len(matches)-1)
+}
+
+// selectMatch makes matches[i] the current match and moves the editor's cursor to it
+func (b *findBar) selectMatch(matches []findMatch, i int) {
+	b.selected = matches[i].start
+	moveEntryCursor(b.app.correctedText, matches[i].start)
+	b.count.SetText(fmt.Sprintf("%d of %d", i+1, len(matches)))
+}
+
+// replace replaces the selected match and selects the next one. Without a
+// selected match it only moves to the next one, so nothing is replaced unseen.
+func (b *findBar) replace() {
+	for _, m := range b.matches() {
+		if m.start != b.selected {
+			continue
+		}
+		text := replaceMatches(b.app.correctedText.Text, []findMatch{m}, b.replacement.Text)
+		b.app.correctedText.SetText(text)
+		b.selectFrom(m.start + utf8.RuneCountInString(b.replacement.Text))
+		return
+	}
+	b.next()
+}
+
+// replaceAll replaces every match at once
+func (b *findBar) replaceAll() {
+	matches := b.matches()
+	if len(matches) == 0 {
+		setStatusText(b.app.statusLabel, "Nothing to replace")
+		return
+	}
+	b.app.correctedText.SetText(replaceMatches(b.app.correctedText.Text, matches, b.replacement.Text))
+	b.selected = -1
+	b.updateCount()
+	if len(matches) == 1 {
+		setStatusText(b.app.statusLabel, "Replaced 1 occurrence")
+	} else {
+		setStatusText(b.app.statusLabel, fmt.Sprintf("Replaced %d occurrences", len(matches)))
+	}
+}
+
+// updateCount shows how many matches the editor text has
+func (b *findBar) updateCount() {
+	switch n := len(b.matches()); {
+	case b.query.Text == "":
+		b.count.SetText("")
+	case n == 0:
+		b.count.SetText("No matches")
+	case n == 1:
+		b.count.SetText("1 match")
+	default:
+		b.count.SetText(fmt.Sprintf("%d matches", n))
+	}
+}
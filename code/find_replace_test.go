@@ -0,0 +1,85 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestFindMatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		query     string
+		matchCase bool
+		wholeWord bool
+		want      []findMatch
+	}{
+		{"empty query", "text", "", false, false, nil},
+		{"ignore case", "Cat cat CAT", "cat", false, false, []findMatch{{0, 3}, {4, 7}, {8, 11}}},
+		{"match case", "Cat cat CAT", "cat", true, false, []findMatch{{4, 7}}},
+		{"cyrillic", "Привет, ПРИВЕТ", "привет", false, false, []findMatch{{0, 6}, {8, 14}}},
+		{"final sigma", "ΟΔΟΣ οδος", "οδοσ", false, false, []findMatch{{0, 4}, {5, 9}}},
+		{"rune offsets", "ёж ёж", "ж", false, false, []findMatch{{1, 2}, {4, 5}}},
+		{"no overlap", "aaaa", "aa", false, false, []findMatch{{0, 2}, {2, 4}}},
+		{"whole word", "cat catalog bobcat cat", "cat", false, true, []findMatch{{0, 3}, {19, 22}}},
+		{"whole word cyrillic", "кот котёнок кот", "кот", false, true, []findMatch{{0, 3}, {12, 15}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findMatches(tt.text, tt.query, tt.matchCase, tt.wholeWord); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findMatches(%q, %q) = %v, want %v", tt.text, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceMatches(t *testing.T) {
+	text := "Привет, мир! привет"
+	matches := findMatches(text, "привет", false, false)
+	if got, want := replaceMatches(text, matches, "Здравствуй"), "Здравствуй, мир! Здравствуй"; got != want {
+		t.Errorf("replaceMatches = %q, want %q", got, want)
+	}
+	if got := replaceMatches(text, nil, "x"); got != text {
+		t.Errorf("replaceMatches without matches = %q, want the text unchanged", got)
+	}
+}
+
+func TestMoveEntryCursor(t *testing.T) {
+	entry := widget.NewMultiLineEntry()
+	entry.SetText("first line\nсекунда\n\nlast")
+	tests := []struct {
+		offset   int
+		row, col int
+	}{
+		{0, 0, 0},
+		{6, 0, 6},
+		{11, 1, 0},
+		{14, 1, 3},
+		{19, 2, 0},
+		{20, 3, 0},
+		{24, 3, 4},
+	}
+	for _, tt := range tests {
+		moveEntryCursor(entry, tt.offset)
+		if entry.CursorRow != tt.row || entry.CursorColumn != tt.col {
+			t.Errorf("offset %d: cursor at %d:%d, want %d:%d", tt.offset, entry.CursorRow, entry.CursorColumn, tt.row, tt.col)
+		}
+		if got := entry.CursorTextOffset(); got != tt.offset {
+			t.Errorf("offset %d: CursorTextOffset() = %d", tt.offset, got)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// historyFile keeps every completed transcription, one JSON object per line.
+// Past the size limit it is renamed to historyFile + ".1", replacing the previous backup.
+const historyFile = "history.jsonl"
+
+// HistoryEntry is one completed transcription
+type HistoryEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Language  string    `json:"language,omitempty"`
+	Text      string    `json:"text"`
+	Recording string    `json:"recording,omitempty"` // Stored recording filename, empty if it wasn't saved
+}
+
+// TranscriptionHistory is an append-only log of transcriptions that outlives the
+// editor text. It is safe for concurrent use.
+type TranscriptionHistory struct {
+	mutex       sync.Mutex
+	path        string
+	maxSize     int64          // Rotate once the file would grow past this many bytes, 0 to never rotate
+	entries     []HistoryEntry // Backup and current file, oldest first
+	backupCount int            // Leading entries that came from the backup file
+}
+
+// NewTranscriptionHistory loads the history at path and its backup
+func NewTranscriptionHistory(path string, maxSize int64) *TranscriptionHistory {
+	h := &TranscriptionHistory{path: path, maxSize: maxSize}
+	h.entries = readHistoryFile(path + ".1")
+	h.backupCount = len(h.entries)
+	h.entries = append(h.entries, readHistoryFile(path)...)
+	Infof("Loaded %d transcription history entries from %s", len(h.entries), path)
+	return h
+}
+
+// readHistoryFile parses a JSONL history file. Lines that don't parse, such as
+// one cut short by a crash, are skipped.
+func readHistoryFile(path string) []HistoryEntry {
+	file, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			Errorf("Failed to open transcription history %s: %v", path, err)
+		}
+		return nil
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			Warnf("Skipping unreadable line %d of %s: %v", line, path, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		Errorf("Failed to read transcription history %s: %v", path, err)
+	}
+	return entries
+}
+
+// Append adds entry to the end of the history file, rotating it first if the
+// entry would take it past the size limit
+func (h *TranscriptionHistory) Append(entry HistoryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %v", err)
+	}
+	line = append(line, '\n')
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if info, err := os.Stat(h.path); err == nil && h.maxSize > 0 && info.Size() > 0 && info.Size()+int64(len(line)) > h.maxSize {
+		if err := os.Rename(h.path, h.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate %s: %v", h.path, err)
+		}
+		Infof("Rotated transcription history %s (%d bytes)", h.path, info.Size())
+		h.entries = slices.Delete(h.entries, 0, h.backupCount)
+		h.backupCount = len(h.entries)
+	}
+
+	// The whole line goes out in one write, so appends never interleave
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", h.path, err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %v", h.path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %v", h.path, err)
+	}
+
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+// Search returns the entries, newest first, whose text, language or recording
+// contains query (ignoring case) and whose language is language ("" for any)
+func (h *TranscriptionHistory) Search(query string, language string) []HistoryEntry {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	query = strings.TrimSpace(query)
+	var results []HistoryEntry
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		entry := h.entries[i]
+		if language != "" && entry.Language != language {
+			continue
+		}
+		if query != "" && len(findMatches(entry.Text+"\n"+entry.Language+"\n"+entry.Recording, query, false, false)) == 0 {
+			continue
+		}
+		results = append(results, entry)
+	}
+	return results
+}
+
+// Languages returns the distinct languages in the history, sorted
+func (h *TranscriptionHistory) Languages() []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var languages []string
+	for _, entry := range h.entries {
+		if entry.Language != "" && !slices.Contains(languages, entry.Language) {
+			languages = append(languages, entry.Language)
+		}
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// recordHistory adds a completed transcription to the history and refreshes the History tab
+func (a *AppState) recordHistory(job transcriptionJob, text string, language string) {
+	if a.history == nil {
+		return
+	}
+	entry := HistoryEntry{
+		Timestamp: time.Now(),
+		Language:  language,
+		Text:      text,
+		Recording: job.recordingFile,
+	}
+	if err := a.history.Append(entry); err != nil {
+		Errorf("Failed to save transcription history: %v", err)
+		return
+	}
+	if a.historyTab != nil {
+		fyne.Do(a.historyTab.refresh)
+	}
+}
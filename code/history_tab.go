@@ -0,0 +1,160 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// allHistoryLanguages is the language filter option that shows every entry
+const allHistoryLanguages = "All languages"
+
+// historyTab lists past transcriptions with a search box and language filter
+type historyTab struct {
+	app      *AppState
+	search   *widget.Entry
+	language *widget.Select
+	list     *widget.List
+	details  *widget.Label
+	results  []HistoryEntry // Entries matching the filters, newest first
+	selected int            // Index into results, -1 for none
+	content  fyne.CanvasObject
+}
+
+// historyPreview shortens entry text to one line for the list
+func historyPreview(text string) string {
+	preview := strings.Join(strings.Fields(text), " ")
+	if runes := []rune(preview); len(runes) > 120 {
+		preview = string(runes[:120]) + "…"
+	}
+	return preview
+}
+
+// formatHistoryEntry describes an entry above its full text
+func formatHistoryEntry(entry HistoryEntry) string {
+	details := entry.Timestamp.Format("2006-01-02 15:04:05")
+	if entry.Language != "" {
+		details += ", " + entry.Language
+	}
+	if entry.Recording != "" {
+		details += ", " + entry.Recording
+	}
+	return details + "\n\n" + entry.Text
+}
+
+// newHistoryTab builds the History tab
+func (a *AppState) newHistoryTab() *historyTab {
+	t := &historyTab{app: a, selected: -1}
+
+	t.search = widget.NewEntry()
+	t.search.SetPlaceHolder("Search history")
+	t.search.OnChanged = func(string) { t.refresh() }
+
+	t.language = widget.NewSelect([]string{allHistoryLanguages}, func(string) { t.refresh() })
+	t.language.SetSelected(allHistoryLanguages)
+
+	t.details = widget.NewLabel("")
+	t.details.Wrapping = fyne.TextWrapWord
+
+	t.list = widget.NewList(
+		func() int { return len(t.results) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			if id >= len(t.results) {
+				return
+			}
+			entry := t.results[id]
+			item.(*widget.Label).SetText(fmt.Sprintf("%s  %s  %s",
+				entry.Timestamp.Format("2006-01-02 15:04"), entry.Language, historyPreview(entry.Text)))
+		},
+	)
+	t.list.OnSelected = func(id widget.ListItemID) {
+		t.selected = id
+		t.details.SetText(formatHistoryEntry(t.results[id]))
+	}
+
+	insertButton := widget.NewButton("Insert into editor", t.insertSelected)
+	copyButton := widget.NewButton("Copy", t.copySelected)
+
+	filters := container.NewBorder(nil, nil, nil, t.language, t.search)
+	details := container.NewBorder(nil, container.NewHBox(insertButton, copyButton), nil, nil, container.NewVScroll(t.details))
+	split := container.NewVSplit(t.list, details)
+	split.Offset = 0.6
+	t.content = container.NewBorder(filters, nil, nil, nil, split)
+
+	t.refresh()
+	return t
+}
+
+// refresh reapplies the filters, keeping the language options up to date
+func (t *historyTab) refresh() {
+	if t.app.history == nil {
+		return
+	}
+	t.language.Options = append([]string{allHistoryLanguages}, t.app.history.Languages()...)
+	t.language.Refresh()
+
+	language := t.language.Selected
+	if language == allHistoryLanguages {
+		language = ""
+	}
+	t.results = t.app.history.Search(t.search.Text, language)
+	t.selected = -1
+	t.list.UnselectAll()
+	t.details.SetText("")
+	t.list.Refresh()
+}
+
+// selectedEntry returns the selected entry, reporting in the status bar if there is none
+func (t *historyTab) selectedEntry() (HistoryEntry, bool) {
+	if t.selected < 0 || t.selected >= len(t.results) {
+		setStatusText(t.app.statusLabel, "Select a history entry first")
+		return HistoryEntry{}, false
+	}
+	return t.results[t.selected], true
+}
+
+// insertSelected appends the selected entry to the editor like an "add" recording
+func (t *historyTab) insertSelected() {
+	entry, ok := t.selectedEntry()
+	if !ok {
+		return
+	}
+	text, _ := reserveAddSpaceText(t.app.correctedText.Text)
+	t.app.correctedText.SetText(text + entry.Text)
+	setStatusText(t.app.statusLabel, "History entry inserted into the editor")
+}
+
+// copySelected copies the selected entry's text to the clipboard
+func (t *historyTab) copySelected() {
+	entry, ok := t.selectedEntry()
+	if !ok {
+		return
+	}
+	if err := copyToClipboard(entry.Text); err != nil {
+		Errorf("Failed to copy history entry: %v", err)
+		setStatusText(t.app.statusLabel, fmt.Sprintf("Copy failed: %v", err))
+		return
+	}
+	setStatusText(t.app.statusLabel, "History entry copied to clipboard")
+}
@@ -0,0 +1,116 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func historyTexts(entries []HistoryEntry) []string {
+	var texts []string
+	for _, entry := range entries {
+		texts = append(texts, entry.Text)
+	}
+	return texts
+}
+
+func TestTranscriptionHistoryReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFile)
+	h := NewTranscriptionHistory(path, 0)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, text := range []string{"first", "второй", "third"} {
+		entry := HistoryEntry{Timestamp: start.Add(time.Duration(i) * time.Minute), Language: "en", Text: text}
+		if err := h.Append(entry); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	// A line cut short by a crash is skipped, the rest still loads
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"timestamp": "2024-05`)
+	file.Close()
+
+	reloaded := NewTranscriptionHistory(path, 0)
+	got := reloaded.Search("", "")
+	if want := []string{"third", "второй", "first"}; !reflect.DeepEqual(historyTexts(got), want) {
+		t.Fatalf("reloaded history = %v, want %v newest first", historyTexts(got), want)
+	}
+	if !got[2].Timestamp.Equal(start) {
+		t.Errorf("timestamp = %v, want %v", got[2].Timestamp, start)
+	}
+}
+
+func TestTranscriptionHistoryRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFile)
+	text := strings.Repeat("x", 100)
+	h := NewTranscriptionHistory(path, 300) // Two entries fit, the third rotates
+	for i := 0; i < 5; i++ {
+		if err := h.Append(HistoryEntry{Text: text + string(rune('a'+i))}); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+
+	// Entries 0-1 went to the backup, which 2-3 then replaced
+	wantAll := []string{text + "e", text + "d", text + "c"}
+	if got := historyTexts(h.Search("", "")); !reflect.DeepEqual(got, wantAll) {
+		t.Errorf("in memory = %v, want %v", got, wantAll)
+	}
+	if got := historyTexts(NewTranscriptionHistory(path, 300).Search("", "")); !reflect.DeepEqual(got, wantAll) {
+		t.Errorf("reloaded = %v, want %v", got, wantAll)
+	}
+	if backup := readHistoryFile(path + ".1"); len(backup) != 2 {
+		t.Errorf("backup has %d entries, want 2", len(backup))
+	}
+}
+
+func TestTranscriptionHistorySearch(t *testing.T) {
+	h := NewTranscriptionHistory(filepath.Join(t.TempDir(), historyFile), 0)
+	for _, entry := range []HistoryEntry{
+		{Language: "en", Text: "Meeting notes", Recording: "recording_1.mp3"},
+		{Language: "ru", Text: "Заметки со ВСТРЕЧИ"},
+		{Language: "en", Text: "Shopping list"},
+		{Text: "No language"},
+	} {
+		if err := h.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query, language string
+		want            []string
+	}{
+		{"", "", []string{"No language", "Shopping list", "Заметки со ВСТРЕЧИ", "Meeting notes"}},
+		{"", "en", []string{"Shopping list", "Meeting notes"}},
+		{"встречи", "", []string{"Заметки со ВСТРЕЧИ"}},
+		{"  NOTES ", "", []string{"Meeting notes"}},
+		{"recording_1", "", []string{"Meeting notes"}},
+		{"notes", "ru", nil},
+	}
+	for _, tt := range tests {
+		if got := historyTexts(h.Search(tt.query, tt.language)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q, %q) = %v, want %v", tt.query, tt.language, got, tt.want)
+		}
+	}
+	if got, want := h.Languages(), []string{"en", "ru"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Languages() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// addAnnotation finishes an annotation. Drawing something new discards the redo history.
+func (c *imageEditorCanvas) addAnnotation(annotation Annotation) {
+	c.annotations = append(c.annotations, annotation)
+	c.redoAnnotations = nil
+}
+
+// undoAnnotation removes the most recent annotation and keeps it for redo
+func (c *imageEditorCanvas) undoAnnotation() {
+	if len(c.annotations) == 0 {
+		return
+	}
+	last := c.annotations[len(c.annotations)-1]
+	c.annotations = c.annotations[:len(c.annotations)-1]
+	c.redoAnnotations = append(c.redoAnnotations, last)
+	Debugf("Image editor: undo, %d annotations left", len(c.annotations))
+	c.Refresh()
+}
+
+// redoAnnotation restores the most recently undone annotation
+func (c *imageEditorCanvas) redoAnnotation() {
+	if len(c.redoAnnotations) == 0 {
+		return
+	}
+	last := c.redoAnnotations[len(c.redoAnnotations)-1]
+	c.redoAnnotations = c.redoAnnotations[:len(c.redoAnnotations)-1]
+	c.annotations = append(c.annotations, last)
+	Debugf("Image editor: redo, %d annotations", len(c.annotations))
+	c.Refresh()
+}
+
+// clearAnnotations removes every annotation. It counts as undoing them all,
+// so redo brings them back one by one.
+func (c *imageEditorCanvas) clearAnnotations() {
+	for len(c.annotations) > 0 {
+		last := c.annotations[len(c.annotations)-1]
+		c.annotations = c.annotations[:len(c.annotations)-1]
+		c.redoAnnotations = append(c.redoAnnotations, last)
+	}
+	Debugf("Image editor: cleared all annotations")
+	c.Refresh()
+}
+
+// addEditorHistoryShortcuts binds Ctrl+Z to undo and Ctrl+Shift+Z / Ctrl+Y to redo
+func addEditorHistoryShortcuts(window fyne.Window, c *imageEditorCanvas) {
+	window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierControl,
+	}, func(fyne.Shortcut) {
+		c.undoAnnotation()
+	})
+	window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift,
+	}, func(fyne.Shortcut) {
+		c.redoAnnotation()
+	})
+	window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyY,
+		Modifier: fyne.KeyModifierControl,
+	}, func(fyne.Shortcut) {
+		c.redoAnnotation()
+	})
+}
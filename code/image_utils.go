@@ -7,9 +7,9 @@ import (
 	"image/color"
 	"image/draw"
 	"image/png"
-	"log"
+	"io"
 	"math"
-	"os/exec"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,47 +21,70 @@ import (
 	"github.com/go-vgo/robotgo"
 )
 
-// copyImageToClipboard copies image to clipboard using xclip
+// pngCompression is the compression level used for captured and edited screenshots
+var pngCompression = png.DefaultCompression
+
+// minSelectionSize is the smallest width and height of a selection that is captured;
+// anything smaller is treated as an accidental click or drag
+const minSelectionSize = 20
+
+// encodePNG encodes img as PNG using the configured compression level
+func encodePNG(w io.Writer, img image.Image) error {
+	encoder := png.Encoder{CompressionLevel: pngCompression}
+	return encoder.Encode(w, img)
+}
+
+// copyImageToClipboard copies image to clipboard using xclip or wl-copy
 func copyImageToClipboard(imageData []byte) error {
-	cmd := exec.Command("xclip", "-selection", "clipboard", "-t", "image/png")
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return err
-	}
+	return writeClipboard(imageData, "image/png")
+}
 
-	if err := cmd.Start(); err != nil {
-		return err
-	}
+// captureScreenRegion captures a region of the screen given in global (virtual desktop)
+// coordinates, which may be negative for monitors left of or above the primary one.
+// The region is clamped to the desktop; a region on a single display is captured
+// from that display, and one spanning displays is grabbed from the whole desktop.
+func captureScreenRegion(x, y, width, height int) ([]byte, error) {
+	Debugf("captureScreenRegion called with x=%d, y=%d, width=%d, height=%d", x, y, width, height)
 
-	if _, err := stdin.Write(imageData); err != nil {
-		return err
+	region := image.Rect(x, y, x+width, y+height)
+	if desktop := virtualDesktopBounds(); !desktop.Empty() {
+		region = clampToDisplay(region, desktop)
+		if region.Empty() {
+			return nil, fmt.Errorf("selection is outside the desktop %v", desktop)
+		}
 	}
 
-	if err := stdin.Close(); err != nil {
-		return err
+	if index, ok := displayContaining(region); ok {
+		return captureDisplayRegion(index, region.Min.X, region.Min.Y, region.Dx(), region.Dy())
 	}
 
-	return cmd.Wait()
-}
-
-// captureScreenRegion captures a region of the screen.
-// It first takes a full-screen screenshot and then crops the desired region.
-func captureScreenRegion(x, y, width, height int) ([]byte, error) {
-	log.Printf("captureScreenRegion called with x=%d, y=%d, width=%d, height=%d", x, y, width, height)
+	if data, ok := captureWayland(region.Min.X, region.Min.Y, region.Dx(), region.Dy()); ok {
+		return data, nil
+	}
 
-	// Capture full screen
-	screenBitmap := robotgo.CaptureScreen()
+	// The selection spans several displays: capture exactly that part of the desktop
+	Infof("Capturing region %v across displays with robotgo (X11)", region)
+	screenBitmap := robotgo.CaptureScreen(region.Min.X, region.Min.Y, region.Dx(), region.Dy())
 	if screenBitmap == nil {
-		return nil, fmt.Errorf("failed to capture full screen")
+		return nil, fmt.Errorf("failed to capture region %v", region)
 	}
 	defer robotgo.FreeBitmap(screenBitmap)
 
-	fullImg := robotgo.ToImage(screenBitmap)
-	if fullImg == nil {
+	regionImg := robotgo.ToImage(screenBitmap)
+	if regionImg == nil {
 		return nil, fmt.Errorf("failed to convert screen bitmap to image")
 	}
 
-	bounds := fullImg.Bounds()
+	var buf bytes.Buffer
+	if err := encodePNG(&buf, regionImg); err != nil {
+		return nil, fmt.Errorf("failed to encode captured region as PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// cropImageToPNG crops img to the given region, clamped to the image bounds, and encodes it as PNG
+func cropImageToPNG(img image.Image, x, y, width, height int) ([]byte, error) {
+	bounds := img.Bounds()
 
 	// Clamp requested region to screen bounds
 	if x < bounds.Min.X {
@@ -90,7 +113,7 @@ func captureScreenRegion(x, y, width, height int) ([]byte, error) {
 
 	region := image.Rect(x, y, x+width, y+height)
 
-	subImager, ok := fullImg.(interface {
+	subImager, ok := img.(interface {
 		SubImage(r image.Rectangle) image.Image
 	})
 	if !ok {
@@ -100,7 +123,7 @@ func captureScreenRegion(x, y, width, height int) ([]byte, error) {
 	cropped := subImager.SubImage(region)
 
 	var buf bytes.Buffer
-	if err := png.Encode(&buf, cropped); err != nil {
+	if err := encodePNG(&buf, cropped); err != nil {
 		return nil, fmt.Errorf("failed to encode cropped image as PNG: %w", err)
 	}
 
@@ -109,7 +132,10 @@ func captureScreenRegion(x, y, width, height int) ([]byte, error) {
 
 // captureSelection captures the selected region as screenshot
 func (a *AppState) captureSelection() {
-	log.Printf("captureSelection called")
+	Debugf("captureSelection called")
+
+	// Make sure the selection frame is not part of the screenshot
+	a.selectionOverlay.hideNow()
 	a.mouseHookMutex.Lock()
 	startX := a.startX
 	startY := a.startY
@@ -117,89 +143,92 @@ func (a *AppState) captureSelection() {
 	endY := a.lastY
 	a.mouseHookMutex.Unlock()
 
-	log.Printf("Selection coordinates: start=(%d, %d), end=(%d, %d)", startX, startY, endX, endY)
+	Debugf("Selection coordinates: start=(%d, %d), end=(%d, %d)", startX, startY, endX, endY)
 
 	if startX == 0 && startY == 0 && endX == 0 && endY == 0 {
-		log.Printf("Warning: Selection coordinates are all zero, skipping capture")
+		Warnf("Selection coordinates are all zero, skipping capture")
 		return
 	}
 
 	// If end coordinates are zero, use current mouse position
 	if endX == 0 && endY == 0 {
 		endX, endY = robotgo.GetMousePos()
-		log.Printf("End coordinates were zero, using current mouse position: (%d, %d)", endX, endY)
+		Debugf("End coordinates were zero, using current mouse position: (%d, %d)", endX, endY)
 	}
 
-	log.Printf("Selection region (before normalization): start=(%d, %d), end=(%d, %d)", startX, startY, endX, endY)
+	Debugf("Selection region (before normalization): start=(%d, %d), end=(%d, %d)", startX, startY, endX, endY)
 
-	// Calculate region
-	minX := startX
-	if endX < minX {
-		minX = endX
-	}
-	minY := startY
-	if endY < minY {
-		minY = endY
-	}
-	width := startX - endX
-	if width < 0 {
-		width = -width
-	}
-	height := startY - endY
-	if height < 0 {
-		height = -height
-	}
+	// image.Rect puts the top-left first, whichever direction the drag went
+	region := image.Rect(startX, startY, endX, endY)
+	Debugf("Normalized selection region: x=%d, y=%d, width=%d, height=%d",
+		region.Min.X, region.Min.Y, region.Dx(), region.Dy())
 
-	// Ensure minimum size
-	if width < 10 {
-		width = 10
-	}
-	if height < 10 {
-		height = 10
+	if region.Dx() < minSelectionSize || region.Dy() < minSelectionSize {
+		Infof("Selection %dx%d is below the %dpx minimum, skipping capture",
+			region.Dx(), region.Dy(), minSelectionSize)
+		postStatusText(a.statusLabel, fmt.Sprintf("Selection too small (%dx%d), drag at least %dx%d to capture",
+			region.Dx(), region.Dy(), minSelectionSize, minSelectionSize))
+		return
 	}
 
-	log.Printf("Normalized selection region: x=%d, y=%d, width=%d, height=%d", minX, minY, width, height)
-
-	// Capture screenshot using full-screen capture + crop
-	imageData, err := captureScreenRegion(minX, minY, width, height)
+	// Capture screenshot using full-screen capture + crop, constrained to the target display if set
+	imageData, err := a.captureRegion(region)
 	if err != nil {
-		log.Printf("Failed to capture screenshot: %v", err)
+		Errorf("Failed to capture screenshot: %v", err)
 	} else {
-		log.Printf("Screenshot captured successfully, size: %d bytes", len(imageData))
+		Infof("Screenshot captured successfully, size: %d bytes", len(imageData))
 		// Update UI with captured image
 		a.updateCapturedImage(imageData)
-		// Close all existing editor windows before opening new one
-		log.Printf("Closing all existing image editor windows")
-		closeAllImageEditorWindows(a)
-		// Automatically open image editor with captured image
-		log.Printf("Opening image editor automatically after CTRL+SHIFT capture")
-		openImageEditorWithAppState(imageData, a)
+		// Automatically open image editor with captured image; this runs on the
+		// mouse hook goroutine, so the window is created through fyne.Do
+		Infof("Opening image editor automatically after CTRL+SHIFT capture")
+		fyne.Do(func() {
+			openImageEditorWithAppState(imageData, a)
+		})
 	}
 }
 
-// updateCapturedImage updates the UI with the captured image
+// updateCapturedImage updates the UI with the captured image. It may be called
+// from any goroutine; the widgets are updated through fyne.Do.
 func (a *AppState) updateCapturedImage(imageData []byte) {
-	log.Printf("updateCapturedImage called, image size: %d bytes", len(imageData))
-	a.imageData = imageData
+	Debugf("updateCapturedImage called, image size: %d bytes", len(imageData))
 
 	// Verify image can be decoded
 	_, _, err := image.Decode(bytes.NewReader(imageData))
 	if err != nil {
-		log.Printf("Failed to decode image: %v", err)
+		Errorf("Failed to decode image: %v", err)
 		return
 	}
 
-	log.Printf("Image decoded successfully")
+	Debugf("Image decoded successfully")
 
 	// Create image resource
 	resource := fyne.NewStaticResource("captured.png", imageData)
 
-	// Update UI in main thread using RunOnMainThread
 	if a.imageContainer == nil {
-		log.Printf("imageContainer is nil, cannot update UI")
+		Errorf("imageContainer is nil, cannot update UI")
 		return
 	}
 
+	// The thumbnail is swapped through fyne.Do because captures arrive on the mouse hook goroutine
+	fyne.Do(func() {
+		a.imageData = imageData
+		a.showCapturedImage(resource, imageData)
+	})
+
+	// Automatically copy image to clipboard when it's added to UI
+	Infof("Copying captured image to clipboard automatically")
+	if err := copyImageToClipboard(imageData); err != nil {
+		Errorf("Failed to copy image to clipboard: %v", err)
+		postStatusText(a.statusLabel, fmt.Sprintf("Image captured but copy failed: %v", err))
+	} else {
+		Infof("Image copied to clipboard successfully")
+		postStatusText(a.statusLabel, "Image captured")
+	}
+}
+
+// showCapturedImage replaces the thumbnail with the captured image. Call it on the UI goroutine.
+func (a *AppState) showCapturedImage(resource fyne.Resource, imageData []byte) {
 	// Create canvas image from resource
 	img := canvas.NewImageFromResource(resource)
 	img.FillMode = canvas.ImageFillContain
@@ -216,17 +245,10 @@ func (a *AppState) updateCapturedImage(imageData []byte) {
 	clickableContainer.Add(img)
 
 	// Use a custom widget that handles clicks
-	imageWidget := newClickableImage(img, a.imageData, a.statusLabel, &lastClickTime, &clickCount, &clickMutex, a)
+	imageWidget := newClickableImage(img, imageData, a.statusLabel, &lastClickTime, &clickCount, &clickMutex, a)
 
-	// Update container - Fyne widgets should be thread-safe, but let's be explicit
-	log.Printf("Updating image container")
-	if a.imageContainer == nil {
-		log.Printf("ERROR: imageContainer is nil, cannot update UI")
-		return
-	}
-
-	// Update container directly - Fyne handles thread safety
-	// But we'll also try to refresh the window
+	// Replace the previous thumbnail and refresh the window
+	Debugf("Updating image container")
 	a.imageContainer.RemoveAll()
 	a.imageContainer.Add(imageWidget)
 	a.imageContainer.Refresh()
@@ -239,17 +261,7 @@ func (a *AppState) updateCapturedImage(imageData []byte) {
 		}
 	}
 
-	log.Printf("Image container updated successfully")
-
-	// Automatically copy image to clipboard when it's added to UI
-	log.Printf("Copying captured image to clipboard automatically")
-	if err := copyImageToClipboard(imageData); err != nil {
-		log.Printf("Failed to copy image to clipboard: %v", err)
-		setStatusText(a.statusLabel, fmt.Sprintf("Image captured but copy failed: %v", err))
-	} else {
-		log.Printf("Image copied to clipboard successfully")
-		setStatusText(a.statusLabel, "Image captured")
-	}
+	Debugf("Image container updated successfully")
 }
 
 // clickableImage is a custom widget that handles clicks and double-clicks on images
@@ -320,7 +332,7 @@ func (c *clickableImage) Tapped(ev *fyne.PointEvent) {
 		if *c.clickCount == 2 {
 			*c.clickCount = 0
 			// Double click - open editor window
-			log.Printf("Double click detected, opening image editor")
+			Infof("Double click detected, opening image editor")
 			openImageEditorWithAppState(c.imageData, c.appState)
 			return
 		}
@@ -329,36 +341,44 @@ func (c *clickableImage) Tapped(ev *fyne.PointEvent) {
 	}
 
 	// Single click - copy to clipboard
-	log.Printf("Single click detected, copying image to clipboard")
+	Infof("Single click detected, copying image to clipboard")
 	if err := copyImageToClipboard(c.imageData); err != nil {
-		log.Printf("Failed to copy image to clipboard: %v", err)
+		Errorf("Failed to copy image to clipboard: %v", err)
 		if c.statusLabel != nil {
 			setStatusText(c.statusLabel, fmt.Sprintf("Copy failed: %v", err))
 		}
 	} else {
-		log.Printf("Image copied to clipboard successfully")
+		Infof("Image copied to clipboard successfully")
 		if c.statusLabel != nil {
 			setStatusText(c.statusLabel, "Image copied to clipboard")
 		}
 	}
 }
 
-// Arrow represents a drawn arrow
+// Arrow represents a drawn arrow with the style it was drawn in
 type Arrow struct {
 	StartX, StartY int
 	EndX, EndY     int
+	Color          color.RGBA
+	Width          int
 }
 
-// imageEditorCanvas is a custom canvas for drawing arrows on images
+// imageEditorCanvas is a custom canvas for drawing arrows and other annotations on images
 type imageEditorCanvas struct {
 	widget.BaseWidget
-	baseImage    image.Image
-	arrows       []Arrow
-	currentArrow *Arrow
-	isDrawing    bool
-	imageData    []byte
-	imageOffsetX float32 // Offset of image in container (for centering)
-	imageOffsetY float32
+	baseImage       image.Image
+	annotations     []Annotation
+	redoAnnotations []Annotation    // Undone annotations, most recent last
+	current         Annotation      // Shape being dragged, nil when not drawing
+	editingText     *TextAnnotation // Caption being typed, nil when not typing
+	tool            annotationTool
+	drawColor       color.RGBA // Colour of new annotations
+	drawWidth       int        // Line width of new annotations in pixels
+	notePending     bool       // A dictated note is being recorded or transcribed
+	isDrawing       bool
+	imageData       []byte
+	imageOffsetX    float32 // Offset of image in container (for centering)
+	imageOffsetY    float32
 }
 
 func newImageEditorCanvas(imageData []byte) (*imageEditorCanvas, error) {
@@ -368,9 +388,12 @@ func newImageEditorCanvas(imageData []byte) (*imageEditorCanvas, error) {
 	}
 
 	c := &imageEditorCanvas{
-		baseImage: img,
-		arrows:    make([]Arrow, 0),
-		imageData: imageData,
+		baseImage:   img,
+		annotations: make([]Annotation, 0),
+		tool:        toolArrow,
+		drawColor:   annotationPalette[0].Color,
+		drawWidth:   defaultAnnotationWidth,
+		imageData:   imageData,
 	}
 	c.ExtendBaseWidget(c)
 	return c, nil
@@ -400,30 +423,30 @@ func (c *imageEditorCanvas) convertMouseToImageCoords(mouseX, mouseY float32) (i
 
 // MouseDown implements desktop.Mouseable
 func (c *imageEditorCanvas) MouseDown(ev *desktop.MouseEvent) {
-	log.Printf("MouseDown at %v (image offset: %v, %v)", ev.Position, c.imageOffsetX, c.imageOffsetY)
+	Debugf("MouseDown at %v (image offset: %v, %v)", ev.Position, c.imageOffsetX, c.imageOffsetY)
 	imgX, imgY := c.convertMouseToImageCoords(ev.Position.X, ev.Position.Y)
-	log.Printf("Converted to image coordinates: (%d, %d)", imgX, imgY)
+	Debugf("Converted to image coordinates: (%d, %d)", imgX, imgY)
+	c.commitText()
 	c.isDrawing = true
-	c.currentArrow = &Arrow{
-		StartX: imgX,
-		StartY: imgY,
-		EndX:   imgX,
-		EndY:   imgY,
-	}
+	c.current = c.newAnnotation(imgX, imgY)
 	c.Refresh()
 }
 
 // MouseUp implements desktop.Mouseable
 func (c *imageEditorCanvas) MouseUp(ev *desktop.MouseEvent) {
-	if c.isDrawing && c.currentArrow != nil {
+	if c.isDrawing && c.current != nil {
 		imgX, imgY := c.convertMouseToImageCoords(ev.Position.X, ev.Position.Y)
-		c.currentArrow.EndX = imgX
-		c.currentArrow.EndY = imgY
-		c.arrows = append(c.arrows, *c.currentArrow)
-		log.Printf("Arrow drawn: start=(%d,%d), end=(%d,%d), total arrows: %d",
-			c.currentArrow.StartX, c.currentArrow.StartY,
-			c.currentArrow.EndX, c.currentArrow.EndY, len(c.arrows))
-		c.currentArrow = nil
+		c.current.Extend(imgX, imgY)
+		if text, ok := c.current.(*TextAnnotation); ok {
+			// Captions are added once typing is finished
+			c.current = nil
+			c.isDrawing = false
+			c.startTextAnnotation(text)
+			return
+		}
+		c.addAnnotation(c.current)
+		Infof("Annotation drawn (%s), total annotations: %d", c.tool, len(c.annotations))
+		c.current = nil
 		c.isDrawing = false
 		c.Refresh()
 	}
@@ -431,19 +454,18 @@ func (c *imageEditorCanvas) MouseUp(ev *desktop.MouseEvent) {
 
 // MouseDragged implements desktop.Mouseable
 func (c *imageEditorCanvas) MouseDragged(ev *desktop.MouseEvent) {
-	if c.isDrawing && c.currentArrow != nil {
+	if c.isDrawing && c.current != nil {
 		imgX, imgY := c.convertMouseToImageCoords(ev.Position.X, ev.Position.Y)
-		c.currentArrow.EndX = imgX
-		c.currentArrow.EndY = imgY
+		c.current.Extend(imgX, imgY)
 		c.Refresh()
 	}
 }
 
 func (c *imageEditorCanvas) CreateRenderer() fyne.WidgetRenderer {
 	// Create initial image with arrows
-	log.Printf("Creating renderer for image editor canvas, image bounds: %v", c.baseImage.Bounds())
+	Debugf("Creating renderer for image editor canvas, image bounds: %v", c.baseImage.Bounds())
 	imgData := c.drawImageWithArrows()
-	log.Printf("Image data size: %d bytes", len(imgData))
+	Debugf("Image data size: %d bytes", len(imgData))
 	resource := fyne.NewStaticResource("canvas.png", imgData)
 	imgObj := canvas.NewImageFromResource(resource)
 	imgObj.FillMode = canvas.ImageFillOriginal
@@ -456,29 +478,36 @@ func (c *imageEditorCanvas) CreateRenderer() fyne.WidgetRenderer {
 	}
 }
 
+// drawImageWithArrows renders the annotated image and encodes it as PNG
 func (c *imageEditorCanvas) drawImageWithArrows() []byte {
+	rgba := c.renderAnnotated()
+
+	// Encode to PNG
+	var buf bytes.Buffer
+	if err := encodePNG(&buf, rgba); err != nil {
+		Errorf("Failed to encode image: %v", err)
+		return c.imageData
+	}
+	return buf.Bytes()
+}
+
+// renderAnnotated draws all annotations, including any in progress, over the base image
+func (c *imageEditorCanvas) renderAnnotated() *image.RGBA {
 	bounds := c.baseImage.Bounds()
 	rgba := image.NewRGBA(bounds)
 	draw.Draw(rgba, bounds, c.baseImage, bounds.Min, draw.Src)
 
-	// Draw all arrows
-	for _, arrow := range c.arrows {
-		drawArrow(rgba, arrow.StartX, arrow.StartY, arrow.EndX, arrow.EndY)
+	// Draw all annotations
+	for _, annotation := range c.annotations {
+		annotation.Draw(rgba)
 	}
 
-	// Draw current arrow if drawing
-	if c.currentArrow != nil {
-		drawArrow(rgba, c.currentArrow.StartX, c.currentArrow.StartY,
-			c.currentArrow.EndX, c.currentArrow.EndY)
+	// Draw current annotation if drawing
+	if c.current != nil {
+		c.current.Draw(rgba)
 	}
-
-	// Encode to PNG
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, rgba); err != nil {
-		log.Printf("Failed to encode image: %v", err)
-		return c.imageData
-	}
-	return buf.Bytes()
+	c.drawEditingText(rgba)
+	return rgba
 }
 
 type imageEditorCanvasRenderer struct {
@@ -525,20 +554,22 @@ func (r *imageEditorCanvasRenderer) Refresh() {
 func (r *imageEditorCanvasRenderer) Destroy() {
 }
 
-func drawArrow(img *image.RGBA, x1, y1, x2, y2 int) {
-	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+// drawArrow draws an arrow in its own colour and width
+func drawArrow(img *image.RGBA, arrow Arrow) {
+	width := max(arrow.Width, minAnnotationWidth)
 
 	// Draw line
-	drawLine(img, x1, y1, x2, y2, red, 2)
+	drawLine(img, arrow.StartX, arrow.StartY, arrow.EndX, arrow.EndY, arrow.Color, width)
 
 	// Draw arrowhead
-	drawArrowhead(img, x1, y1, x2, y2, red)
+	drawArrowhead(img, arrow.StartX, arrow.StartY, arrow.EndX, arrow.EndY, arrow.Color, width)
 }
 
 func drawLine(img *image.RGBA, x1, y1, x2, y2 int, c color.Color, width int) {
 	dx := x2 - x1
 	dy := y2 - y1
 	steps := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+	bounds := img.Bounds()
 
 	if steps == 0 {
 		return
@@ -549,10 +580,10 @@ func drawLine(img *image.RGBA, x1, y1, x2, y2 int, c color.Color, width int) {
 		x := int(float64(x1) + float64(dx)*t)
 		y := int(float64(y1) + float64(dy)*t)
 
-		// Draw with width
+		// Draw with width, skipping pixels outside the image
 		for wx := -width / 2; wx <= width/2; wx++ {
 			for wy := -width / 2; wy <= width/2; wy++ {
-				if x+wx >= 0 && x+wx < img.Bounds().Dx() && y+wy >= 0 && y+wy < img.Bounds().Dy() {
+				if image.Pt(x+wx, y+wy).In(bounds) {
 					img.Set(x+wx, y+wy, c)
 				}
 			}
@@ -560,14 +591,14 @@ func drawLine(img *image.RGBA, x1, y1, x2, y2 int, c color.Color, width int) {
 	}
 }
 
-func drawArrowhead(img *image.RGBA, x1, y1, x2, y2 int, c color.Color) {
+func drawArrowhead(img *image.RGBA, x1, y1, x2, y2 int, c color.Color, width int) {
 	// Calculate angle
 	dx := float64(x2 - x1)
 	dy := float64(y2 - y1)
 	angle := math.Atan2(dy, dx)
 
-	// Arrowhead size
-	size := 15.0
+	// Arrowhead size, growing with thicker lines so the head stays visible
+	size := math.Max(15, 15+2*float64(width-defaultAnnotationWidth))
 
 	// Calculate arrowhead points
 	arrowAngle := math.Pi / 6 // 30 degrees
@@ -581,67 +612,27 @@ func drawArrowhead(img *image.RGBA, x1, y1, x2, y2 int, c color.Color) {
 	py2 := y2 - int(size*math.Sin(angle+arrowAngle))
 
 	// Draw arrowhead triangle
-	drawLine(img, x2, y2, px1, py1, c, 2)
-	drawLine(img, x2, y2, px2, py2, c, 2)
-	drawLine(img, px1, py1, px2, py2, c, 2)
+	drawLine(img, x2, y2, px1, py1, c, width)
+	drawLine(img, x2, y2, px2, py2, c, width)
+	drawLine(img, px1, py1, px2, py2, c, width)
 }
 
-// closeAllImageEditorWindows closes all open image editor windows
-func closeAllImageEditorWindows(appState *AppState) {
-	currentApp := fyne.CurrentApp()
-	if currentApp == nil {
-		return
-	}
-
-	// First, close the window stored in AppState if it exists
-	if appState != nil && appState.imageEditorWindow != nil {
-		log.Printf("Closing image editor window from AppState")
-		windowToClose := appState.imageEditorWindow
-		appState.imageEditorWindow = nil
-		// Clear CloseIntercept to avoid recursion and issues
-		windowToClose.SetCloseIntercept(nil)
-		windowToClose.Close()
-		// Small delay to ensure window is closed
-		time.Sleep(50 * time.Millisecond)
+// embedTranscriptInPNG stores caption as PNG text metadata, returning the
+// original image unchanged if the caption is empty or embedding fails
+func embedTranscriptInPNG(pngData []byte, caption string) []byte {
+	caption = strings.TrimSpace(caption)
+	if caption == "" {
+		return pngData
 	}
 
-	// Close all windows with title "Editor" (editor windows)
-	// Get all windows and close those that are editor windows
-	allWindows := currentApp.Driver().AllWindows()
-	editorWindowsToClose := make([]fyne.Window, 0)
-
-	for _, window := range allWindows {
-		if window != nil && window.Title() == "Editor" {
-			log.Printf("Found image editor window to close: %s", window.Title())
-			editorWindowsToClose = append(editorWindowsToClose, window)
-		}
+	withText, err := embedPNGText(pngData, pngDescriptionKeyword, caption)
+	if err != nil {
+		Errorf("Failed to embed transcript in PNG: %v", err)
+		return pngData
 	}
 
-	// Close all found editor windows
-	for _, window := range editorWindowsToClose {
-		log.Printf("Closing image editor window: %s", window.Title())
-		// Clear CloseIntercept to avoid recursion
-		window.SetCloseIntercept(nil)
-		window.Close()
-		// Small delay between closing windows
-		time.Sleep(50 * time.Millisecond)
-	}
-
-	// Final check: clear AppState reference if it still points to something
-	if appState != nil && appState.imageEditorWindow != nil {
-		// Check if window is still in the list of open windows
-		stillOpen := false
-		for _, window := range currentApp.Driver().AllWindows() {
-			if window == appState.imageEditorWindow {
-				stillOpen = true
-				break
-			}
-		}
-		if !stillOpen {
-			log.Printf("Clearing stale reference to closed editor window")
-			appState.imageEditorWindow = nil
-		}
-	}
+	Infof("Embedded transcript (%d chars) in PNG metadata", len(caption))
+	return withText
 }
 
 // openImageEditor opens a new window with image editor
@@ -654,23 +645,30 @@ func openImageEditorWithAppState(imageData []byte, appState *AppState) {
 	// Use existing app instead of creating new one
 	currentApp := fyne.CurrentApp()
 	if currentApp == nil {
-		log.Printf("No current Fyne app available")
+		Infof("No current Fyne app available")
 		return
 	}
 
 	editorWindow := currentApp.NewWindow("Editor")
 
-	// Store reference to editor window in AppState if provided
-	if appState != nil {
-		appState.imageEditorWindow = editorWindow
-	}
-
 	canvasWidget, err := newImageEditorCanvas(imageData)
 	if err != nil {
-		log.Printf("Failed to create image editor canvas: %v", err)
+		Errorf("Failed to create image editor canvas: %v", err)
 		return
 	}
 
+	// Track the editor in AppState if provided, closing the oldest ones over the cap
+	if appState != nil {
+		for _, oldWindow := range appState.trackEditorWindow(editorWindow) {
+			Infof("Editor window limit (%d) reached, closing the oldest editor", appState.config.MaxEditorWindows)
+			oldWindow.SetCloseIntercept(nil)
+			oldWindow.Close()
+		}
+		editorWindow.SetOnClosed(func() {
+			appState.untrackEditorWindow(editorWindow)
+		})
+	}
+
 	// Get image bounds
 	bounds := canvasWidget.baseImage.Bounds()
 	imgWidth := float32(bounds.Dx())
@@ -699,15 +697,39 @@ func openImageEditorWithAppState(imageData []byte, appState *AppState) {
 
 	// Create container that centers the canvas (no scroll, image stays original size)
 	// Use Max container to fill window, canvas will center itself in Layout
-	canvasContainer := container.NewMax(canvasWidget)
-
-	editorWindow.SetContent(canvasContainer)
+	editorWindow.SetContent(editorContent(canvasWidget, appState))
 
 	// Add Escape key handler to close window without saving
 	// Add W key handler to close window and save image
+	// Add S key handler to save the image to a PNG or JPEG file
+	// Add C key handler to clear all annotations (undo brings them back)
+	// Add O key handler to append the text in the image to the main editor (needs tesseract)
+	// Number keys 1-5 pick the colour and +/- the width of new annotations
+	// T switches between the arrow, rectangle, freehand, redact and text tools
+	// While a caption is being typed, keys edit the caption instead
+	editorWindow.Canvas().SetOnTypedRune(canvasWidget.handleTextRune)
 	editorWindow.Canvas().SetOnTypedKey(func(event *fyne.KeyEvent) {
+		if canvasWidget.handleTextKey(event.Name) {
+			return
+		}
+		if canvasWidget.handleToolKey(event.Name) {
+			if appState != nil {
+				setStatusText(appState.statusLabel, "Drawing tool: "+canvasWidget.tool.String())
+			}
+			return
+		}
+		if canvasWidget.handleStyleKey(event.Name) {
+			if appState != nil {
+				setStatusText(appState.statusLabel, "Drawing style: "+canvasWidget.styleDescription())
+			}
+			return
+		}
+		if (event.Name == fyne.KeyEscape || event.Name == fyne.KeyW) && appState != nil &&
+			notePendingBlocksClose(canvasWidget, appState) {
+			return
+		}
 		if event.Name == fyne.KeyEscape {
-			log.Printf("Escape pressed in image editor, closing window without saving")
+			Infof("Escape pressed in image editor, closing window without saving")
 			// Clear reference when closing
 			if appState != nil {
 				appState.imageEditorWindow = nil
@@ -715,11 +737,16 @@ func openImageEditorWithAppState(imageData []byte, appState *AppState) {
 			// Close window without saving
 			editorWindow.Close()
 		} else if event.Name == fyne.KeyW {
-			log.Printf("W pressed in image editor, closing window and saving image")
+			Infof("W pressed in image editor, closing window and saving image")
 
-			// Get final image with all arrows
+			// Get final image with all annotations
 			finalImageData := canvasWidget.drawImageWithArrows()
 
+			// Optionally carry the dictated description inside the PNG
+			if appState != nil && appState.config.EmbedTranscript && appState.correctedText != nil {
+				finalImageData = embedTranscriptInPNG(finalImageData, appState.correctedText.Text)
+			}
+
 			// Update main UI if AppState is provided
 			if appState != nil {
 				appState.updateCapturedImage(finalImageData)
@@ -728,19 +755,32 @@ func openImageEditorWithAppState(imageData []byte, appState *AppState) {
 
 			// Copy to clipboard
 			if err := copyImageToClipboard(finalImageData); err != nil {
-				log.Printf("Failed to copy edited image to clipboard: %v", err)
+				Errorf("Failed to copy edited image to clipboard: %v", err)
 			} else {
-				log.Printf("Edited image copied to clipboard")
+				Infof("Edited image copied to clipboard")
 			}
 
 			// Close window
 			editorWindow.Close()
+		} else if event.Name == fyne.KeyS {
+			showSaveScreenshotDialog(editorWindow, canvasWidget, appState)
+		} else if event.Name == fyne.KeyC {
+			canvasWidget.clearAnnotations()
+		} else if event.Name == fyne.KeyO && appState != nil {
+			// Recognize the annotated image so redacted areas stay unreadable
+			appState.recognizeScreenshotText(canvasWidget.drawImageWithArrows())
 		}
 	})
 
+	// Ctrl+Z undoes the last annotation, Ctrl+Shift+Z or Ctrl+Y redoes it
+	addEditorHistoryShortcuts(editorWindow, canvasWidget)
+
 	// Clear reference when window is closed (for Escape key or window close button)
 	editorWindow.SetCloseIntercept(func() {
 		if appState != nil {
+			if notePendingBlocksClose(canvasWidget, appState) {
+				return
+			}
 			appState.imageEditorWindow = nil
 		}
 		editorWindow.Close()
@@ -0,0 +1,244 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+)
+
+// pngCompressionLevels are the levels selectable with the PNG compression setting
+var pngCompressionLevels = []struct {
+	name  string
+	level png.CompressionLevel
+}{
+	{"none", png.NoCompression},
+	{"speed", png.BestSpeed},
+	{"default", png.DefaultCompression},
+	{"best", png.BestCompression},
+}
+
+// testScreenshot returns a screenshot-like image: flat window backgrounds,
+// a gradient title bar and rows of text-like glyph noise
+func testScreenshot(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 240, G: 240, B: 240, A: 255}
+			switch {
+			case y < 32:
+				c = color.RGBA{R: uint8(40 + x*60/width), G: 60, B: 90, A: 255}
+			case y%20 < 12 && x%400 < 360 && (x*7+y*13)%11 < 4:
+				c = color.RGBA{R: 20, G: 20, B: 20, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEncodePNGRoundTrip(t *testing.T) {
+	defer func(level png.CompressionLevel) { pngCompression = level }(pngCompression)
+
+	img := testScreenshot(200, 120)
+	for _, tt := range pngCompressionLevels {
+		t.Run(tt.name, func(t *testing.T) {
+			pngCompression = tt.level
+			var buf bytes.Buffer
+			if err := encodePNG(&buf, img); err != nil {
+				t.Fatalf("encodePNG: %v", err)
+			}
+			decoded, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatalf("png.Decode: %v", err)
+			}
+			if decoded.Bounds() != img.Bounds() {
+				t.Fatalf("decoded bounds %v, want %v", decoded.Bounds(), img.Bounds())
+			}
+			for _, p := range []image.Point{{0, 0}, {100, 10}, {57, 40}, {199, 119}} {
+				r, g, b, a := decoded.At(p.X, p.Y).RGBA()
+				wr, wg, wb, wa := img.At(p.X, p.Y).RGBA()
+				if r != wr || g != wg || b != wb || a != wa {
+					t.Errorf("pixel %v changed after encoding", p)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEncodePNG compares encode time and output size of a full HD
+// screenshot across compression levels
+func BenchmarkEncodePNG(b *testing.B) {
+	defer func(level png.CompressionLevel) { pngCompression = level }(pngCompression)
+
+	img := testScreenshot(1920, 1080)
+	for _, tt := range pngCompressionLevels {
+		b.Run(tt.name, func(b *testing.B) {
+			pngCompression = tt.level
+			var buf bytes.Buffer
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := encodePNG(&buf, img); err != nil {
+					b.Fatalf("encodePNG: %v", err)
+				}
+			}
+			b.ReportMetric(float64(buf.Len()), "png-bytes")
+		})
+	}
+}
+
+func TestOpenImageEditorKeepsWindowCap(t *testing.T) {
+	tests := []struct {
+		name   string
+		cap    int
+		opened int
+		want   int
+	}{
+		{"cap of one", 1, 5, 1},
+		{"cap of three", 3, 5, 3},
+		{"under the cap", 3, 2, 2},
+		{"invalid cap keeps one", 0, 4, 1},
+	}
+
+	imageData := testPNG(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := test.NewApp()
+			defer app.Quit()
+			// The test driver may already hold a window of its own
+			before := len(app.Driver().AllWindows())
+
+			a := newTestAppState(context.Background())
+			a.config.MaxEditorWindows = tt.cap
+			var opened []fyne.Window
+			for i := 0; i < tt.opened; i++ {
+				openImageEditorWithAppState(imageData, a)
+				opened = append(opened, a.imageEditorWindow)
+			}
+
+			if got := len(app.Driver().AllWindows()) - before; got != tt.want {
+				t.Errorf("%d editor windows open, want %d", got, tt.want)
+			}
+			if len(a.editorWindows) != tt.want {
+				t.Errorf("%d editor windows tracked, want %d", len(a.editorWindows), tt.want)
+			}
+			// The newest editors stay open, oldest first
+			for i, w := range a.editorWindows {
+				if want := opened[tt.opened-tt.want+i]; w != want {
+					t.Errorf("tracked window %d is not the editor opened %d", i, tt.opened-tt.want+i)
+				}
+			}
+			if a.imageEditorWindow != opened[tt.opened-1] {
+				t.Error("imageEditorWindow is not the newest editor")
+			}
+		})
+	}
+}
+
+func TestDrawLineStaysInBounds(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	background := color.RGBA{R: 1, G: 2, B: 3, A: 255}
+
+	lines := []struct {
+		name           string
+		x1, y1, x2, y2 int
+		visible        bool
+	}{
+		{"across", 10, 15, 50, 35, true},
+		{"along top edge", 10, 10, 60, 10, true},
+		{"along right edge", 59, 10, 59, 40, true},
+		{"from outside to inside", -20, -20, 30, 30, true},
+		{"entirely outside", 100, 100, 200, 150, false},
+		{"through corner", 0, 50, 70, 0, true},
+	}
+
+	for _, width := range []int{minAnnotationWidth, defaultAnnotationWidth, 5, maxAnnotationWidth, 40} {
+		for _, line := range lines {
+			t.Run(fmt.Sprintf("%s width %d", line.name, width), func(t *testing.T) {
+				// Draw on a sub-image with a non-zero origin so the parent shows any spill
+				parent := image.NewRGBA(image.Rect(0, 0, 70, 50))
+				for y := 0; y < 50; y++ {
+					for x := 0; x < 70; x++ {
+						parent.SetRGBA(x, y, background)
+					}
+				}
+				bounds := image.Rect(10, 10, 60, 40)
+				img := parent.SubImage(bounds).(*image.RGBA)
+
+				drawLine(img, line.x1, line.y1, line.x2, line.y2, red, width)
+
+				painted := 0
+				for y := 0; y < 50; y++ {
+					for x := 0; x < 70; x++ {
+						if parent.RGBAAt(x, y) == background {
+							continue
+						}
+						if !image.Pt(x, y).In(bounds) {
+							t.Fatalf("pixel (%d, %d) outside %v was painted", x, y, bounds)
+						}
+						painted++
+					}
+				}
+
+				// The midpoint of a line that crosses the image is painted
+				mid := image.Pt((line.x1+line.x2)/2, (line.y1+line.y2)/2)
+				if mid.In(bounds) && parent.RGBAAt(mid.X, mid.Y) != red {
+					t.Errorf("midpoint %v was not painted", mid)
+				}
+				if visible := painted > 0; visible != line.visible {
+					t.Errorf("%d pixels painted, want visible=%v", painted, line.visible)
+				}
+			})
+		}
+	}
+}
+
+func TestDrawLineWidth(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	for _, width := range []int{1, 2, 5, 12} {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+		drawLine(img, 5, 20, 35, 20, red, width)
+
+		thickness := 0
+		for y := 0; y < 40; y++ {
+			if img.RGBAAt(20, y) == red {
+				thickness++
+			}
+		}
+		// Pixels from -width/2 to width/2 around the line are painted
+		if want := 2*(width/2) + 1; thickness != want {
+			t.Errorf("width %d: line is %d pixels thick, want %d", width, thickness, want)
+		}
+	}
+}
+
+func TestDrawArrowNearCorner(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 30, 30))
+	for _, width := range []int{0, 1, maxAnnotationWidth} {
+		// The arrowhead extends past the top-left corner
+		drawArrow(img, Arrow{StartX: 25, StartY: 25, EndX: 1, EndY: 1, Color: color.RGBA{B: 255, A: 255}, Width: width})
+	}
+	if img.RGBAAt(1, 1) != (color.RGBA{B: 255, A: 255}) {
+		t.Error("arrow tip was not drawn")
+	}
+}
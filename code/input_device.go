@@ -0,0 +1,185 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/gordonklaus/portaudio"
+)
+
+// inputDevicePrefKey is the preferences key storing the name of the chosen microphone
+const inputDevicePrefKey = "inputDevice"
+
+// defaultInputDevice is the inputDevice value that records from the system default device
+const defaultInputDevice = -1
+
+// inputDeviceOption is a recording-capable device and its index in portaudio.Devices()
+type inputDeviceOption struct {
+	Index int
+	Info  *portaudio.DeviceInfo
+}
+
+// listInputDevices returns all devices that have at least one input channel
+func listInputDevices() ([]inputDeviceOption, error) {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audio devices: %v", err)
+	}
+
+	var inputs []inputDeviceOption
+	for i, device := range devices {
+		if device.MaxInputChannels > 0 {
+			inputs = append(inputs, inputDeviceOption{Index: i, Info: device})
+		}
+	}
+	return inputs, nil
+}
+
+// inputDeviceLabel formats a device for the selector, including its host API
+// since the same microphone is often listed once per API
+func inputDeviceLabel(device *portaudio.DeviceInfo) string {
+	if device.HostApi != nil {
+		return fmt.Sprintf("%s (%s)", device.Name, device.HostApi.Name)
+	}
+	return device.Name
+}
+
+// findInputDevice returns the index of the input device with the given selector label, if present
+func findInputDevice(label string) (int, bool) {
+	if label == "" {
+		return defaultInputDevice, false
+	}
+	inputs, err := listInputDevices()
+	if err != nil {
+		Warnf("%v", err)
+		return defaultInputDevice, false
+	}
+	for _, input := range inputs {
+		if inputDeviceLabel(input.Info) == label {
+			return input.Index, true
+		}
+	}
+	return defaultInputDevice, false
+}
+
+// selectedInputDevice returns the chosen input device, or nil to use the default.
+// The device list can change between selection and recording (e.g. an unplugged
+// headset), so the index is re-checked against the remembered label.
+func (a *AppState) selectedInputDevice() *portaudio.DeviceInfo {
+	if a.inputDevice == defaultInputDevice {
+		return nil
+	}
+
+	devices, err := portaudio.Devices()
+	if err == nil && a.inputDevice < len(devices) {
+		device := devices[a.inputDevice]
+		if device.MaxInputChannels > 0 && inputDeviceLabel(device) == a.inputDeviceLabel {
+			return device
+		}
+	}
+
+	if index, ok := findInputDevice(a.inputDeviceLabel); ok {
+		a.inputDevice = index
+		return a.selectedInputDevice()
+	}
+
+	Infof("Input device %q is no longer available, using the default device", a.inputDeviceLabel)
+	return nil
+}
+
+// openInputStream opens a 16-bit input stream with channels interleaved on the selected device,
+// falling back to the default device when none is selected or it is gone
+func (a *AppState) openInputStream(sampleRate float64, channels int, framesPerBuffer int) (*portaudio.Stream, error) {
+	device := a.selectedInputDevice()
+	if device == nil {
+		return portaudio.OpenDefaultStream(channels, 0, sampleRate, framesPerBuffer, a.audioCallback)
+	}
+
+	Infof("Recording from input device %q", inputDeviceLabel(device))
+	params := portaudio.StreamParameters{
+		Input: portaudio.StreamDeviceParameters{
+			Device:   device,
+			Channels: channels,
+			Latency:  device.DefaultLowInputLatency,
+		},
+		SampleRate:      sampleRate,
+		FramesPerBuffer: framesPerBuffer,
+	}
+	return portaudio.OpenStream(params, a.audioCallback)
+}
+
+// samplesPerSecond returns how many interleaved samples the current recording
+// adds to audioBuffer per second
+func (a *AppState) samplesPerSecond() int {
+	return int(a.sampleRate) * int(max(a.channels, 1))
+}
+
+// samplesDuration returns how long count interleaved samples of the current recording last
+func (a *AppState) samplesDuration(count int) time.Duration {
+	perSecond := a.samplesPerSecond()
+	if perSecond == 0 {
+		return 0
+	}
+	return time.Duration(count) * time.Second / time.Duration(perSecond)
+}
+
+// newInputDeviceSelect builds the microphone selector and restores the saved choice
+func (a *AppState) newInputDeviceSelect(prefs fyne.Preferences) *widget.Select {
+	const defaultOption = "Default microphone"
+
+	options := []string{defaultOption}
+	inputs, err := listInputDevices()
+	if err != nil {
+		Warnf("%v", err)
+	}
+	for _, input := range inputs {
+		options = append(options, inputDeviceLabel(input.Info))
+	}
+
+	a.inputDevice = defaultInputDevice
+	saved := prefs.String(inputDevicePrefKey)
+	if index, ok := findInputDevice(saved); ok {
+		a.inputDevice = index
+		a.inputDeviceLabel = saved
+	} else if saved != "" {
+		Warnf("Saved input device %q not found, using the default device", saved)
+	}
+
+	deviceSelect := widget.NewSelect(options, nil)
+	if a.inputDevice == defaultInputDevice {
+		deviceSelect.SetSelected(defaultOption)
+	} else {
+		deviceSelect.SetSelected(a.inputDeviceLabel)
+	}
+	deviceSelect.OnChanged = func(label string) {
+		if label == defaultOption {
+			a.inputDevice = defaultInputDevice
+			a.inputDeviceLabel = ""
+		} else if index, ok := findInputDevice(label); ok {
+			a.inputDevice = index
+			a.inputDeviceLabel = label
+		} else {
+			Infof("Input device %q disappeared, using the default device", label)
+			a.inputDevice = defaultInputDevice
+			a.inputDeviceLabel = ""
+		}
+		prefs.SetString(inputDevicePrefKey, a.inputDeviceLabel)
+		Infof("Input device set to %q (index %d)", label, a.inputDevice)
+	}
+	return deviceSelect
+}
@@ -0,0 +1,141 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// apiKeyCheckedPrefKey stores the fingerprint of the last key that passed
+// ValidateKey, so a known-good key isn't checked again on every start
+const apiKeyCheckedPrefKey = "apiKeyValidated"
+
+// keyValidationTimeout bounds the request that checks an API key
+const keyValidationTimeout = 10 * time.Second
+
+// errInvalidAPIKey is returned by ValidateKey when the API rejects the key
+var errInvalidAPIKey = errors.New("API key rejected")
+
+// ValidateKey checks the client's API key with a cheap authenticated request
+// (GET /models). It returns errInvalidAPIKey for a 401, an APIStatusError for
+// other failed statuses and the network error if the API can't be reached.
+func (c *OpenAiSpeechClient) ValidateKey(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return errInvalidAPIKey
+	case http.StatusNotFound:
+		// Some proxies don't list models; the key can't be checked, so accept it
+		Warnf("%s/models not available, API key not checked", c.baseURL)
+		return nil
+	default:
+		return &APIStatusError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("API request failed with status %d: %s", resp.StatusCode, string(body)),
+		}
+	}
+}
+
+// validateAPIKey checks key against the API at baseURL (empty for the official API)
+func validateAPIKey(ctx context.Context, baseURL string, key string) error {
+	baseURL, err := resolveBaseURL(baseURL)
+	if err != nil {
+		return err
+	}
+	client := &OpenAiSpeechClient{
+		apiKey:  key,
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: keyValidationTimeout,
+		},
+	}
+	return client.ValidateKey(ctx)
+}
+
+// describeKeyError explains a ValidateKey failure to the user, telling a wrong
+// key apart from a network problem
+func describeKeyError(err error) string {
+	var apiErr *APIStatusError
+	switch {
+	case errors.Is(err, errInvalidAPIKey):
+		return "The API rejected this key (401). Check that it is copied completely and still active."
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("The key could not be checked: %v", err)
+	default:
+		return fmt.Sprintf("Could not reach the API to check the key. Check your connection or OPENAI_BASE_URL: %v", err)
+	}
+}
+
+// apiKeyFingerprint identifies a key in the preferences without storing it again
+func apiKeyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// checkAPIKeyOnStart validates the current key in the background unless it
+// passed before. A rejected key disables recording and shows the banner;
+// network errors are only logged, since the key may well be fine.
+func (a *AppState) checkAPIKeyOnStart(prefs fyne.Preferences) {
+	key := os.Getenv("OPENAI_API_KEY")
+	if transcriber, _ := a.clients(); !a.config.usesOpenAI() || transcriber == nil || key == "" {
+		return
+	}
+	fingerprint := apiKeyFingerprint(key)
+	if prefs.String(apiKeyCheckedPrefKey) == fingerprint {
+		return
+	}
+
+	go func() {
+		err := validateAPIKey(a.ctx, a.config.OpenAIBaseURL, key)
+		switch {
+		case err == nil:
+			Infof("OpenAI API key verified")
+			prefs.SetString(apiKeyCheckedPrefKey, fingerprint)
+		case errors.Is(err, errInvalidAPIKey):
+			Errorf("OpenAI API key rejected, asking for a new one")
+			a.setClients(nil, nil)
+			fyne.Do(func() {
+				a.showAPIKeyBanner("Your OpenAI API key was rejected (401). Paste a valid key to enable transcription and correction:")
+			})
+		default:
+			Warnf("Could not verify the OpenAI API key: %v", err)
+		}
+	}()
+}
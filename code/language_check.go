@@ -0,0 +1,91 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"unicode"
+)
+
+// Writing scripts detected by the language heuristic
+const (
+	scriptCyrillic = "Cyrillic"
+	scriptLatin    = "Latin"
+)
+
+// languageScripts maps Whisper language codes to the script they are written in
+var languageScripts = map[string]string{
+	"ru": scriptCyrillic,
+	"uk": scriptCyrillic,
+	"be": scriptCyrillic,
+	"bg": scriptCyrillic,
+	"sr": scriptCyrillic,
+	"kk": scriptCyrillic,
+	"en": scriptLatin,
+	"de": scriptLatin,
+	"fr": scriptLatin,
+	"es": scriptLatin,
+	"it": scriptLatin,
+	"pt": scriptLatin,
+	"pl": scriptLatin,
+	"nl": scriptLatin,
+}
+
+// minLettersForLanguageCheck avoids false alarms on very short transcriptions
+const minLettersForLanguageCheck = 12
+
+// dominantScript returns the script used by most letters in text, or "" if
+// the text is too short or mixed to tell
+func dominantScript(text string) string {
+	var cyrillic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	total := cyrillic + latin
+	if total < minLettersForLanguageCheck {
+		return ""
+	}
+
+	// Require a clear majority so mixed text (e.g. Russian with English terms) passes
+	switch {
+	case cyrillic*4 >= total*3:
+		return scriptCyrillic
+	case latin*4 >= total*3:
+		return scriptLatin
+	default:
+		return ""
+	}
+}
+
+// checkLanguageMismatch reports whether text appears to be written in a different
+// script than the requested language. It returns the detected script name.
+// Auto-detect and languages without a known script never mismatch.
+func checkLanguageMismatch(text string, language string) (string, bool) {
+	expected, ok := languageScripts[language]
+	if !ok {
+		return "", false
+	}
+
+	detected := dominantScript(text)
+	if detected == "" {
+		return "", false
+	}
+
+	return detected, detected != expected
+}
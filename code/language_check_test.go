@@ -0,0 +1,69 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import "testing"
+
+func TestDominantScript(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"russian", "Привет, как у тебя дела сегодня?", scriptCyrillic},
+		{"english", "Hello, how are you doing today?", scriptLatin},
+		{"russian with english terms", "Запусти deploy на сервере после проверки логов", scriptCyrillic},
+		{"evenly mixed", "Привет мир hello world", ""},
+		{"too short", "Да, ok", ""},
+		{"digits and punctuation", "12345 67890 !!! ??? ...", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dominantScript(tt.text); got != tt.want {
+				t.Errorf("dominantScript(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLanguageMismatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		language     string
+		wantDetected string
+		wantMismatch bool
+	}{
+		{"english for russian", "This is an English sentence spoken by mistake.", "ru", scriptLatin, true},
+		{"russian for russian", "Это обычное русское предложение.", "ru", scriptCyrillic, false},
+		{"russian for english", "Это обычное русское предложение.", "en", scriptCyrillic, true},
+		{"english for english", "This is an English sentence.", "en", scriptLatin, false},
+		{"ukrainian shares script", "Це звичайне українське речення.", "uk", scriptCyrillic, false},
+		{"auto detect", "This is an English sentence.", "", "", false},
+		{"unknown language", "This is an English sentence.", "ja", "", false},
+		{"too short to tell", "OK", "ru", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detected, mismatch := checkLanguageMismatch(tt.text, tt.language)
+			if detected != tt.wantDetected || mismatch != tt.wantMismatch {
+				t.Errorf("checkLanguageMismatch(%q, %q) = (%q, %v), want (%q, %v)",
+					tt.text, tt.language, detected, mismatch, tt.wantDetected, tt.wantMismatch)
+			}
+		})
+	}
+}
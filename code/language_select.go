@@ -0,0 +1,84 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// languagePrefKey is the preferences key storing the last used transcription language
+const languagePrefKey = "language"
+
+// defaultLanguage is used until the user picks another language
+const defaultLanguage = "ru"
+
+// transcriptionLanguage is a Whisper language code and its name in the selector
+type transcriptionLanguage struct {
+	Code string
+	Name string
+}
+
+// transcriptionLanguages lists the languages offered in the UI. "auto" leaves the
+// language out of the Whisper request so it is detected from the audio.
+var transcriptionLanguages = []transcriptionLanguage{
+	{"auto", "Auto-detect"},
+	{"ru", "Russian"},
+	{"en", "English"},
+	{"uk", "Ukrainian"},
+	{"de", "German"},
+	{"fr", "French"},
+	{"es", "Spanish"},
+	{"it", "Italian"},
+	{"pt", "Portuguese"},
+	{"pl", "Polish"},
+}
+
+// languageName returns the selector name of a language code
+func languageName(code string) (string, bool) {
+	for _, lang := range transcriptionLanguages {
+		if lang.Code == code {
+			return lang.Name, true
+		}
+	}
+	return "", false
+}
+
+// newLanguageSelect builds the transcription language selector, restoring the
+// last used language and saving every change to prefs
+func (a *AppState) newLanguageSelect(prefs fyne.Preferences) *widget.Select {
+	names := make([]string, len(transcriptionLanguages))
+	codes := make(map[string]string, len(transcriptionLanguages))
+	for i, lang := range transcriptionLanguages {
+		names[i] = lang.Name
+		codes[lang.Name] = lang.Code
+	}
+
+	a.selectedLanguage = prefs.StringWithFallback(languagePrefKey, defaultLanguage)
+	selected, ok := languageName(a.selectedLanguage)
+	if !ok {
+		Infof("Saved language %q is not offered, using %q", a.selectedLanguage, defaultLanguage)
+		a.selectedLanguage = defaultLanguage
+		selected, _ = languageName(defaultLanguage)
+	}
+
+	languageSelect := widget.NewSelect(names, nil)
+	languageSelect.SetSelected(selected)
+	languageSelect.OnChanged = func(name string) {
+		a.selectedLanguage = codes[name]
+		prefs.SetString(languagePrefKey, a.selectedLanguage)
+		Infof("Transcription language set to %s", a.selectedLanguage)
+	}
+	return languageSelect
+}
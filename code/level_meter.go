@@ -0,0 +1,88 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/gordonklaus/portaudio"
+)
+
+// levelMeterInterval is how often the input level meter is redrawn
+const levelMeterInterval = 100 * time.Millisecond
+
+// bufferPeak returns the largest absolute sample value in samples
+func bufferPeak(samples []int16) int32 {
+	var peak int32
+	for _, sample := range samples {
+		v := int32(sample)
+		if v < 0 {
+			v = -v
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+	return peak
+}
+
+// recordPeak keeps the highest peak seen since the meter last read it.
+// It is called from the audio callback, so it only does a compare-and-swap.
+func (a *AppState) recordPeak(samples []int16) {
+	peak := bufferPeak(samples)
+	for {
+		current := a.inputPeak.Load()
+		if peak <= current || a.inputPeak.CompareAndSwap(current, peak) {
+			return
+		}
+	}
+}
+
+// newLevelMeter builds the input level meter shown while recording
+func newLevelMeter() *widget.ProgressBar {
+	meter := widget.NewProgressBar()
+	meter.Min = 0
+	meter.Max = 32768
+	meter.TextFormatter = func() string { return "" }
+	return meter
+}
+
+// runLevelMeter publishes the input peak to the level meter while the given
+// stream is recording, and resets the meter to zero once it stops
+func (a *AppState) runLevelMeter(stream *portaudio.Stream) {
+	if a.levelMeter == nil {
+		return
+	}
+	defer fyne.Do(func() { a.levelMeter.SetValue(0) })
+
+	ticker := time.NewTicker(levelMeterInterval)
+	defer ticker.Stop()
+
+	a.inputPeak.Store(0)
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !a.isCurrentRecording(stream) {
+			return
+		}
+		peak := float64(a.inputPeak.Swap(0))
+		fyne.Do(func() { a.levelMeter.SetValue(peak) })
+	}
+}
@@ -15,19 +15,29 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// defaultCorrectionModel is the chat model used when CORRECTION_MODEL is unset
+const defaultCorrectionModel = "gpt-4o-mini"
+
 // LLMClient handles communication with OpenAI's GPT API for text correction
 type LLMClient struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	Model   string // Chat model used by all correction requests
+	client  *http.Client
+
+	instructions atomic.Pointer[string] // Custom instructions sent as the system message, set from the Settings tab
 }
 
 // CorrectionRequest represents the request to OpenAI's chat completion API
@@ -52,10 +62,19 @@ type Message struct {
 
 // CorrectionResponse represents the response from OpenAI's chat completion API
 type CorrectionResponse struct {
+	Model   string    `json:"model"` // Model that answered, e.g. gpt-4o-mini-2024-07-18
 	Choices []Choice  `json:"choices"`
+	Usage   Usage     `json:"usage"`
 	Error   *APIError `json:"error,omitempty"`
 }
 
+// Usage reports the tokens consumed by a chat completion request
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
 // Choice represents a choice in the response
 type Choice struct {
 	Message Message `json:"message"`
@@ -73,6 +92,9 @@ type CorrectionJSON struct {
 	CorrectedText string   `json:"corrected_text"`
 	Changes       []Change `json:"changes"`
 	Confidence    float64  `json:"confidence"`
+
+	Model string `json:"-"` // Model that made the correction, for cost tracking
+	Usage Usage  `json:"-"` // Tokens the correction request used
 }
 
 // Change represents a specific change made to the text
@@ -83,25 +105,72 @@ type Change struct {
 	Description string `json:"description"`
 }
 
-// NewLLMClient creates a new LLM client for text correction
-func NewLLMClient() (*LLMClient, error) {
+// NewLLMClient creates a new LLM client for text correction.
+// baseURL is the API root (e.g. a proxy); empty uses the official API.
+// model selects the chat model; empty uses gpt-4o-mini.
+func NewLLMClient(baseURL string, model string) (*LLMClient, error) {
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
 	}
 
-	return &LLMClient{
-		apiKey: apiKey,
+	baseURL, err := resolveBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if model == "" {
+		model = defaultCorrectionModel
+	}
+
+	c := &LLMClient{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		Model:   model,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-	}, nil
+	}
+	c.SetInstructions(defaultCorrectionInstructions)
+	return c, nil
 }
 
-// CorrectText sends transcribed text to OpenAI's GPT API for correction and improvement
-func (c *LLMClient) CorrectText(transcribedText string) (string, error) {
-	// Create the correction prompt with JSON format specification
-	prompt := fmt.Sprintf(`Please correct and improve the following transcribed text. Fix any grammar errors, punctuation, capitalization, and make it more readable while preserving the original meaning.
+// SetInstructions sets the custom instructions sent as the system message. It
+// may be called while corrections are running.
+func (c *LLMClient) SetInstructions(instructions string) {
+	c.instructions.Store(&instructions)
+}
+
+// Instructions returns the custom instructions sent as the system message
+func (c *LLMClient) Instructions() string {
+	if instructions := c.instructions.Load(); instructions != nil {
+		return *instructions
+	}
+	return ""
+}
+
+// maxCorrectionContext is the most characters of preceding text sent as context
+// with an "add" mode correction
+const maxCorrectionContext = 1000
+
+// trimCorrectionContext returns the last maxChars characters of text, starting at
+// a word boundary, for use as correction context
+func trimCorrectionContext(text string, maxChars int) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	tail := string(runes[len(runes)-maxChars:])
+	if i := strings.IndexAny(tail, " \n\t"); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return strings.TrimSpace(tail)
+}
+
+// correctionPrompt asks for a correction of transcribedText as CorrectionJSON
+func correctionPrompt(transcribedText string) string {
+	return fmt.Sprintf(`Please correct and improve the following transcribed text. Fix any grammar errors, punctuation, capitalization, and make it more readable while preserving the original meaning.
 
 Return your response in the following JSON format:
 {
@@ -119,107 +188,12 @@ Return your response in the following JSON format:
 }
 
 Original text: "%s"`, transcribedText)
-
-	// Create the request with JSON response format
-	request := CorrectionRequest{
-		Model: "gpt-3.5-turbo",
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		MaxTokens:   1000,
-		Temperature: 0.3, // Lower temperature for more consistent corrections
-		ResponseFormat: ResponseFormat{
-			Type: "json_object",
-		},
-	}
-
-	// Convert to JSON
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %v", err)
-	}
-
-	// Create HTTP request
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	// Handle HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		switch resp.StatusCode {
-		case http.StatusUnauthorized:
-			return "", fmt.Errorf("unauthorized: check your OpenAI API key")
-		case http.StatusTooManyRequests:
-			return "", fmt.Errorf("rate limit exceeded: please try again later")
-		case http.StatusBadRequest:
-			return "", fmt.Errorf("bad request: %s", string(body))
-		default:
-			return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-		}
-	}
-
-	// Parse JSON response
-	var correctionResp CorrectionResponse
-	err = json.Unmarshal(body, &correctionResp)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse response JSON: %v", err)
-	}
-
-	// Check for API errors
-	if correctionResp.Error != nil {
-		return "", fmt.Errorf("API error: %s", correctionResp.Error.Message)
-	}
-
-	// Extract corrected text
-	if len(correctionResp.Choices) == 0 {
-		return "", fmt.Errorf("no response choices received")
-	}
-
-	// Parse the JSON content from the response
-	var correctionJSON CorrectionJSON
-	err = json.Unmarshal([]byte(correctionResp.Choices[0].Message.Content), &correctionJSON)
-	if err != nil {
-		// Fallback: if JSON parsing fails, return the raw content
-		log.Printf("Failed to parse correction JSON, using raw content: %v", err)
-		return correctionResp.Choices[0].Message.Content, nil
-	}
-
-	// Log the changes made for debugging
-	if len(correctionJSON.Changes) > 0 {
-		log.Printf("Applied %d corrections with confidence %.2f", len(correctionJSON.Changes), correctionJSON.Confidence)
-		for _, change := range correctionJSON.Changes {
-			log.Printf("  %s: '%s' -> '%s' (%s)", change.Type, change.Original, change.Corrected, change.Description)
-		}
-	}
-
-	return correctionJSON.CorrectedText, nil
 }
 
-// CorrectTextWithContext sends transcribed text with context for better correction
-func (c *LLMClient) CorrectTextWithContext(transcribedText string, context string) (string, error) {
-	// Create the correction prompt with context and JSON format specification
-	prompt := fmt.Sprintf(`Please correct and improve the following transcribed text. Use the provided context to better understand the intended meaning. Fix any grammar errors, punctuation, capitalization, and make it more readable while preserving the original meaning.
+// correctionPromptWithContext is correctionPrompt with preceding text that helps
+// the model keep names, terminology and tense consistent
+func correctionPromptWithContext(transcribedText string, textContext string) string {
+	return fmt.Sprintf(`Please correct and improve the following transcribed text. Use the provided context to better understand the intended meaning. Fix any grammar errors, punctuation, capitalization, and make it more readable while preserving the original meaning.
 
 Return your response in the following JSON format:
 {
@@ -238,137 +212,80 @@ Return your response in the following JSON format:
 
 Context: %s
 
-Original text: "%s"`, context, transcribedText)
-
-	// Create the request with JSON response format
-	request := CorrectionRequest{
-		Model: "gpt-3.5-turbo",
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		MaxTokens:   1000,
-		Temperature: 0.3,
-		ResponseFormat: ResponseFormat{
-			Type: "json_object",
-		},
-	}
-
-	// Convert to JSON
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %v", err)
-	}
+Original text: "%s"`, textContext, transcribedText)
+}
 
-	// Create HTTP request
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %v", err)
-	}
+// CorrectText sends transcribed text to OpenAI's GPT API for correction and improvement
+func (c *LLMClient) CorrectText(ctx context.Context, transcribedText string) (string, error) {
+	return c.correctedText(c.doCorrection(ctx, correctionPrompt(transcribedText)))
+}
 
-	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+// CorrectTextWithContext sends transcribed text with context for better correction
+func (c *LLMClient) CorrectTextWithContext(ctx context.Context, transcribedText string, textContext string) (string, error) {
+	return c.correctedText(c.doCorrection(ctx, correctionPromptWithContext(transcribedText, textContext)))
+}
 
-	// Send request
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
+// CorrectTextDetailed returns the full JSON correction response with detailed changes
+func (c *LLMClient) CorrectTextDetailed(ctx context.Context, transcribedText string) (*CorrectionJSON, error) {
+	return c.doCorrection(ctx, correctionPrompt(transcribedText))
+}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
-	}
+// CorrectTextDetailedWithContext is CorrectTextDetailed with the text preceding
+// transcribedText as context
+func (c *LLMClient) CorrectTextDetailedWithContext(ctx context.Context, transcribedText string, textContext string) (*CorrectionJSON, error) {
+	return c.doCorrection(ctx, correctionPromptWithContext(transcribedText, textContext))
+}
 
-	// Handle HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		switch resp.StatusCode {
-		case http.StatusUnauthorized:
-			return "", fmt.Errorf("unauthorized: check your OpenAI API key")
-		case http.StatusTooManyRequests:
-			return "", fmt.Errorf("rate limit exceeded: please try again later")
-		case http.StatusBadRequest:
-			return "", fmt.Errorf("bad request: %s", string(body))
-		default:
-			return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-		}
-	}
+// correctionContentError reports a model answer that isn't valid CorrectionJSON,
+// keeping the raw content so plain-text callers can still use it
+type correctionContentError struct {
+	content string
+	err     error
+}
 
-	// Parse JSON response
-	var correctionResp CorrectionResponse
-	err = json.Unmarshal(body, &correctionResp)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse response JSON: %v", err)
-	}
+func (e *correctionContentError) Error() string {
+	return fmt.Sprintf("failed to parse correction JSON: %v", e.err)
+}
 
-	// Check for API errors
-	if correctionResp.Error != nil {
-		return "", fmt.Errorf("API error: %s", correctionResp.Error.Message)
-	}
+func (e *correctionContentError) Unwrap() error {
+	return e.err
+}
 
-	// Extract corrected text
-	if len(correctionResp.Choices) == 0 {
-		return "", fmt.Errorf("no response choices received")
+// correctedText adapts a doCorrection result to the plain-text API, logging the
+// changes and falling back to the raw content when the answer isn't valid JSON
+func (c *LLMClient) correctedText(correction *CorrectionJSON, err error) (string, error) {
+	var contentErr *correctionContentError
+	if errors.As(err, &contentErr) {
+		Warnf("Failed to parse correction JSON, using raw content: %v", contentErr.err)
+		return contentErr.content, nil
 	}
-
-	// Parse the JSON content from the response
-	var correctionJSON CorrectionJSON
-	err = json.Unmarshal([]byte(correctionResp.Choices[0].Message.Content), &correctionJSON)
 	if err != nil {
-		// Fallback: if JSON parsing fails, return the raw content
-		log.Printf("Failed to parse correction JSON, using raw content: %v", err)
-		return correctionResp.Choices[0].Message.Content, nil
+		return "", err
 	}
 
 	// Log the changes made for debugging
-	if len(correctionJSON.Changes) > 0 {
-		log.Printf("Applied %d corrections with confidence %.2f", len(correctionJSON.Changes), correctionJSON.Confidence)
-		for _, change := range correctionJSON.Changes {
-			log.Printf("  %s: '%s' -> '%s' (%s)", change.Type, change.Original, change.Corrected, change.Description)
+	if len(correction.Changes) > 0 {
+		Infof("Applied %d corrections with confidence %.2f", len(correction.Changes), correction.Confidence)
+		for _, change := range correction.Changes {
+			Debugf("  %s: '%s' -> '%s' (%s)", change.Type, change.Original, change.Corrected, change.Description)
 		}
 	}
 
-	return correctionJSON.CorrectedText, nil
-}
-
-// CorrectTextDetailed returns the full JSON correction response with detailed changes
-func (c *LLMClient) CorrectTextDetailed(transcribedText string) (*CorrectionJSON, error) {
-	// Create the correction prompt with JSON format specification
-	prompt := fmt.Sprintf(`Please correct and improve the following transcribed text. Fix any grammar errors, punctuation, capitalization, and make it more readable while preserving the original meaning.
-
-Return your response in the following JSON format:
-{
-  "original_text": "the original transcribed text",
-  "corrected_text": "the corrected and improved text",
-  "changes": [
-    {
-      "type": "grammar|punctuation|capitalization|clarity",
-      "original": "original phrase",
-      "corrected": "corrected phrase",
-      "description": "brief description of the change"
-    }
-  ],
-  "confidence": 0.95
+	return correction.CorrectedText, nil
 }
 
-Original text: "%s"`, transcribedText)
+// doCorrection sends a correction prompt and parses the CorrectionJSON answer.
+// It is the single code path for building the request, handling the HTTP
+// status and decoding the response of every correction method
+func (c *LLMClient) doCorrection(ctx context.Context, prompt string) (*CorrectionJSON, error) {
+	started := time.Now()
 
 	// Create the request with JSON response format
 	request := CorrectionRequest{
-		Model: "gpt-3.5-turbo",
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+		Model:       c.Model,
+		Messages:    c.correctionMessages(prompt),
 		MaxTokens:   1000,
-		Temperature: 0.3,
+		Temperature: 0.3, // Lower temperature for more consistent corrections
 		ResponseFormat: ResponseFormat{
 			Type: "json_object",
 		},
@@ -381,19 +298,21 @@ Original text: "%s"`, transcribedText)
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
 
-	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	// Set headers; local servers may not need a key
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send request
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -433,13 +352,20 @@ Original text: "%s"`, transcribedText)
 	if len(correctionResp.Choices) == 0 {
 		return nil, fmt.Errorf("no response choices received")
 	}
+	GetLogger().LogLLMEvent("correction", c.Model, correctionResp.Usage.TotalTokens, time.Since(started))
 
 	// Parse the JSON content from the response
+	content := correctionResp.Choices[0].Message.Content
 	var correctionJSON CorrectionJSON
-	err = json.Unmarshal([]byte(correctionResp.Choices[0].Message.Content), &correctionJSON)
+	err = json.Unmarshal([]byte(content), &correctionJSON)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse correction JSON: %v", err)
+		return nil, &correctionContentError{content: content, err: err}
 	}
 
+	correctionJSON.Model = correctionResp.Model
+	if correctionJSON.Model == "" {
+		correctionJSON.Model = c.Model
+	}
+	correctionJSON.Usage = correctionResp.Usage
 	return &correctionJSON, nil
 }
@@ -0,0 +1,288 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// chatServer answers chat completion requests with answer as the message
+// content and records the decoded requests. A non-zero status is returned
+// as an error response instead.
+type chatServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []CorrectionRequest
+	status   int
+}
+
+func newChatServer(t *testing.T, answer string) *chatServer {
+	t.Helper()
+	s := &chatServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var request CorrectionRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, request)
+		status := s.status
+		s.mu.Unlock()
+
+		if status != 0 {
+			http.Error(w, `{"error":{"message":"rejected"}}`, status)
+			return
+		}
+		json.NewEncoder(w).Encode(CorrectionResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: answer}}},
+			Usage:   Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// lastRequest returns the most recent request the server received
+func (s *chatServer) lastRequest(t *testing.T) CorrectionRequest {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		t.Fatal("no chat completion request was sent")
+	}
+	return s.requests[len(s.requests)-1]
+}
+
+func TestCorrectionRequestModel(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	tests := []struct {
+		name  string
+		model string
+		want  string
+	}{
+		{"default", "", defaultCorrectionModel},
+		{"configured", "gpt-4o", "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newChatServer(t, `{"corrected_text":"Fixed."}`)
+			client, err := NewLLMClient(server.URL, tt.model)
+			if err != nil {
+				t.Fatalf("NewLLMClient: %v", err)
+			}
+			if _, err := client.CorrectText(context.Background(), "fixed"); err != nil {
+				t.Fatalf("CorrectText: %v", err)
+			}
+
+			// Check the marshalled field name as well as the value
+			data, err := json.Marshal(server.lastRequest(t))
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			if fields["model"] != tt.want {
+				t.Errorf("request model = %v, want %q", fields["model"], tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigCorrectionModel(t *testing.T) {
+	t.Setenv("CORRECTION_MODEL", "")
+	if got := LoadConfig().CorrectionModel; got != defaultCorrectionModel {
+		t.Errorf("default CorrectionModel = %q, want %q", got, defaultCorrectionModel)
+	}
+
+	t.Setenv("CORRECTION_MODEL", "gpt-4.1-mini")
+	if got := LoadConfig().CorrectionModel; got != "gpt-4.1-mini" {
+		t.Errorf("CorrectionModel = %q, want %q", got, "gpt-4.1-mini")
+	}
+}
+
+// correctionAnswer is a well-formed CorrectionJSON answer
+const correctionAnswer = `{
+	"original_text": "helo world",
+	"corrected_text": "Hello, world.",
+	"changes": [{"type": "grammar", "original": "helo", "corrected": "Hello", "description": "spelling"}],
+	"confidence": 0.9
+}`
+
+// newTestLLMClient returns a client talking to server with the default instructions
+func newTestLLMClient(t *testing.T, server *chatServer) *LLMClient {
+	t.Helper()
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	client, err := NewLLMClient(server.URL, "gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("NewLLMClient: %v", err)
+	}
+	return client
+}
+
+// checkCorrectionRequest verifies the request every correction method sends
+func checkCorrectionRequest(t *testing.T, request CorrectionRequest, text string, textContext string) {
+	t.Helper()
+	if request.Model != "gpt-4o-mini" || request.MaxTokens != 1000 || request.Temperature != 0.3 ||
+		request.ResponseFormat.Type != "json_object" {
+		t.Errorf("request settings = %+v", request)
+	}
+	if len(request.Messages) != 2 || request.Messages[0].Role != "system" || request.Messages[1].Role != "user" {
+		t.Fatalf("messages = %+v, want system instructions and the user prompt", request.Messages)
+	}
+	prompt := request.Messages[1].Content
+	if !strings.Contains(prompt, `Original text: "`+text+`"`) {
+		t.Errorf("prompt does not contain the text: %q", prompt)
+	}
+	if hasContext := strings.Contains(prompt, "Context: "); hasContext != (textContext != "") {
+		t.Errorf("prompt context present = %v, want %v", hasContext, textContext != "")
+	}
+	if textContext != "" && !strings.Contains(prompt, "Context: "+textContext) {
+		t.Errorf("prompt does not contain the context: %q", prompt)
+	}
+}
+
+func TestCorrectText(t *testing.T) {
+	server := newChatServer(t, correctionAnswer)
+	client := newTestLLMClient(t, server)
+
+	corrected, err := client.CorrectText(context.Background(), "helo world")
+	if err != nil {
+		t.Fatalf("CorrectText: %v", err)
+	}
+	if corrected != "Hello, world." {
+		t.Errorf("CorrectText = %q, want %q", corrected, "Hello, world.")
+	}
+	checkCorrectionRequest(t, server.lastRequest(t), "helo world", "")
+}
+
+func TestCorrectTextWithContext(t *testing.T) {
+	server := newChatServer(t, correctionAnswer)
+	client := newTestLLMClient(t, server)
+
+	corrected, err := client.CorrectTextWithContext(context.Background(), "helo world", "Greeting the audience.")
+	if err != nil {
+		t.Fatalf("CorrectTextWithContext: %v", err)
+	}
+	if corrected != "Hello, world." {
+		t.Errorf("CorrectTextWithContext = %q, want %q", corrected, "Hello, world.")
+	}
+	checkCorrectionRequest(t, server.lastRequest(t), "helo world", "Greeting the audience.")
+}
+
+func TestCorrectTextDetailed(t *testing.T) {
+	server := newChatServer(t, correctionAnswer)
+	client := newTestLLMClient(t, server)
+
+	correction, err := client.CorrectTextDetailed(context.Background(), "helo world")
+	if err != nil {
+		t.Fatalf("CorrectTextDetailed: %v", err)
+	}
+	if correction.CorrectedText != "Hello, world." || correction.OriginalText != "helo world" ||
+		correction.Confidence != 0.9 || len(correction.Changes) != 1 || correction.Changes[0].Corrected != "Hello" {
+		t.Errorf("CorrectTextDetailed = %+v", correction)
+	}
+	// The server did not name its model, so the requested one is reported
+	if correction.Model != "gpt-4o-mini" || correction.Usage.TotalTokens != 15 {
+		t.Errorf("model = %q, usage = %+v", correction.Model, correction.Usage)
+	}
+	checkCorrectionRequest(t, server.lastRequest(t), "helo world", "")
+}
+
+func TestCorrectTextDetailedWithContext(t *testing.T) {
+	server := newChatServer(t, correctionAnswer)
+	client := newTestLLMClient(t, server)
+
+	correction, err := client.CorrectTextDetailedWithContext(context.Background(), "helo world", "Greeting the audience.")
+	if err != nil {
+		t.Fatalf("CorrectTextDetailedWithContext: %v", err)
+	}
+	if correction.CorrectedText != "Hello, world." || len(correction.Changes) != 1 {
+		t.Errorf("CorrectTextDetailedWithContext = %+v", correction)
+	}
+	checkCorrectionRequest(t, server.lastRequest(t), "helo world", "Greeting the audience.")
+}
+
+func TestCorrectTextNonJSONAnswer(t *testing.T) {
+	server := newChatServer(t, "Hello, world.")
+	client := newTestLLMClient(t, server)
+
+	// The plain-text methods fall back to the raw answer
+	for name, correct := range map[string]func() (string, error){
+		"CorrectText": func() (string, error) { return client.CorrectText(context.Background(), "helo world") },
+		"CorrectTextWithContext": func() (string, error) {
+			return client.CorrectTextWithContext(context.Background(), "helo world", "context")
+		},
+	} {
+		if corrected, err := correct(); err != nil || corrected != "Hello, world." {
+			t.Errorf("%s = (%q, %v), want the raw answer", name, corrected, err)
+		}
+	}
+
+	// The detailed methods report the unparseable answer
+	if _, err := client.CorrectTextDetailed(context.Background(), "helo world"); err == nil {
+		t.Error("CorrectTextDetailed accepted a non-JSON answer")
+	}
+	if _, err := client.CorrectTextDetailedWithContext(context.Background(), "helo world", "context"); err == nil {
+		t.Error("CorrectTextDetailedWithContext accepted a non-JSON answer")
+	}
+}
+
+func TestCorrectTextHTTPErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusUnauthorized, "unauthorized"},
+		{http.StatusTooManyRequests, "rate limit exceeded"},
+		{http.StatusBadRequest, "bad request"},
+		{http.StatusInternalServerError, "status 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := newChatServer(t, correctionAnswer)
+			server.mu.Lock()
+			server.status = tt.status
+			server.mu.Unlock()
+			client := newTestLLMClient(t, server)
+			ctx := context.Background()
+
+			errs := map[string]error{}
+			_, errs["CorrectText"] = client.CorrectText(ctx, "text")
+			_, errs["CorrectTextWithContext"] = client.CorrectTextWithContext(ctx, "text", "context")
+			_, errs["CorrectTextDetailed"] = client.CorrectTextDetailed(ctx, "text")
+			_, errs["CorrectTextDetailedWithContext"] = client.CorrectTextDetailedWithContext(ctx, "text", "context")
+			for name, err := range errs {
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("%s error = %v, want one mentioning %q", name, err, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// logLevelPrefKey is the preferences key storing the log level picked in the settings tab
+const logLevelPrefKey = "logLevel"
+
+// selectableLogLevels are the levels offered in the settings tab
+var selectableLogLevels = []LogLevel{DEBUG, INFO, WARN, ERROR}
+
+// newLogLevelSelect builds the log level selector. The saved choice wins over
+// MICAPP_LOG_LEVEL, which only provides the default, and is applied right away.
+func (a *AppState) newLogLevelSelect(prefs fyne.Preferences) *widget.Select {
+	names := make([]string, len(selectableLogLevels))
+	for i, level := range selectableLogLevels {
+		names[i] = level.String()
+	}
+
+	saved := prefs.StringWithFallback(logLevelPrefKey, a.config.LogLevel.String())
+	level, err := ParseLogLevel(saved)
+	if err != nil {
+		Warnf("Saved log level %q is invalid, using %s", saved, a.config.LogLevel)
+		level = a.config.LogLevel
+	}
+	if level != GetLogger().GetLevel() {
+		GetLogger().SetLevel(level)
+	}
+
+	levelSelect := widget.NewSelect(names, nil)
+	levelSelect.SetSelected(level.String())
+	levelSelect.OnChanged = func(name string) {
+		level, err := ParseLogLevel(name)
+		if err != nil {
+			return
+		}
+		GetLogger().SetLevel(level)
+		prefs.SetString(logLevelPrefKey, level.String())
+	}
+	return levelSelect
+}
@@ -14,6 +14,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -21,6 +22,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -53,22 +55,71 @@ func (l LogLevel) String() string {
 	}
 }
 
+// ParseLogLevel converts a level name (case-insensitive) to a LogLevel
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN", "WARNING":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	case "FATAL":
+		return FATAL, nil
+	default:
+		return INFO, fmt.Errorf("unknown log level: %q", name)
+	}
+}
+
+// LogFormat selects how AppLogger renders log lines
+type LogFormat int
+
+const (
+	LogFormatText LogFormat = iota // Bracketed human-readable lines
+	LogFormatJSON                  // One JSON object per line
+)
+
+// ParseLogFormat converts a format name ("text" or "json", case-insensitive) to a LogFormat
+func ParseLogFormat(name string) (LogFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "text":
+		return LogFormatText, nil
+	case "json":
+		return LogFormatJSON, nil
+	default:
+		return LogFormatText, fmt.Errorf("unknown log format: %q", name)
+	}
+}
+
 // AppLogger represents the application logger
 type AppLogger struct {
-	level    LogLevel
+	level    atomic.Int32 // LogLevel, changed at runtime from the settings tab
+	format   LogFormat
 	logger   *log.Logger
 	file     *os.File
 	filePath string
 }
 
-// NewAppLogger creates a new application logger
-func NewAppLogger(level LogLevel) (*AppLogger, error) {
-	// Use single app.log file in root directory
+// jsonLogEntry is a log line in LogFormatJSON
+type jsonLogEntry struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	File      string                 `json:"file"`
+	Line      int                    `json:"line"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// NewAppLogger creates a new application logger writing lines in the given format
+func NewAppLogger(level LogLevel, format LogFormat) (*AppLogger, error) {
+	// Use single app.log file in root directory, truncated on each start
 	logFileName := "app.log"
 	filePath := logFileName
 
 	// Open log file
-	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %v", err)
 	}
@@ -80,11 +131,12 @@ func NewAppLogger(level LogLevel) (*AppLogger, error) {
 	logger := log.New(multiWriter, "", 0)
 
 	appLogger := &AppLogger{
-		level:    level,
+		format:   format,
 		logger:   logger,
 		file:     file,
 		filePath: filePath,
 	}
+	appLogger.level.Store(int32(level))
 
 	// Log initial message
 	appLogger.Info("Logger initialized", "level", level.String(), "file", filePath)
@@ -101,15 +153,16 @@ func (l *AppLogger) Close() error {
 	return nil
 }
 
-// SetLevel sets the logging level
+// SetLevel sets the logging level. It is safe to call while other goroutines log,
+// and applies to every message logged after it returns.
 func (l *AppLogger) SetLevel(level LogLevel) {
-	l.level = level
+	l.level.Store(int32(level))
 	l.Info("Log level changed", "new_level", level.String())
 }
 
 // GetLevel returns the current logging level
 func (l *AppLogger) GetLevel() LogLevel {
-	return l.level
+	return LogLevel(l.level.Load())
 }
 
 // GetFilePath returns the log file path
@@ -117,14 +170,23 @@ func (l *AppLogger) GetFilePath() string {
 	return l.filePath
 }
 
-// log writes a log message with the specified level
-func (l *AppLogger) log(level LogLevel, message string, fields ...interface{}) {
-	if level < l.level {
+// Write implements io.Writer so the standard log package can be redirected to the
+// logger with log.SetOutput and log.SetFlags(0). Each write is logged at INFO.
+func (l *AppLogger) Write(p []byte) (int, error) {
+	// Frames: log, Write, (*log.Logger).output, log.Printf and friends, caller
+	l.log(INFO, 4, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// log writes a log message with the specified level. depth is the number of
+// stack frames between the caller being reported and log itself.
+func (l *AppLogger) log(level LogLevel, depth int, message string, fields ...interface{}) {
+	if level < l.GetLevel() {
 		return
 	}
 
 	// Get caller information
-	_, file, line, ok := runtime.Caller(2)
+	_, file, line, ok := runtime.Caller(depth)
 	if !ok {
 		file = "unknown"
 		line = 0
@@ -134,104 +196,149 @@ func (l *AppLogger) log(level LogLevel, message string, fields ...interface{}) {
 	fileName := filepath.Base(file)
 
 	// Format timestamp
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
+	now := time.Now()
+
+	// Create and write log message
+	if l.format == LogFormatJSON {
+		l.logger.Println(formatJSONLogLine(now, level, fileName, line, message, fields))
+	} else {
+		l.logger.Println(formatTextLogLine(now, level, fileName, line, message, fields))
+	}
+
+	// For FATAL level, also exit the program
+	if level == FATAL {
+		os.Exit(1)
+	}
+}
 
-	// Format fields
+// formatTextLogLine renders a log line as "[time] [LEVEL] [file:line] message key=value ..."
+func formatTextLogLine(now time.Time, level LogLevel, file string, line int, message string, fields []interface{}) string {
 	var fieldStr string
 	if len(fields) > 0 {
 		var parts []string
-		for i := 0; i < len(fields); i += 2 {
-			if i+1 < len(fields) {
-				parts = append(parts, fmt.Sprintf("%v=%v", fields[i], fields[i+1]))
-			}
+		for i := 0; i+1 < len(fields); i += 2 {
+			parts = append(parts, fmt.Sprintf("%v=%v", fields[i], fields[i+1]))
 		}
 		if len(parts) > 0 {
 			fieldStr = " " + strings.Join(parts, " ")
 		}
 	}
 
-	// Create log message
-	logMessage := fmt.Sprintf("[%s] [%s] [%s:%d] %s%s",
-		timestamp,
+	return fmt.Sprintf("[%s] [%s] [%s:%d] %s%s",
+		now.Format("2006-01-02 15:04:05.000"),
 		level.String(),
-		fileName,
+		file,
 		line,
 		message,
 		fieldStr,
 	)
+}
 
-	// Write log message
-	l.logger.Println(logMessage)
+// formatJSONLogLine renders a log line as a JSON object; the key/value pairs in
+// fields become the nested "fields" object. A key without a value is dropped,
+// as in the text format.
+func formatJSONLogLine(now time.Time, level LogLevel, file string, line int, message string, fields []interface{}) string {
+	entry := jsonLogEntry{
+		Timestamp: now.Format(time.RFC3339Nano),
+		Level:     level.String(),
+		File:      file,
+		Line:      line,
+		Message:   message,
+	}
+	if len(fields) >= 2 {
+		entry.Fields = make(map[string]interface{}, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			entry.Fields[fmt.Sprint(fields[i])] = jsonFieldValue(fields[i+1])
+		}
+	}
 
-	// For FATAL level, also exit the program
-	if level == FATAL {
-		os.Exit(1)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		// A value JSON can't represent; fall back to its printed form
+		for key, value := range entry.Fields {
+			entry.Fields[key] = fmt.Sprint(value)
+		}
+		data, _ = json.Marshal(entry)
+	}
+	return string(data)
+}
+
+// jsonFieldValue converts values that would marshal uselessly (errors become {},
+// durations a nanosecond count) to their string form
+func jsonFieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return value
 	}
 }
 
 // Debug logs a debug message
 func (l *AppLogger) Debug(message string, fields ...interface{}) {
-	l.log(DEBUG, message, fields...)
+	l.log(DEBUG, 2, message, fields...)
 }
 
 // Info logs an info message
 func (l *AppLogger) Info(message string, fields ...interface{}) {
-	l.log(INFO, message, fields...)
+	l.log(INFO, 2, message, fields...)
 }
 
 // Warn logs a warning message
 func (l *AppLogger) Warn(message string, fields ...interface{}) {
-	l.log(WARN, message, fields...)
+	l.log(WARN, 2, message, fields...)
 }
 
 // Error logs an error message
 func (l *AppLogger) Error(message string, fields ...interface{}) {
-	l.log(ERROR, message, fields...)
+	l.log(ERROR, 2, message, fields...)
 }
 
 // Fatal logs a fatal message and exits the program
 func (l *AppLogger) Fatal(message string, fields ...interface{}) {
-	l.log(FATAL, message, fields...)
+	l.log(FATAL, 2, message, fields...)
 }
 
 // Debugf logs a formatted debug message
 func (l *AppLogger) Debugf(format string, args ...interface{}) {
-	if DEBUG >= l.level {
-		l.Debug(fmt.Sprintf(format, args...))
+	if DEBUG >= l.GetLevel() {
+		l.log(DEBUG, 2, fmt.Sprintf(format, args...))
 	}
 }
 
 // Infof logs a formatted info message
 func (l *AppLogger) Infof(format string, args ...interface{}) {
-	if INFO >= l.level {
-		l.Info(fmt.Sprintf(format, args...))
+	if INFO >= l.GetLevel() {
+		l.log(INFO, 2, fmt.Sprintf(format, args...))
 	}
 }
 
 // Warnf logs a formatted warning message
 func (l *AppLogger) Warnf(format string, args ...interface{}) {
-	if WARN >= l.level {
-		l.Warn(fmt.Sprintf(format, args...))
+	if WARN >= l.GetLevel() {
+		l.log(WARN, 2, fmt.Sprintf(format, args...))
 	}
 }
 
 // Errorf logs a formatted error message
 func (l *AppLogger) Errorf(format string, args ...interface{}) {
-	if ERROR >= l.level {
-		l.Error(fmt.Sprintf(format, args...))
+	if ERROR >= l.GetLevel() {
+		l.log(ERROR, 2, fmt.Sprintf(format, args...))
 	}
 }
 
 // Fatalf logs a formatted fatal message and exits the program
 func (l *AppLogger) Fatalf(format string, args ...interface{}) {
-	if FATAL >= l.level {
-		l.Fatal(fmt.Sprintf(format, args...))
+	if FATAL >= l.GetLevel() {
+		l.log(FATAL, 2, fmt.Sprintf(format, args...))
 	}
 }
 
 // LogAudioEvent logs audio-related events
 func (l *AppLogger) LogAudioEvent(event string, duration time.Duration, sampleRate int, channels int) {
-	l.Info("Audio event",
+	l.log(INFO, 2, "Audio event",
 		"event", event,
 		"duration", duration.String(),
 		"sample_rate", sampleRate,
@@ -239,9 +346,19 @@ func (l *AppLogger) LogAudioEvent(event string, duration time.Duration, sampleRa
 	)
 }
 
+// LogAudioLevel logs microphone input level statistics at DEBUG level
+func (l *AppLogger) LogAudioLevel(minSample int16, maxSample int16, rms float64, samples int) {
+	l.log(DEBUG, 2, "Audio level",
+		"min", minSample,
+		"max", maxSample,
+		"rms", fmt.Sprintf("%.1f", rms),
+		"samples", samples,
+	)
+}
+
 // LogTranscriptionEvent logs transcription-related events
 func (l *AppLogger) LogTranscriptionEvent(event string, language string, textLength int, processingTime time.Duration) {
-	l.Info("Transcription event",
+	l.log(INFO, 2, "Transcription event",
 		"event", event,
 		"language", language,
 		"text_length", textLength,
@@ -251,7 +368,7 @@ func (l *AppLogger) LogTranscriptionEvent(event string, language string, textLen
 
 // LogLLMEvent logs LLM-related events
 func (l *AppLogger) LogLLMEvent(event string, model string, tokens int, processingTime time.Duration) {
-	l.Info("LLM event",
+	l.log(INFO, 2, "LLM event",
 		"event", event,
 		"model", model,
 		"tokens", tokens,
@@ -261,7 +378,7 @@ func (l *AppLogger) LogLLMEvent(event string, model string, tokens int, processi
 
 // LogUIEvent logs UI-related events
 func (l *AppLogger) LogUIEvent(event string, component string, action string) {
-	l.Debug("UI event",
+	l.log(DEBUG, 2, "UI event",
 		"event", event,
 		"component", component,
 		"action", action,
@@ -271,12 +388,12 @@ func (l *AppLogger) LogUIEvent(event string, component string, action string) {
 // LogError logs an error with context
 func (l *AppLogger) LogError(err error, context string, fields ...interface{}) {
 	errorFields := append([]interface{}{"error", err.Error(), "context", context}, fields...)
-	l.Error("Application error", errorFields...)
+	l.log(ERROR, 2, "Application error", errorFields...)
 }
 
 // LogPerformance logs performance metrics
 func (l *AppLogger) LogPerformance(operation string, duration time.Duration, memoryUsage int64) {
-	l.Info("Performance metric",
+	l.log(INFO, 2, "Performance metric",
 		"operation", operation,
 		"duration", duration.String(),
 		"memory_usage", memoryUsage,
@@ -286,10 +403,15 @@ func (l *AppLogger) LogPerformance(operation string, duration time.Duration, mem
 // Global logger instance
 var globalLogger *AppLogger
 
-// InitLogger initializes the global logger
-func InitLogger(level LogLevel) error {
-	var err error
-	globalLogger, err = NewAppLogger(level)
+// InitLogger initializes the global logger. If the log file cannot be opened
+// the logger writes to stderr only and the error is returned.
+func InitLogger(level LogLevel, format LogFormat) error {
+	logger, err := NewAppLogger(level, format)
+	if err != nil {
+		logger = &AppLogger{format: format, logger: log.New(os.Stderr, "", 0)}
+		logger.level.Store(int32(level))
+	}
+	globalLogger = logger
 	return err
 }
 
@@ -297,48 +419,58 @@ func InitLogger(level LogLevel) error {
 func GetLogger() *AppLogger {
 	if globalLogger == nil {
 		// Initialize with INFO level if not already initialized
-		InitLogger(INFO)
+		InitLogger(INFO, LogFormatText)
 	}
 	return globalLogger
 }
 
 // Convenience functions for global logger
 func Debug(message string, fields ...interface{}) {
-	GetLogger().Debug(message, fields...)
+	GetLogger().log(DEBUG, 2, message, fields...)
 }
 
 func Info(message string, fields ...interface{}) {
-	GetLogger().Info(message, fields...)
+	GetLogger().log(INFO, 2, message, fields...)
 }
 
 func Warn(message string, fields ...interface{}) {
-	GetLogger().Warn(message, fields...)
+	GetLogger().log(WARN, 2, message, fields...)
 }
 
 func Error(message string, fields ...interface{}) {
-	GetLogger().Error(message, fields...)
+	GetLogger().log(ERROR, 2, message, fields...)
 }
 
 func Fatal(message string, fields ...interface{}) {
-	GetLogger().Fatal(message, fields...)
+	GetLogger().log(FATAL, 2, message, fields...)
 }
 
 func Debugf(format string, args ...interface{}) {
-	GetLogger().Debugf(format, args...)
+	if logger := GetLogger(); DEBUG >= logger.GetLevel() {
+		logger.log(DEBUG, 2, fmt.Sprintf(format, args...))
+	}
 }
 
 func Infof(format string, args ...interface{}) {
-	GetLogger().Infof(format, args...)
+	if logger := GetLogger(); INFO >= logger.GetLevel() {
+		logger.log(INFO, 2, fmt.Sprintf(format, args...))
+	}
 }
 
 func Warnf(format string, args ...interface{}) {
-	GetLogger().Warnf(format, args...)
+	if logger := GetLogger(); WARN >= logger.GetLevel() {
+		logger.log(WARN, 2, fmt.Sprintf(format, args...))
+	}
 }
 
 func Errorf(format string, args ...interface{}) {
-	GetLogger().Errorf(format, args...)
+	if logger := GetLogger(); ERROR >= logger.GetLevel() {
+		logger.log(ERROR, 2, fmt.Sprintf(format, args...))
+	}
 }
 
 func Fatalf(format string, args ...interface{}) {
-	GetLogger().Fatalf(format, args...)
+	if logger := GetLogger(); FATAL >= logger.GetLevel() {
+		logger.log(FATAL, 2, fmt.Sprintf(format, args...))
+	}
 }
@@ -0,0 +1,129 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLogOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf, DEBUG, LogFormatJSON)
+
+	before := time.Now()
+	logger.Info("Recording saved",
+		"file", "recording.mp3",
+		"bytes", 4096,
+		"ratio", 0.25,
+		"ok", true,
+		"err", errors.New("disk \"full\""),
+		"took", 1500*time.Millisecond,
+		"note", "line one\nline two",
+		"dangling",
+	)
+	logger.Debug("No fields")
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one JSON object per log call: %q", len(lines), buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("line is not valid JSON: %v\n%s", err, lines[0])
+	}
+
+	if entry["level"] != "INFO" || entry["message"] != "Recording saved" || entry["file"] != "logger_test.go" {
+		t.Errorf("entry = %v, want INFO \"Recording saved\" from logger_test.go", entry)
+	}
+	if line, ok := entry["line"].(float64); !ok || line <= 0 || line != math.Trunc(line) {
+		t.Errorf("line = %v, want a positive line number", entry["line"])
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, entry["timestamp"].(string))
+	if err != nil || timestamp.Before(before.Truncate(time.Millisecond)) || timestamp.After(time.Now()) {
+		t.Errorf("timestamp = %v (%v), want the time of the call", entry["timestamp"], err)
+	}
+
+	fields, ok := entry["fields"].(map[string]any)
+	if !ok {
+		t.Fatalf("fields = %v, want a nested object", entry["fields"])
+	}
+	want := map[string]any{
+		"file":  "recording.mp3",
+		"bytes": float64(4096),
+		"ratio": 0.25,
+		"ok":    true,
+		"err":   "disk \"full\"",
+		"took":  "1.5s",
+		"note":  "line one\nline two",
+	}
+	if len(fields) != len(want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("field %q = %#v, want %#v", key, fields[key], value)
+		}
+	}
+
+	var bare map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &bare); err != nil {
+		t.Fatalf("line is not valid JSON: %v\n%s", err, lines[1])
+	}
+	if _, ok := bare["fields"]; ok {
+		t.Errorf("entry without fields has a fields object: %s", lines[1])
+	}
+}
+
+func TestJSONLogUnmarshalableField(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf, INFO, LogFormatJSON)
+	logger.Warn("Odd value", "ch", make(chan int), "n", 1)
+
+	var entry jsonLogEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("line is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if entry.Fields["n"] != "1" {
+		t.Errorf("field n = %#v, want its printed form", entry.Fields["n"])
+	}
+	if s, ok := entry.Fields["ch"].(string); !ok || !strings.HasPrefix(s, "0x") {
+		t.Errorf("field ch = %#v, want its printed form", entry.Fields["ch"])
+	}
+}
+
+func TestParseLogFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    LogFormat
+		wantErr bool
+	}{
+		{"", LogFormatText, false},
+		{"text", LogFormatText, false},
+		{" JSON ", LogFormatJSON, false},
+		{"xml", LogFormatText, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLogFormat(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLogFormat(%q) = (%v, %v), want (%v, error=%v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
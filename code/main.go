@@ -14,19 +14,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"image"
 	"image/color"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/go-vgo/robotgo"
@@ -34,27 +40,9 @@ import (
 	hook "github.com/robotn/gohook"
 )
 
-// copyToClipboard copies text to clipboard using xclip
+// copyToClipboard copies text to clipboard using xclip or wl-copy
 func copyToClipboard(text string) error {
-	cmd := exec.Command("xclip", "-selection", "clipboard")
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-
-	if _, err := stdin.Write([]byte(text)); err != nil {
-		return err
-	}
-
-	if err := stdin.Close(); err != nil {
-		return err
-	}
-
-	return cmd.Wait()
+	return writeClipboard([]byte(text), "")
 }
 
 // clickableStatusLabel is a custom label that handles clicks to copy text
@@ -72,15 +60,15 @@ func newClickableStatusLabel(correctedText *widget.Entry) *clickableStatusLabel
 }
 
 func (l *clickableStatusLabel) Tapped(ev *fyne.PointEvent) {
-	log.Printf("Status label clicked, copying text to clipboard")
+	Infof("Status label clicked, copying text to clipboard")
 	textToCopy := l.correctedText.Text
 	if textToCopy != "" {
 		err := copyToClipboard(textToCopy)
 		if err != nil {
-			log.Printf("Failed to copy text to clipboard: %v", err)
+			Errorf("Failed to copy text to clipboard: %v", err)
 			l.SetText(fmt.Sprintf("Copy failed: %v", err))
 		} else {
-			log.Printf("Text copied to clipboard")
+			Infof("Text copied to clipboard")
 			l.SetText("Text copied to clipboard")
 		}
 	} else {
@@ -88,7 +76,8 @@ func (l *clickableStatusLabel) Tapped(ev *fyne.PointEvent) {
 	}
 }
 
-// setStatusText is a helper function to set text on status label (works with both widget.Label and clickableStatusLabel)
+// setStatusText is a helper function to set text on status label (works with both widget.Label and clickableStatusLabel).
+// It must be called on the UI goroutine; background goroutines use postStatusText.
 func setStatusText(statusLabel fyne.Widget, text string) {
 	if label, ok := statusLabel.(*widget.Label); ok {
 		label.SetText(text)
@@ -97,41 +86,96 @@ func setStatusText(statusLabel fyne.Widget, text string) {
 	}
 }
 
-// startMouseHook starts monitoring for Ctrl+drag mouse selection using gohook
-func (a *AppState) startMouseHook() {
+// postStatusText sets the status text from a background goroutine. The label is
+// updated on the UI goroutine through fyne.Do, after this function returns.
+func postStatusText(statusLabel fyne.Widget, text string) {
+	fyne.Do(func() { setStatusText(statusLabel, text) })
+}
+
+// startMouseHook starts monitoring for Ctrl+drag mouse selection using gohook.
+// The monitor exits when ctx is cancelled or stopMouseHook is called. Nothing is
+// started while screenshot capture is disabled, so keys aren't watched globally.
+func (a *AppState) startMouseHook(ctx context.Context) {
+	if !a.config.CaptureEnabled {
+		Infof("Screenshot capture is disabled, global keyboard and mouse hook not started")
+		return
+	}
+
 	a.mouseHookMutex.Lock()
 	if a.isMouseHookActive {
 		a.mouseHookMutex.Unlock()
 		return
 	}
+	previous := a.mouseHookDone
+	a.mouseHookMutex.Unlock()
+
+	// gohook is global, so a stopped monitor must release it before it can start again
+	if previous != nil {
+		<-previous
+	}
+
+	hookCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	a.mouseHookMutex.Lock()
 	a.isMouseHookActive = true
+	a.mouseHookCancel = cancel
+	a.mouseHookDone = done
 	a.mouseHookMutex.Unlock()
 
-	log.Printf("Mouse hook started - monitoring for Ctrl+Shift+drag selection using gohook (isMouseHookActive=%v, ctrlKeyPressed=%v, isSelecting=%v)",
+	Infof("Mouse hook started - monitoring for Ctrl+Shift+drag selection using gohook (isMouseHookActive=%v, ctrlKeyPressed=%v, isSelecting=%v)",
 		a.isMouseHookActive, a.ctrlKeyPressed, a.isSelecting)
 
+	// Show the selection while dragging
+	a.selectionOverlay = newSelectionOverlay()
+	go a.selectionOverlay.run(hookCtx)
+
 	// Start gohook event monitor in separate goroutine
-	go a.monitorGohookEvents()
+	go func() {
+		defer close(done)
+		a.monitorGohookEvents(hookCtx)
+	}()
 }
 
 // monitorGohookEvents monitors keyboard and mouse events using gohook
-func (a *AppState) monitorGohookEvents() {
-	log.Printf("Starting gohook event monitor")
+func (a *AppState) monitorGohookEvents(ctx context.Context) {
+	Debugf("Starting gohook event monitor")
 
 	events := hook.Start()
 	defer hook.End()
 
 	var lastX, lastY int
 	var startX, startY int
-	ctrlPressed := false
-	shiftPressed := false // Track Shift key state
+	var combo modifierCombo // Tracks the Ctrl + Left Shift capture combination
 
-	log.Printf("Gohook event monitor started, waiting for events...")
-	log.Printf("=== KEYBOARD EVENT LOGGING ENABLED - All key presses will be logged ===")
-	log.Printf("=== SCREENSHOT CAPTURE: Ctrl + Left Shift + Mouse Drag ===")
+	Debugf("Gohook event monitor started, waiting for events...")
+	if a.config.LogKeystrokes && GetLogger().GetLevel() <= DEBUG {
+		Infof("=== KEYSTROKE LOGGING ENABLED (DEBUG) - key codes will be logged ===")
+	}
+	Infof("=== SCREENSHOT CAPTURE: Ctrl + Left Shift + Mouse Drag ===")
+
+	// Single-key capture mode: pressing the configured capture key arms
+	// region selection, and the next mouse drag defines the region
+	captureKey, captureKeyEnabled := resolveCaptureKey(a.config.CaptureKey)
+	captureState := captureIdle
+	if captureKeyEnabled {
+		Infof("=== SCREENSHOT CAPTURE: press %q, then drag to select a region ===", a.config.CaptureKey)
+	}
 
 	eventCount := 0
-	for ev := range events {
+monitorLoop:
+	for {
+		var ev hook.Event
+		select {
+		case <-ctx.Done():
+			Infof("Shutdown requested, stopping gohook event monitor")
+			break monitorLoop
+		case e, ok := <-events:
+			if !ok {
+				Infof("Gohook event channel closed")
+				break monitorLoop
+			}
+			ev = e
+		}
 		eventCount++
 
 		// Check if we should stop
@@ -139,7 +183,7 @@ func (a *AppState) monitorGohookEvents() {
 		active := a.isMouseHookActive
 		a.mouseHookMutex.Unlock()
 		if !active {
-			log.Printf("Mouse hook is no longer active, stopping gohook event monitor")
+			Infof("Mouse hook is no longer active, stopping gohook event monitor")
 			break
 		}
 
@@ -152,7 +196,7 @@ func (a *AppState) monitorGohookEvents() {
 			// (will be noisy, but helps debug)
 
 			// If Ctrl + Shift are both pressed, update selection coordinates
-			if ctrlPressed && shiftPressed {
+			if combo.active() {
 				a.mouseHookMutex.Lock()
 				// Update last position while Ctrl is pressed (this is the end point)
 				oldX, oldY := a.lastX, a.lastY
@@ -161,177 +205,113 @@ func (a *AppState) monitorGohookEvents() {
 				if !a.isSelecting {
 					// Mark as selecting
 					a.isSelecting = true
-					log.Printf("Mouse monitor: Selection started - start=(%d, %d), current=(%d, %d)",
+					Debugf("Mouse monitor: Selection started - start=(%d, %d), current=(%d, %d)",
 						a.startX, a.startY, lastX, lastY)
 				} else if oldX != lastX || oldY != lastY {
 					// Only log when position actually changes
-					log.Printf("Mouse monitor: Selection updated - start=(%d, %d), current=(%d, %d)",
+					Debugf("Mouse monitor: Selection updated - start=(%d, %d), current=(%d, %d)",
 						a.startX, a.startY, lastX, lastY)
 				}
+				selection := image.Rect(a.startX, a.startY, lastX, lastY)
 				a.mouseHookMutex.Unlock()
+				a.selectionOverlay.show(selection)
+			}
+
+		case hook.MouseDown:
+			// gohook reports a button press as MouseDown
+			if captureState == captureArmed {
+				startX, startY = int(ev.X), int(ev.Y)
+				lastX, lastY = startX, startY
+				captureState = captureDragging
+				Debugf("Capture key mode: selection started at point: %d, %d", startX, startY)
+
+				a.mouseHookMutex.Lock()
+				a.startX, a.startY = startX, startY
+				a.lastX, a.lastY = startX, startY
+				a.isSelecting = false // Will be set to true by MouseDrag
+				a.mouseHookMutex.Unlock()
+			}
+
+		case hook.MouseDrag:
+			lastX = int(ev.X)
+			lastY = int(ev.Y)
+			if captureState == captureDragging {
+				a.mouseHookMutex.Lock()
+				a.lastX, a.lastY = lastX, lastY
+				a.isSelecting = true
+				a.mouseHookMutex.Unlock()
+				a.selectionOverlay.show(image.Rect(startX, startY, lastX, lastY))
+			}
+
+		case hook.MouseHold:
+			// gohook reports a button release as MouseHold
+			if captureState == captureDragging {
+				captureState = captureIdle
+				lastX, lastY = int(ev.X), int(ev.Y)
+				Debugf("Capture key mode: selection ended at point: %d, %d", lastX, lastY)
+
+				a.mouseHookMutex.Lock()
+				a.lastX, a.lastY = lastX, lastY
+				a.isSelecting = false
+				a.mouseHookMutex.Unlock()
+
+				go a.captureSelection()
 			}
 
 		case hook.KeyDown:
-			// Log all keyboard events for debugging - detailed logging to find Function key
-			log.Printf("=== KEYDOWN === Rawcode=%d, Keycode=%d, Keychar='%c' (rune=%d), Mask=%d, Button=%d, Clicks=%d, Kind=%d",
-				ev.Rawcode, ev.Keycode, ev.Keychar, ev.Keychar, ev.Mask, ev.Button, ev.Clicks, ev.Kind)
-
-			// Check for Ctrl key press
-			// Rawcode 65507 is Ctrl in gohook on Linux
-			// Keycode 29 is also Ctrl
-			// Also check for X11 codes 37 (left Ctrl) and 105 (right Ctrl) for compatibility
-			if ev.Rawcode == 65507 || ev.Rawcode == 37 || ev.Rawcode == 105 || ev.Keycode == 29 || ev.Keycode == 37 || ev.Keycode == 105 {
-				if !ctrlPressed {
-					ctrlPressed = true
-					log.Printf("Ctrl key PRESSED (gohook) - Rawcode=%d, Keycode=%d", ev.Rawcode, ev.Keycode)
-					// Only start selection if Shift is also pressed
-					if shiftPressed {
-						// Use last known mouse position as start point, or get current position if not set
-						if lastX == 0 && lastY == 0 {
-							// Get current mouse position using robotgo
-							startX, startY = robotgo.GetMousePos()
-							lastX, lastY = startX, startY // Update last position too
-						} else {
-							startX = lastX
-							startY = lastY
-						}
-						log.Printf("Ctrl+Shift: Starting selection at point: %d, %d", startX, startY)
-
-						a.mouseHookMutex.Lock()
-						a.ctrlKeyPressed = true
-						a.startX, a.startY = startX, startY
-						a.lastX, a.lastY = startX, startY
-						a.isSelecting = false // Will be set to true by MouseMove
-						log.Printf("Set start position to (%d, %d) when Ctrl+Shift pressed", startX, startY)
-						a.mouseHookMutex.Unlock()
-					}
+			a.logKeyEvent("down", ev, captureKey)
+
+			// Check for the single capture key (arms region selection)
+			if captureKeyEnabled && ev.Keycode == captureKey {
+				if captureState == captureIdle {
+					captureState = captureArmed
+					Debugf("Capture key PRESSED - region selection armed, waiting for drag")
+					postStatusText(a.statusLabel, "Drag to select a region (Esc to cancel)")
 				}
+			} else if captureState != captureIdle && ev.Keycode == hook.Keycode["esc"] {
+				captureState = captureIdle
+				a.selectionOverlay.hide()
+				Infof("Capture key mode: region selection canceled")
+				postStatusText(a.statusLabel, "Region selection canceled")
 			}
 
-			// Check for Left Shift key press
-			// Rawcode 50 is Left Shift in X11
-			// Keycode 42 is also Left Shift
-			if ev.Rawcode == 50 || ev.Keycode == 42 {
-				if !shiftPressed {
-					shiftPressed = true
-					log.Printf("Left Shift key PRESSED (gohook) - Rawcode=%d, Keycode=%d", ev.Rawcode, ev.Keycode)
-					// Only start selection if Ctrl is also pressed
-					if ctrlPressed {
-						// Use last known mouse position as start point, or get current position if not set
-						if lastX == 0 && lastY == 0 {
-							// Get current mouse position using robotgo
-							startX, startY = robotgo.GetMousePos()
-							lastX, lastY = startX, startY // Update last position too
-						} else {
-							startX = lastX
-							startY = lastY
-						}
-						log.Printf("Ctrl+Shift: Starting selection at point: %d, %d", startX, startY)
-
-						a.mouseHookMutex.Lock()
-						a.ctrlKeyPressed = true
-						a.startX, a.startY = startX, startY
-						a.lastX, a.lastY = startX, startY
-						a.isSelecting = false // Will be set to true by MouseMove
-						log.Printf("Set start position to (%d, %d) when Ctrl+Shift pressed", startX, startY)
-						a.mouseHookMutex.Unlock()
-					}
-				}
+			// Start the selection once when Ctrl + Left Shift becomes active,
+			// whichever of the two keys is pressed first
+			ctrl, shift := captureModifiers(ev)
+			if ctrl && !combo.ctrl {
+				Debugf("Ctrl key PRESSED (gohook) - Keycode=%#04x, Mask=%#04x", ev.Keycode, ev.Mask)
+			}
+			if shift && !combo.shift {
+				Debugf("Left Shift key PRESSED (gohook) - Keycode=%#04x, Mask=%#04x", ev.Keycode, ev.Mask)
+			}
+			started, ended := combo.set(ctrl, shift)
+			if started {
+				startX, startY, lastX, lastY = a.beginComboSelection(lastX, lastY)
+			} else if ended {
+				// A release was missed, e.g. while another window grabbed the keyboard
+				lastX, lastY = a.endComboSelection(lastX, lastY)
 			}
 
 		case hook.KeyUp:
-			// Log all keyboard events for debugging - detailed logging to find Function key
-			log.Printf("=== KEYUP === Rawcode=%d, Keycode=%d, Keychar='%c' (rune=%d), Mask=%d, Button=%d, Clicks=%d, Kind=%d",
-				ev.Rawcode, ev.Keycode, ev.Keychar, ev.Keychar, ev.Mask, ev.Button, ev.Clicks, ev.Kind)
-
-			// Check for Ctrl key release
-			// Rawcode 65507 is Ctrl in gohook on Linux
-			// Keycode 29 is also Ctrl
-			// Also check for X11 codes 37 (left Ctrl) and 105 (right Ctrl) for compatibility
-			if ev.Rawcode == 65507 || ev.Rawcode == 37 || ev.Rawcode == 105 || ev.Keycode == 29 || ev.Keycode == 37 || ev.Keycode == 105 {
-				if ctrlPressed {
-					ctrlPressed = false
-					log.Printf("Ctrl key RELEASED (gohook) - Rawcode=%d, Keycode=%d", ev.Rawcode, ev.Keycode)
-					// Only trigger capture if Shift was also pressed (Ctrl+Shift combination)
-					if shiftPressed {
-						// Use last known mouse position as end point, or get current position
-						endX := lastX
-						endY := lastY
-						if endX == 0 && endY == 0 {
-							// Get current mouse position using robotgo
-							endX, endY = robotgo.GetMousePos()
-							lastX, lastY = endX, endY // Update last position too
-						}
-						log.Printf("Ctrl+Shift: Ending selection at point: %d, %d", endX, endY)
-
-						a.mouseHookMutex.Lock()
-						a.ctrlKeyPressed = false
-						// Update end position
-						a.lastX, a.lastY = endX, endY
-						log.Printf("Set end position to (%d, %d) when Ctrl+Shift released", endX, endY)
-						if a.isSelecting {
-							log.Printf("Selection was active, triggering capture")
-							// Trigger screenshot capture
-							go a.captureSelection()
-							a.isSelecting = false
-						} else {
-							log.Printf("Selection was not active (isSelecting=false), but capturing anyway with start=(%d,%d) end=(%d,%d)",
-								a.startX, a.startY, a.lastX, a.lastY)
-							// Even if isSelecting is false, we should capture if we have valid coordinates
-							if a.startX != 0 || a.startY != 0 || a.lastX != 0 || a.lastY != 0 {
-								go a.captureSelection()
-							}
-						}
-						a.mouseHookMutex.Unlock()
-					} else {
-						// Ctrl released but Shift wasn't pressed, just reset state
-						a.mouseHookMutex.Lock()
-						a.ctrlKeyPressed = false
-						a.mouseHookMutex.Unlock()
-					}
-				}
+			a.logKeyEvent("up", ev, captureKey)
+
+			// Capture exactly once when the Ctrl + Left Shift combination is broken,
+			// regardless of which key is released first
+			ctrl, shift := captureModifiers(ev)
+			ctrlReleased := combo.ctrl && !ctrl
+			if ctrlReleased {
+				Debugf("Ctrl key RELEASED (gohook) - Keycode=%#04x, Mask=%#04x", ev.Keycode, ev.Mask)
 			}
-
-			// Check for Left Shift key release
-			// Rawcode 50 is Left Shift in X11
-			// Keycode 42 is also Left Shift
-			if ev.Rawcode == 50 || ev.Keycode == 42 {
-				if shiftPressed {
-					shiftPressed = false
-					log.Printf("Left Shift key RELEASED (gohook) - Rawcode=%d, Keycode=%d", ev.Rawcode, ev.Keycode)
-					// Only trigger capture if Ctrl was also pressed (Ctrl+Shift combination)
-					if ctrlPressed {
-						// Use last known mouse position as end point, or get current position
-						endX := lastX
-						endY := lastY
-						if endX == 0 && endY == 0 {
-							// Get current mouse position using robotgo
-							endX, endY = robotgo.GetMousePos()
-							lastX, lastY = endX, endY // Update last position too
-						}
-						log.Printf("Ctrl+Shift: Ending selection at point: %d, %d", endX, endY)
-
-						a.mouseHookMutex.Lock()
-						a.ctrlKeyPressed = false
-						// Update end position
-						a.lastX, a.lastY = endX, endY
-						log.Printf("Set end position to (%d, %d) when Ctrl+Shift released", endX, endY)
-						if a.isSelecting {
-							log.Printf("Selection was active, triggering capture")
-							// Trigger screenshot capture
-							go a.captureSelection()
-							a.isSelecting = false
-						} else {
-							log.Printf("Selection was not active (isSelecting=false), but capturing anyway with start=(%d,%d) end=(%d,%d)",
-								a.startX, a.startY, a.lastX, a.lastY)
-							// Even if isSelecting is false, we should capture if we have valid coordinates
-							if a.startX != 0 || a.startY != 0 || a.lastX != 0 || a.lastY != 0 {
-								go a.captureSelection()
-							}
-						}
-						a.mouseHookMutex.Unlock()
-					}
-				}
+			if combo.shift && !shift {
+				Debugf("Left Shift key RELEASED (gohook) - Keycode=%#04x, Mask=%#04x", ev.Keycode, ev.Mask)
+			}
+			if _, ended := combo.set(ctrl, shift); ended {
+				lastX, lastY = a.endComboSelection(lastX, lastY)
+			} else if ctrlReleased {
+				// Ctrl released outside the combination, just reset state
+				a.mouseHookMutex.Lock()
+				a.ctrlKeyPressed = false
+				a.mouseHookMutex.Unlock()
 			}
 		}
 
@@ -339,21 +319,153 @@ func (a *AppState) monitorGohookEvents() {
 		time.Sleep(1 * time.Millisecond)
 	}
 
-	log.Printf("Gohook event monitor stopped")
+	Infof("Gohook event monitor stopped")
+}
+
+// logKeyEvent logs a key event for diagnosing hotkey issues. It only writes when
+// keystroke logging was opted into and the log level is DEBUG, and never records
+// the typed character. Codes are logged only for the keys the hook reacts to
+// (modifiers, the capture key and Esc): for any other key the key code and the
+// raw code, which is the keysym on X11, would spell out what was typed.
+func (a *AppState) logKeyEvent(direction string, ev hook.Event, captureKey uint16) {
+	if !a.config.LogKeystrokes || GetLogger().GetLevel() > DEBUG {
+		return
+	}
+	if !isModifierKey(ev.Keycode) && ev.Keycode != captureKey && ev.Keycode != hook.Keycode["esc"] {
+		Debug("Key event",
+			"direction", direction,
+			"key", "other",
+			"modifiers", describeModifiers(ev.Mask),
+		)
+		return
+	}
+	Debug("Key event",
+		"direction", direction,
+		"rawcode", ev.Rawcode,
+		"keycode", ev.Keycode,
+		"mask", ev.Mask,
+		"modifiers", describeModifiers(ev.Mask),
+	)
+}
+
+// modifierCombo tracks whether the Ctrl + Left Shift capture combination is held
+type modifierCombo struct {
+	ctrl  bool
+	shift bool
+}
+
+// active reports whether both keys of the combination are held
+func (m *modifierCombo) active() bool {
+	return m.ctrl && m.shift
+}
+
+// set records which of Ctrl and Left Shift are held and reports whether the
+// combination just became active (started) or was just broken (ended)
+func (m *modifierCombo) set(ctrl bool, shift bool) (started bool, ended bool) {
+	wasActive := m.active()
+	m.ctrl, m.shift = ctrl, shift
+	return !wasActive && m.active(), wasActive && !m.active()
+}
+
+// beginComboSelection records the selection start when Ctrl + Shift becomes active.
+// It returns the updated start and last mouse positions.
+func (a *AppState) beginComboSelection(lastX, lastY int) (startX, startY, newLastX, newLastY int) {
+	// Use last known mouse position as start point, or get current position if not set
+	if lastX == 0 && lastY == 0 {
+		lastX, lastY = robotgo.GetMousePos()
+	}
+	startX, startY = lastX, lastY
+	Debugf("Ctrl+Shift: Starting selection at point: %d, %d", startX, startY)
+
+	a.mouseHookMutex.Lock()
+	a.ctrlKeyPressed = true
+	a.startX, a.startY = startX, startY
+	a.lastX, a.lastY = startX, startY
+	a.isSelecting = false // Will be set to true by MouseMove
+	a.mouseHookMutex.Unlock()
+
+	return startX, startY, lastX, lastY
+}
+
+// endComboSelection records the selection end when Ctrl + Shift is broken and
+// triggers the capture. It returns the updated last mouse position.
+func (a *AppState) endComboSelection(lastX, lastY int) (int, int) {
+	// Use last known mouse position as end point, or get current position
+	if lastX == 0 && lastY == 0 {
+		lastX, lastY = robotgo.GetMousePos()
+	}
+	Debugf("Ctrl+Shift: Ending selection at point: %d, %d", lastX, lastY)
+	a.selectionOverlay.hide()
+
+	a.mouseHookMutex.Lock()
+	defer a.mouseHookMutex.Unlock()
+	a.ctrlKeyPressed = false
+	a.lastX, a.lastY = lastX, lastY
+	if a.isSelecting {
+		Infof("Selection was active, triggering capture")
+		go a.captureSelection()
+		a.isSelecting = false
+	} else if a.startX != 0 || a.startY != 0 || a.lastX != 0 || a.lastY != 0 {
+		// Even if isSelecting is false, we should capture if we have valid coordinates
+		Infof("Selection was not active (isSelecting=false), but capturing anyway with start=(%d,%d) end=(%d,%d)",
+			a.startX, a.startY, a.lastX, a.lastY)
+		go a.captureSelection()
+	}
+	return lastX, lastY
+}
+
+// captureKeyState is the state of the single-key region capture mode
+type captureKeyState int
+
+const (
+	captureIdle     captureKeyState = iota // Waiting for the capture key
+	captureArmed                           // Capture key pressed, waiting for mouse press
+	captureDragging                        // Mouse pressed, drag defines the region
+)
+
+// extraCaptureKeycodes maps key names missing from gohook's keycode table
+// to their libuiohook virtual key codes
+var extraCaptureKeycodes = map[string]uint16{
+	"printscreen": 0x0E37,
+	"print":       0x0E37,
+	"scrolllock":  0x0046,
+	"pause":       0x0E45,
+	"insert":      0x0E52,
+}
+
+// resolveCaptureKey converts a configured key name to a gohook keycode.
+// It returns false if the name is empty or unknown.
+func resolveCaptureKey(name string) (uint16, bool) {
+	if name == "" {
+		return 0, false
+	}
+	if code, ok := extraCaptureKeycodes[name]; ok {
+		return code, true
+	}
+	if code, ok := hook.Keycode[name]; ok {
+		return code, true
+	}
+	Warnf("Unknown capture key %q, single-key capture disabled", name)
+	return 0, false
 }
 
 // stopMouseHook stops the mouse hook monitoring
 func (a *AppState) stopMouseHook() {
-	log.Printf("Stopping mouse hook (before lock) - isMouseHookActive=%v, ctrlKeyPressed=%v, isSelecting=%v",
+	Debugf("Stopping mouse hook (before lock) - isMouseHookActive=%v, ctrlKeyPressed=%v, isSelecting=%v",
 		a.isMouseHookActive, a.ctrlKeyPressed, a.isSelecting)
 	a.mouseHookMutex.Lock()
 	a.isMouseHookActive = false
 	a.ctrlKeyPressed = false
 	a.isSelecting = false
+	cancel := a.mouseHookCancel
+	a.mouseHookCancel = nil
 	a.mouseHookMutex.Unlock()
-	log.Printf("Stopping mouse hook (after unlock) - isMouseHookActive=%v, ctrlKeyPressed=%v, isSelecting=%v",
+	Debugf("Stopping mouse hook (after unlock) - isMouseHookActive=%v, ctrlKeyPressed=%v, isSelecting=%v",
 		a.isMouseHookActive, a.ctrlKeyPressed, a.isSelecting)
-	// Note: hook.End() is called in monitorGohookEvents defer, which will stop when isMouseHookActive becomes false
+	// Note: hook.End() is called in monitorGohookEvents defer once the cancelled context stops it
+	if cancel != nil {
+		cancel()
+	}
 }
 
 // CustomTheme provides white text on dark background
@@ -389,85 +501,171 @@ func (t *CustomTheme) Size(name fyne.ThemeSizeName) float32 {
 	}
 }
 
+// addModeSeparator separates paragraphs appended in "add" mode
+const addModeSeparator = "\n\n"
+
+// minRecordingSeconds is the shortest recording that is sent for transcription
+// unless it was finalized explicitly
+const minRecordingSeconds = 3
+
+// correctionPrefKey is the preferences key storing whether LLM correction is enabled
+const correctionPrefKey = "correctionEnabled"
+
+// fallbackBitrate is the MP3 bitrate (kbps) used when a 128 kbps upload is rejected as too large
+const fallbackBitrate = 32
+
+// recordingSampleRate is the default microphone sample rate and the rate decoded audio is converted to
+const recordingSampleRate = 16000
+
+// transcriptionJob describes a single recording waiting in the transcription queue
+type transcriptionJob struct {
+	audioData     []byte         // Raw 16-bit mono PCM audio
+	sampleRate    uint32         // Sample rate of audioData in Hz
+	mode          string         // "start" or "add"
+	windowTitle   string         // Title of the window focused when recording started
+	recordingFile string         // Filename of the stored recording (empty if saving failed)
+	segments      []audioSegment // Parts transcribed one after another, nil for a single request
+	caption       captionHandler // Receives the text instead of the editor (screenshot notes), nil for the editor
+	queueID       uint64         // Entry in transcriptionQueue, set by addToQueue
+}
+
 // AppState represents the current state of the application
 type AppState struct {
+	recordingMutex     sync.Mutex // Guards isRecording and stream; the UI goroutine writes them, monitors read them
 	isRecording        bool
 	audioBuffer        []int16
-	openaiClient       *OpenAiSpeechClient
-	llmClient          *LLMClient
+	audioMutex         sync.Mutex                   // Guards audioBuffer between the capture worker and its readers
+	capture            atomic.Pointer[audioCapture] // Hands audio from the callback to the worker filling audioBuffer
+	continuous         bool                         // Current recording is transcribed in chunks while recording
+	jobQueue           chan transcriptionJob        // Jobs waiting for runTranscriptionWorker, in recording order
+	history            *TranscriptionHistory        // Every completed transcription, kept across sessions
+	transcriber        Transcriber
+	llmClient          Corrector         // nil until an API key is available, like transcriber
+	instructions       string            // Custom correction instructions, applied to llmClient when it is created
+	clientsMutex       sync.Mutex        // Guards transcriber, llmClient and instructions
+	apiKeyBanner       fyne.CanvasObject // Asks for an API key while the clients are missing
+	apiKeyMessage      *widget.Label     // Explains in apiKeyBanner why a key is needed
+	usage              *UsageTracker     // Session and daily totals of API usage and estimated cost
+	correctionEnabled  bool              // Run transcriptions through LLM correction before inserting them
 	audioStorage       *AudioStorage
 	stream             *portaudio.Stream
 	correctedText      *widget.Entry
 	recordButton       *widget.Button
 	addButton          *widget.Button
-	statusLabel        fyne.Widget // Can be *widget.Label or *clickableStatusLabel
+	liveButton         *widget.Button // Starts a continuous (live) recording
+	statusLabel        fyne.Widget    // Can be *widget.Label or *clickableStatusLabel
 	storedAudioList    *widget.List
+	audioDetailsLabel  *widget.Label
+	selectedAudioFile  string // Recording selected in the Audio Files tab, empty if none
 	lastTranscription  string
+	whisperPrompt      atomic.Pointer[string] // Vocabulary hint sent with every transcription, read through transcriptionPrompt
 	selectedLanguage   string
 	recordingMode      string              // "start" or "add"
+	addSpaceReserved   bool                // Whether a paragraph separator was reserved for "add" mode
 	activeButton       *widget.Button      // Currently active recording button
-	transcriptionQueue []string            // Queue of pending transcriptions
+	transcriptionQueue []queueEntry        // Queue of pending transcriptions, guarded by queueMutex
+	queueMutex         sync.Mutex          // Guards transcriptionQueue and nextQueueID
+	nextQueueID        uint64              // Last id handed out by enqueueTranscription
 	queueIndicators    []fyne.CanvasObject // Visual indicators for queue
 	queueContainer     *fyne.Container     // Container for queue indicators
 	imageContainer     *fyne.Container     // Container for image thumbnail
 	imageData          []byte              // Raw image data for clipboard
 	imageEditorWindow  fyne.Window         // Reference to image editor window (if open)
+	editorWindows      []fyne.Window       // Open editor windows, oldest first
+	editorMutex        sync.Mutex          // Guards editorWindows and imageEditorWindow tracking
 	mouseHookMutex     sync.Mutex          // Mutex for mouse hook state
 	isMouseHookActive  bool                // Whether mouse hook is active
+	mouseHookCancel    context.CancelFunc  // Stops the running gohook monitor (nil if stopped)
+	mouseHookDone      chan struct{}       // Closed once the last gohook monitor has released the hook
 	ctrlKeyPressed     bool                // Whether Ctrl key is currently pressed
 	isSelecting        bool                // Whether we're currently selecting a region
 	startX, startY     int                 // Selection start coordinates
 	lastX, lastY       int                 // Selection end coordinates
+	selectionOverlay   *selectionOverlay   // Frame shown around the selection while dragging (nil if unsupported)
 	processingMutex    sync.Mutex          // Mutex for processing state
 	isProcessing       bool                // Whether audio is being processed
 	shouldCancel       bool                // Flag to cancel processing
+	finalizeRequested  bool                // Keep partial audio when processing the current recording
+	captionTarget      captionHandler      // Receives the current recording's text instead of the editor, if set
+	lastCorrection     *CorrectionJSON     // What the LLM changed in the last transcription, nil if uncorrected
+	correctionPanel    *widget.Accordion   // Collapsible list of lastCorrection's changes
+	correctionDetails  *widget.Label       // Text inside correctionPanel
+	textStatsLabel     *widget.Label       // Word and character count under the editor
+	findBar            *findBar            // Find and replace bar above the editor
+	historyTab         *historyTab         // History tab, refreshed when an entry is added
+	ctx                context.Context     // Cancelled when the application shuts down
+	config             *Config             // User-configurable settings
+	timeLapseCancel    context.CancelFunc  // Stops the running time-lapse capture (nil if idle)
+	mainWindow         fyne.Window         // Main application window (for dialogs)
+	recordingWindow    string              // Title of the window focused when recording started
+	otherWindow        atomic.Value        // Title of the last other window to have the focus (a string)
+	sampleRate         uint32              // Sample rate negotiated with the current input stream
+	channels           uint16              // Channels interleaved in audioBuffer
+	inputDevice        int                 // Index into portaudio.Devices(), -1 for the default device
+	inputDeviceLabel   string              // Selector label of inputDevice, to detect re-enumeration
+	inputPeak          atomic.Int32        // Highest input sample since the level meter last read it
+	levelMeter         *widget.ProgressBar // Live input level while recording
+	silentSamples      atomic.Int64        // Consecutive silent samples in the current recording
+	heardSpeech        atomic.Bool         // Whether the current recording has picked up speech
+	recordedSamples    atomic.Int64        // Samples captured by the current recording, including flushed live chunks
+	requestCtx         context.Context     // Shared by in-flight API requests, replaced after each cancel
+	requestCancel      context.CancelFunc  // Aborts requests using requestCtx
+	isPaused           bool                // Whether the current recording is paused
+	pauseButton        *widget.Button      // Pauses and resumes the current recording
+	retranscribing     sync.Map            // Stored recordings being re-transcribed, keyed by filename
+	sessionChanged     chan struct{}       // Signals an editor change to the session autosave
+	dataDir            string              // Application data folder holding the session and history files
 }
 
-// NewAppState creates a new application state
-func NewAppState() (*AppState, error) {
+// NewAppState creates a new application state.
+// Background workers started by the state stop when ctx is cancelled.
+func NewAppState(ctx context.Context, config *Config) (*AppState, error) {
 	// Initialize PortAudio
 	err := portaudio.Initialize()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize PortAudio: %v", err)
 	}
 
-	// Create OpenAI client
-	openaiClient, err := NewOpenAiSpeechClient()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create OpenAI client: %v", err)
-	}
-
-	// Create LLM client for text correction
-	llmClient, err := NewLLMClient()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create LLM client: %v", err)
-	}
-
 	// Create audio storage
 	audioStorage := NewAudioStorage()
 
-	// Recreate recordings folder on app start
-	if err := audioStorage.RecreateRecordingsFolder(); err != nil {
-		log.Printf("Warning: Failed to recreate recordings folder: %v", err)
+	// Detect ffmpeg once; without it recordings are kept as WAV
+	ffmpegAvailable()
+	audioStorage.SetSortOrder(config.AudioSortOrder)
+
+	// Clean up junk left by crashes while keeping healthy recordings,
+	// then apply the optional retention policy
+	if _, err := audioStorage.ValidateRecordings(); err != nil {
+		Warnf("Failed to validate recordings folder: %v", err)
+	}
+	if _, err := audioStorage.ApplyRetention(config.RetentionKeep, config.RetentionMaxAge); err != nil {
+		Warnf("Failed to apply recordings retention: %v", err)
 	}
 
-	return &AppState{
+	// Files of earlier versions in the working directory are moved over first
+	dataDir := appDataDir()
+	moveToAppData(dataDir, historyFile+".1")
+	moveToAppData(dataDir, historyFile)
+
+	a := &AppState{
+		dataDir:            dataDir,
 		isRecording:        false,
 		audioBuffer:        make([]int16, 0),
-		openaiClient:       openaiClient,
-		llmClient:          llmClient,
+		correctionEnabled:  true,
 		audioStorage:       audioStorage,
+		history:            NewTranscriptionHistory(filepath.Join(dataDir, historyFile), int64(config.HistoryMaxKB)*1024),
 		stream:             nil,
 		correctedText:      nil,
 		recordButton:       nil,
 		addButton:          nil,
 		statusLabel:        nil,
 		storedAudioList:    nil,
+		inputDevice:        defaultInputDevice,
 		lastTranscription:  "",
-		selectedLanguage:   "ru",    // Default to Russian
-		recordingMode:      "start", // Default mode
-		activeButton:       nil,     // Will be set when recording starts
-		transcriptionQueue: make([]string, 0),
+		selectedLanguage:   defaultLanguage,    // Replaced by the saved choice in main
+		recordingMode:      config.DefaultMode, // "start" or "add" from config
+		activeButton:       nil,                // Will be set when recording starts
+		transcriptionQueue: make([]queueEntry, 0),
 		queueIndicators:    make([]fyne.CanvasObject, 0),
 		queueContainer:     nil, // Will be set later
 		imageContainer:     nil,
@@ -481,7 +679,29 @@ func NewAppState() (*AppState, error) {
 		lastY:              0,
 		isProcessing:       false,
 		shouldCancel:       false,
-	}, nil
+		ctx:                ctx,
+		config:             config,
+		sessionChanged:     make(chan struct{}, 1),
+		jobQueue:           make(chan transcriptionJob, transcriptionQueueSize),
+	}
+	go a.runTranscriptionWorker()
+
+	// Create the transcription and correction backends. Without an API key the
+	// window still opens and asks for one.
+	if err := a.createClients(); errors.Is(err, errMissingAPIKey) {
+		Warnf("%v; recording is disabled until a key is entered", err)
+	} else if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// isCurrentRecording reports whether stream is the stream of a recording that is
+// still running. Monitor goroutines use it to notice that their recording ended.
+func (a *AppState) isCurrentRecording(stream *portaudio.Stream) bool {
+	a.recordingMutex.Lock()
+	defer a.recordingMutex.Unlock()
+	return a.isRecording && a.stream == stream
 }
 
 // Cleanup performs cleanup operations
@@ -495,30 +715,68 @@ func (a *AppState) Cleanup() {
 // StartRecording starts audio recording
 func (a *AppState) StartRecording() error {
 	// Audio parameters
-	sampleRate := 16000.0
+	sampleRate := float64(a.config.SampleRate)
+	channels := a.config.Channels
 	framesPerBuffer := 1024
-	numChannels := 1
 
-	// Create audio stream
-	stream, err := portaudio.OpenDefaultStream(
-		numChannels, 0, // input channels, output channels
-		sampleRate, framesPerBuffer, // sample rate, frames per buffer
-		a.audioCallback, // callback function
-	)
+	// Create audio stream on the selected microphone
+	stream, err := a.openInputStream(sampleRate, channels, framesPerBuffer)
 	if err != nil {
 		return fmt.Errorf("failed to open audio stream: %v", err)
 	}
 
+	a.recordingMutex.Lock()
 	a.stream = stream
-	a.audioBuffer = make([]int16, 0)
+	a.recordingMutex.Unlock()
+
+	// The device may not honour the requested rate; use what was actually negotiated
+	a.sampleRate = uint32(a.config.SampleRate)
+	if info := stream.Info(); info != nil && info.SampleRate > 0 {
+		a.sampleRate = uint32(info.SampleRate)
+	}
+	checkSampleRate("StartRecording", uint32(a.config.SampleRate), a.sampleRate)
+	a.channels = uint16(channels)
+
+	// The callback hands audio to a worker that fills audioBuffer
+	a.startAudioCapture(framesPerBuffer)
+
+	// Remember which window the user was dictating into
+	a.recordingWindow = a.dictationWindowTitle()
+	if a.recordingWindow != "" {
+		Infof("Recording started while focused on window: %q", a.recordingWindow)
+	}
 
 	// Start the stream
+	a.resetSilenceTracking()
+	a.recordedSamples.Store(0)
 	err = stream.Start()
 	if err != nil {
+		a.stopAudioCapture()
 		return fmt.Errorf("failed to start audio stream: %v", err)
 	}
 
+	a.recordingMutex.Lock()
 	a.isRecording = true
+	a.recordingMutex.Unlock()
+	GetLogger().LogAudioEvent("recording_started", 0, int(a.sampleRate), channels)
+
+	// Periodically log input levels for headless diagnostics (DEBUG only)
+	go a.monitorAudioLevel(stream)
+
+	// Show the live input level so a muted or dead mic is noticed early
+	go a.runLevelMeter(stream)
+
+	// Optionally stop by itself after a pause in speech
+	go a.runAutoStop(stream)
+
+	// Stop by itself at the maximum length so a forgotten recording can't grow forever
+	go a.runMaxDuration(stream)
+
+	// In continuous mode, transcribe chunks while recording continues
+	if a.continuous {
+		go a.runContinuousSlicer(stream)
+	}
+
 	// Only update the active button text and color
 	if a.activeButton != nil {
 		a.activeButton.SetText("Send")
@@ -536,19 +794,26 @@ func (a *AppState) StopRecording() error {
 		return fmt.Errorf("no active recording stream")
 	}
 
-	// Stop the stream
-	err := a.stream.Stop()
-	if err != nil {
-		return fmt.Errorf("failed to stop audio stream: %v", err)
+	// Stop the stream (a paused stream is already stopped)
+	if !a.isPaused {
+		if err := a.stream.Stop(); err != nil {
+			return fmt.Errorf("failed to stop audio stream: %v", err)
+		}
 	}
 
-	err = a.stream.Close()
+	err := a.stream.Close()
 	if err != nil {
 		return fmt.Errorf("failed to close audio stream: %v", err)
 	}
 
+	// No more callbacks arrive; wait until all captured audio is in audioBuffer
+	a.stopAudioCapture()
+
+	a.recordingMutex.Lock()
 	a.stream = nil
 	a.isRecording = false
+	a.recordingMutex.Unlock()
+	a.setPaused(false)
 
 	// Reset cancel flag before processing
 	a.processingMutex.Lock()
@@ -557,7 +822,7 @@ func (a *AppState) StopRecording() error {
 
 	// Keep button active (showing "Send") until processing is complete
 	// This allows Escape to cancel processing
-	log.Printf("StopRecording: keeping button active (activeButton=%v, button text=%s)", a.activeButton != nil, func() string {
+	Debugf("StopRecording: keeping button active (activeButton=%v, button text=%s)", a.activeButton != nil, func() string {
 		if a.activeButton != nil {
 			return a.activeButton.Text
 		}
@@ -565,18 +830,68 @@ func (a *AppState) StopRecording() error {
 	}())
 	setStatusText(a.statusLabel, "Processing...")
 
+	// Take the samples now so a recording started before processing runs
+	// cannot reset or extend them
+	samples := a.takeAudioBuffer()
+
 	// Process audio in a goroutine to keep UI responsive
-	go a.processAudio()
+	go a.processAudio(samples)
 
 	return nil
 }
 
+// FinalizeRecording stops listening and transcribes whatever has been captured so far,
+// keeping partial audio that StopRecording would reject as too short.
+// Unlike CancelRecording, nothing is discarded.
+func (a *AppState) FinalizeRecording() error {
+	if !a.isRecording {
+		return fmt.Errorf("no active recording to finalize")
+	}
+
+	Infof("FinalizeRecording: stopping and keeping the captured audio")
+	a.finalizeRequested = true
+	if err := a.StopRecording(); err != nil {
+		a.finalizeRequested = false
+		return err
+	}
+	setStatusText(a.statusLabel, "Finalizing partial recording...")
+	return nil
+}
+
+// busyActivity describes work that would be lost by quitting now ("Recording" or
+// "Transcription"), or returns an empty string when it is safe to close
+func (a *AppState) busyActivity() string {
+	if a.isRecording {
+		return "Recording"
+	}
+
+	a.processingMutex.Lock()
+	processing := a.isProcessing
+	a.processingMutex.Unlock()
+	if processing || a.queueLength() > 0 {
+		return "Transcription"
+	}
+	return ""
+}
+
+// abandonProcessing shows status and resets the active button when processAudio
+// drops the recording instead of queuing it. It is called from processAudio's goroutine.
+func (a *AppState) abandonProcessing(status string) {
+	fyne.Do(func() {
+		setStatusText(a.statusLabel, status)
+		a.resetActiveButton()
+	})
+}
+
 // resetActiveButton resets the active button to its original state
 func (a *AppState) resetActiveButton() {
 	if a.activeButton != nil {
-		if a.activeButton == a.recordButton {
+		switch a.activeButton {
+		case a.recordButton:
 			a.activeButton.SetText("Start")
-		} else {
+		case a.liveButton:
+			a.activeButton.SetText("Live")
+		default:
 			a.activeButton.SetText("Add")
 		}
 		a.activeButton.Importance = widget.MediumImportance
@@ -586,7 +901,10 @@ func (a *AppState) resetActiveButton() {
 
 // CancelRecording cancels audio recording without processing the audio
 func (a *AppState) CancelRecording() error {
-	log.Printf("CancelRecording called - isRecording: %v, stream: %v", a.isRecording, a.stream != nil)
+	Debugf("CancelRecording called - isRecording: %v, stream: %v", a.isRecording, a.stream != nil)
+
+	// Drop jobs that have not started yet; the one in progress sees the cancel flag
+	a.discardQueuedJobs()
 
 	// Set cancel flag to stop any pending transcription
 	a.processingMutex.Lock()
@@ -596,96 +914,124 @@ func (a *AppState) CancelRecording() error {
 	}
 	a.processingMutex.Unlock()
 
+	// Abort uploads and correction requests that are already in flight
+	a.cancelRequests()
+
 	// Stop and close audio stream
 	if a.stream != nil {
-		err := a.stream.Stop()
-		if err != nil {
-			log.Printf("CancelRecording: failed to stop audio stream: %v", err)
-			return fmt.Errorf("failed to stop audio stream: %v", err)
+		if !a.isPaused {
+			if err := a.stream.Stop(); err != nil {
+				Errorf("CancelRecording: failed to stop audio stream: %v", err)
+				return fmt.Errorf("failed to stop audio stream: %v", err)
+			}
 		}
 
-		err = a.stream.Close()
+		err := a.stream.Close()
 		if err != nil {
-			log.Printf("CancelRecording: failed to close audio stream: %v", err)
+			Errorf("CancelRecording: failed to close audio stream: %v", err)
 			return fmt.Errorf("failed to close audio stream: %v", err)
 		}
-
-		a.stream = nil
 	}
+	a.stopAudioCapture()
 
 	// Reset recording state
+	a.recordingMutex.Lock()
+	a.stream = nil
 	a.isRecording = false
-	a.audioBuffer = make([]int16, 0)
+	a.recordingMutex.Unlock()
+	a.setPaused(false)
+	discarded := len(a.takeAudioBuffer())
+	GetLogger().LogAudioEvent("recording_canceled", a.samplesDuration(discarded), int(a.sampleRate), int(a.channels))
 
 	// Remove reserved space for "add" mode
 	if a.recordingMode == "add" {
-		currentText := a.correctedText.Text
-		if len(currentText) >= 2 && currentText[len(currentText)-2:] == "\n\n" {
-			currentText = currentText[:len(currentText)-2]
-			a.correctedText.SetText(currentText)
-		}
+		a.unreserveAddSpace()
 	}
 
+	// Release an image editor waiting for a dictated note
+	a.dropCaptionTarget()
+
 	// Reset button and status to original state
 	a.resetActiveButton()
 	setStatusText(a.statusLabel, "Ready")
-	log.Printf("CancelRecording: recording canceled, interface reset to initial state")
+	Infof("CancelRecording: recording canceled, interface reset to initial state")
 	return nil
 }
 
-// audioCallback is called by PortAudio for each audio frame
-func (a *AppState) audioCallback(in []int16) {
-	// Append audio data to buffer
-	a.audioBuffer = append(a.audioBuffer, in...)
+// processingCanceled reports whether the current processing should stop,
+// either because the user canceled it or because the application is shutting down
+func (a *AppState) processingCanceled() bool {
+	if a.ctx != nil && a.ctx.Err() != nil {
+		return true
+	}
+	a.processingMutex.Lock()
+	defer a.processingMutex.Unlock()
+	return a.shouldCancel
 }
 
 // transcribeWithRetry performs transcription with up to 3 retries
-func (a *AppState) transcribeWithRetry(wavData []byte, filename string, language string) (string, error) {
+func (a *AppState) transcribeWithRetry(ctx context.Context, wavData []byte, filename string, language string, duration time.Duration) (string, error) {
 	var lastErr error
 	maxRetries := 3
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		// Check for cancel before each attempt
-		a.processingMutex.Lock()
-		shouldCancel := a.shouldCancel
-		a.processingMutex.Unlock()
+		shouldCancel := a.processingCanceled()
 		if shouldCancel {
-			log.Printf("transcribeWithRetry: canceled before attempt %d", attempt)
+			Infof("transcribeWithRetry: canceled before attempt %d", attempt)
 			return "", fmt.Errorf("transcription canceled")
 		}
 
 		// Callback to change indicator when upload is complete and waiting for response
 		onRequestSent := func() {
-			log.Printf("Upload complete, waiting for Whisper response...")
+			Debugf("Upload complete, waiting for Whisper response...")
 			a.setFirstIndicatorDownload()
 		}
 
-		transcription, err := a.openaiClient.Transcribe(wavData, filename, language, onRequestSent)
+		transcriber, _ := a.clients()
+		if transcriber == nil {
+			return "", errMissingAPIKey
+		}
+		transcription, err := transcriber.Transcribe(ctx, wavData, filename, language, a.transcriptionPrompt(), onRequestSent)
 		if err == nil {
+			a.usage.addTranscription(duration)
 			return transcription, nil
 		}
+		if ctx.Err() != nil {
+			Infof("transcribeWithRetry: request aborted during attempt %d", attempt)
+			return "", fmt.Errorf("transcription canceled: %w", ctx.Err())
+		}
+		if !isRetriable(err) {
+			// Sending the same data again cannot succeed (bad request, auth, size)
+			Warnf("Transcription attempt %d rejected: %v", attempt, err)
+			return "", err
+		}
 
 		lastErr = err
-		log.Printf("Transcription attempt %d failed: %v", attempt, err)
+		Warnf("Transcription attempt %d failed: %v", attempt, err)
 
 		if attempt < maxRetries {
 			// Check for cancel before retry
-			a.processingMutex.Lock()
-			shouldCancel = a.shouldCancel
-			a.processingMutex.Unlock()
+			shouldCancel = a.processingCanceled()
 			if shouldCancel {
-				log.Printf("transcribeWithRetry: canceled before retry (attempt %d)", attempt+1)
+				Infof("transcribeWithRetry: canceled before retry (attempt %d)", attempt+1)
 				return "", fmt.Errorf("transcription canceled")
 			}
-			log.Printf("Retrying transcription (attempt %d/%d)...", attempt+1, maxRetries)
+
+			delay := retryDelay(attempt, err)
+			Infof("Retrying transcription in %v (attempt %d/%d)...", delay.Round(time.Millisecond), attempt+1, maxRetries)
+			if !sleepContext(ctx, delay) {
+				Infof("transcribeWithRetry: canceled while waiting to retry")
+				return "", fmt.Errorf("transcription canceled: %w", ctx.Err())
+			}
 		}
 	}
 
-	return "", fmt.Errorf("transcription failed after %d attempts: %v", maxRetries, lastErr)
+	return "", fmt.Errorf("transcription failed after %d attempts: %w", maxRetries, lastErr)
 }
 
 // processAudio processes the recorded audio and sends it to OpenAI asynchronously
-func (a *AppState) processAudio() {
+func (a *AppState) processAudio(samples []int16) {
 	// Set processing flag
 	a.processingMutex.Lock()
 	a.isProcessing = true
@@ -699,118 +1045,238 @@ func (a *AppState) processAudio() {
 		a.processingMutex.Unlock()
 	}()
 
+	// Consume the finalize request for this recording
+	finalize := a.finalizeRequested
+	a.finalizeRequested = false
+
+	// A dictated screenshot note goes to its editor; tell it if nothing gets queued
+	caption := a.takeCaptionTarget()
+	queued := false
+	if caption != nil {
+		defer func() {
+			if !queued {
+				fyne.Do(func() { caption("") })
+			}
+		}()
+	}
+
+	// Take the remaining audio; in continuous mode earlier chunks were already queued
+	GetLogger().LogAudioEvent("recording_stopped", a.samplesDuration(len(samples)), int(a.sampleRate), int(a.channels))
+
 	// Check for cancel before starting
-	a.processingMutex.Lock()
-	shouldCancel := a.shouldCancel
-	a.processingMutex.Unlock()
+	shouldCancel := a.processingCanceled()
 	if shouldCancel {
-		log.Printf("processAudio: canceled before processing")
-		setStatusText(a.statusLabel, "Processing canceled")
-		a.resetActiveButton()
+		Infof("processAudio: canceled before processing")
+		a.abandonProcessing("Processing canceled")
 		return
 	}
 
-	if len(a.audioBuffer) == 0 {
-		setStatusText(a.statusLabel, "No audio recorded")
-		a.resetActiveButton()
+	// Check minimum recording duration (3 seconds at the stream's sample rate).
+	// A finalized recording keeps whatever was captured, down to Whisper's 0.1s minimum.
+	minSamples := a.samplesPerSecond() * minRecordingSeconds
+	if finalize || a.continuous {
+		minSamples = a.samplesPerSecond() / 10
+	}
+	if a.continuous && len(samples) < minSamples {
+		// Nothing left after the last live chunk; no space was reserved for it
+		a.abandonProcessing("Live recording stopped")
 		return
 	}
 
-	// Check minimum recording duration (3 seconds at 16kHz sample rate)
-	minSamples := int(16000 * 3) // 3 seconds at 16kHz
-	if len(a.audioBuffer) < minSamples {
-		setStatusText(a.statusLabel, "Recording too short (minimum 3 seconds)")
+	if len(samples) == 0 {
+		a.abandonProcessing("No audio recorded")
+		return
+	}
 
+	if len(samples) < minSamples {
 		// If this was an "add" recording, remove the reserved space
 		if a.recordingMode == "add" {
-			currentText := a.correctedText.Text
-			// Remove the last \n\n that we added when starting recording
-			if len(currentText) >= 2 && currentText[len(currentText)-2:] == "\n\n" {
-				currentText = currentText[:len(currentText)-2]
-				a.correctedText.SetText(currentText)
-			}
+			fyne.Do(a.unreserveAddSpace)
 		}
-		a.resetActiveButton()
+		a.abandonProcessing("Recording too short (minimum 3 seconds)")
 		return
 	}
 
 	// Check for cancel before converting
-	a.processingMutex.Lock()
-	shouldCancel = a.shouldCancel
-	a.processingMutex.Unlock()
+	shouldCancel = a.processingCanceled()
 	if shouldCancel {
-		log.Printf("processAudio: canceled before converting audio")
-		setStatusText(a.statusLabel, "Processing canceled")
-		a.resetActiveButton()
+		Infof("processAudio: canceled before converting audio")
+		a.abandonProcessing("Processing canceled")
 		return
 	}
 
-	// Convert int16 samples to bytes
-	audioBytes := make([]byte, len(a.audioBuffer)*2)
-	for i, sample := range a.audioBuffer {
-		// Convert to little-endian bytes
-		audioBytes[i*2] = byte(sample & 0xFF)
-		audioBytes[i*2+1] = byte((sample >> 8) & 0xFF)
-	}
+	// Convert int16 samples to bytes; the archive keeps every channel unfiltered,
+	// transcription gets a mono mix with the optional hum filter and noise gate
+	audioBytes := samplesToPCM(samples)
+	mono := downmixToMono(samples, a.channels)
+	a.filterAudio(mono)
 
 	// Check for cancel before saving recording
-	a.processingMutex.Lock()
-	shouldCancel = a.shouldCancel
-	a.processingMutex.Unlock()
+	shouldCancel = a.processingCanceled()
 	if shouldCancel {
-		log.Printf("processAudio: canceled before saving recording")
-		setStatusText(a.statusLabel, "Processing canceled")
-		a.resetActiveButton()
+		Infof("processAudio: canceled before saving recording")
+		a.abandonProcessing("Processing canceled")
 		return
 	}
 
-	// Save the recording to recordings folder (MP3 128kbps only)
-	lastRecording, err := a.audioStorage.SaveLastRecording(audioBytes, 16000)
-	if err != nil {
-		log.Printf("Failed to save recording: %v", err)
+	// Save the recording to recordings folder (a single MP3 at the configured bitrate)
+	lastRecording := ""
+	if stored, err := a.audioStorage.StoreAudioAt(audioBytes, a.sampleRate, a.channels, a.config.RecordingBitrate); err != nil {
+		Errorf("Failed to save recording: %v", err)
 	} else {
-		log.Printf("Recording saved as: %s", lastRecording)
+		lastRecording = stored.Filename
+		Infof("Recording saved as: %s", lastRecording)
 	}
 
 	// Check for cancel before adding to queue
-	a.processingMutex.Lock()
-	shouldCancel = a.shouldCancel
-	a.processingMutex.Unlock()
+	shouldCancel = a.processingCanceled()
 	if shouldCancel {
-		log.Printf("processAudio: canceled before adding to transcription queue")
-		setStatusText(a.statusLabel, "Processing canceled")
-		a.resetActiveButton()
+		Infof("processAudio: canceled before adding to transcription queue")
+		a.abandonProcessing("Processing canceled")
 		return
 	}
 
 	// Add to transcription queue (asynchronous)
-	a.addToQueue(audioBytes, a.recordingMode)
-	setStatusText(a.statusLabel, fmt.Sprintf("Processing... (%d in queue)", len(a.transcriptionQueue)))
+	if a.continuous {
+		fyne.Do(a.reserveAddSpace)
+	}
+	job := transcriptionJob{
+		audioData:     samplesToPCM(mono),
+		sampleRate:    a.sampleRate,
+		mode:          a.recordingMode,
+		windowTitle:   a.recordingWindow,
+		recordingFile: lastRecording,
+		caption:       caption,
+	}
+	if caption == nil {
+		job.segments = a.chunkSegments(mono)
+	}
+	a.addToQueue(job)
+	queued = true
+	postStatusText(a.statusLabel, fmt.Sprintf("Processing... (%d in queue)", a.queueLength()))
 
 	// Update stored audio list
 	a.updateStoredAudioList()
 }
 
+// reserveAddSpaceText returns text prepared for appending a new paragraph and
+// whether a separator was reserved. Empty (or whitespace-only) text gets no separator.
+func reserveAddSpaceText(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return "", false
+	}
+	return trimmed + addModeSeparator, true
+}
+
+// unreserveAddSpaceText removes the separator added by reserveAddSpaceText.
+// It only touches the text if a separator was actually reserved.
+func unreserveAddSpaceText(text string, reserved bool) string {
+	if !reserved {
+		return text
+	}
+	return strings.TrimSuffix(text, addModeSeparator)
+}
+
+// reserveAddSpace reserves room for an "add" mode transcription in the editor.
+// It must be called on the UI goroutine; background goroutines wrap it in fyne.Do.
+func (a *AppState) reserveAddSpace() {
+	text, reserved := reserveAddSpaceText(a.correctedText.Text)
+	a.correctedText.SetText(text)
+	a.addSpaceReserved = reserved
+}
+
+// unreserveAddSpace removes the reserved room if the "add" recording produced no text.
+// Calling it more than once is safe. Like reserveAddSpace, it runs on the UI goroutine.
+func (a *AppState) unreserveAddSpace() {
+	text := unreserveAddSpaceText(a.correctedText.Text, a.addSpaceReserved)
+	if text != a.correctedText.Text {
+		a.correctedText.SetText(text)
+	}
+	a.addSpaceReserved = false
+}
+
+// transcribeClipboardAudio reads audio from the clipboard and queues it for
+// transcription, appending the result like an "add" recording
+func (a *AppState) transcribeClipboardAudio() {
+	if !a.clientsReady() {
+		return
+	}
+	if a.isRecording {
+		setStatusText(a.statusLabel, "Stop recording before transcribing clipboard audio")
+		return
+	}
+
+	setStatusText(a.statusLabel, "Reading audio from clipboard...")
+	go func() {
+		audioData, mimeType, err := readClipboardAudio()
+		if err == errNoClipboardAudio {
+			Infof("transcribeClipboardAudio: %v", err)
+			postStatusText(a.statusLabel, "No audio on clipboard")
+			return
+		} else if err != nil {
+			Infof("transcribeClipboardAudio: %v", err)
+			postStatusText(a.statusLabel, fmt.Sprintf("Clipboard error: %v", err))
+			return
+		}
+		Infof("Read %d bytes of %s from clipboard", len(audioData), mimeType)
+
+		pcmData, err := a.audioStorage.DecodeToPCM(audioData, recordingSampleRate)
+		if err != nil {
+			postStatusText(a.statusLabel, "Clipboard audio could not be decoded")
+			return
+		}
+		if len(pcmData) == 0 {
+			postStatusText(a.statusLabel, "Clipboard audio is empty")
+			return
+		}
+
+		fyne.Do(a.reserveAddSpace)
+		a.addToQueue(transcriptionJob{
+			audioData:  pcmData,
+			sampleRate: recordingSampleRate,
+			mode:       "add",
+		})
+		postStatusText(a.statusLabel, fmt.Sprintf("Processing clipboard audio... (%d in queue)", a.queueLength()))
+	}()
+}
+
 // clearCorrectedText clears the corrected text area
 func (a *AppState) clearCorrectedText() {
 	a.correctedText.SetText("")
 	setStatusText(a.statusLabel, "Ready")
 }
 
-// onRecordButtonClick handles the record button click
+// beginRecording starts a recording in the given mode ("start" replaces text,
+// "add" appends it) with button as the active recording button
+func (a *AppState) beginRecording(mode string, button *widget.Button) {
+	if !a.clientsReady() {
+		return
+	}
+	a.recordingMode = mode
+	a.activeButton = button
+	a.continuous = button != nil && button == a.liveButton
+
+	// Continuous recordings reserve space right before each chunk is queued
+	if mode == "add" && !a.continuous {
+		a.reserveAddSpace()
+	}
+
+	err := a.StartRecording()
+	if err != nil {
+		Errorf("Failed to start recording: %v", err)
+		setStatusText(a.statusLabel, fmt.Sprintf("Recording error: %v", err))
+	}
+}
+
+// onRecordButtonClick handles the record button click - records using the configured default mode
 func (a *AppState) onRecordButtonClick() {
 	if !a.isRecording {
-		a.recordingMode = "start"       // Set mode to start (replace text)
-		a.activeButton = a.recordButton // Set active button
-		err := a.StartRecording()
-		if err != nil {
-			log.Printf("Failed to start recording: %v", err)
-			setStatusText(a.statusLabel, fmt.Sprintf("Recording error: %v", err))
-		}
+		a.beginRecording(a.config.DefaultMode, a.recordButton)
 	} else {
 		err := a.StopRecording()
 		if err != nil {
-			log.Printf("Failed to stop recording: %v", err)
+			Errorf("Failed to stop recording: %v", err)
 			setStatusText(a.statusLabel, fmt.Sprintf("Stop error: %v", err))
 		}
 	}
@@ -819,27 +1285,11 @@ func (a *AppState) onRecordButtonClick() {
 // onAddButtonClick handles the add button click - records and appends text
 func (a *AppState) onAddButtonClick() {
 	if !a.isRecording {
-		a.recordingMode = "add"      // Set mode to add (append text)
-		a.activeButton = a.addButton // Set active button
-
-		// Reserve space by adding a new line immediately
-		currentText := strings.TrimSpace(a.correctedText.Text)
-		if currentText != "" {
-			currentText += "\n\n"
-		} else {
-			currentText = ""
-		}
-		a.correctedText.SetText(currentText)
-
-		err := a.StartRecording()
-		if err != nil {
-			log.Printf("Failed to start recording: %v", err)
-			setStatusText(a.statusLabel, fmt.Sprintf("Recording error: %v", err))
-		}
+		a.beginRecording("add", a.addButton)
 	} else {
 		err := a.StopRecording()
 		if err != nil {
-			log.Printf("Failed to stop recording: %v", err)
+			Errorf("Failed to stop recording: %v", err)
 			setStatusText(a.statusLabel, fmt.Sprintf("Stop error: %v", err))
 		}
 	}
@@ -847,244 +1297,488 @@ func (a *AppState) onAddButtonClick() {
 
 // updateQueueIndicators updates the visual queue indicators
 func (a *AppState) updateQueueIndicators() {
-	if a.queueContainer == nil {
-		return
-	}
-
-	// Clear existing indicators
-	a.queueContainer.RemoveAll()
-	a.queueIndicators = make([]fyne.CanvasObject, 0)
+	fyne.Do(func() {
+		if a.queueContainer == nil {
+			return
+		}
 
-	// Create new indicators based on queue length
-	for i := 0; i < len(a.transcriptionQueue); i++ {
-		// Create an icon that represents data submission/upload
-		indicator := widget.NewIcon(theme.UploadIcon())
-		indicator.Resize(fyne.NewSize(16, 16))
-		a.queueIndicators = append(a.queueIndicators, indicator)
-		a.queueContainer.Add(indicator)
-	}
+		// Clear existing indicators
+		a.queueContainer.RemoveAll()
+		a.queueIndicators = make([]fyne.CanvasObject, 0)
+
+		// Create new indicators based on queue length
+		pending := a.queueLength()
+		for i := 0; i < pending; i++ {
+			// Create an icon that represents data submission/upload
+			indicator := widget.NewIcon(theme.UploadIcon())
+			indicator.Resize(fyne.NewSize(16, 16))
+			a.queueIndicators = append(a.queueIndicators, indicator)
+			a.queueContainer.Add(indicator)
+		}
+	})
 }
 
 // setFirstIndicatorDownload changes the first queue indicator to download icon
 // Called when upload is complete and waiting for response from server
 func (a *AppState) setFirstIndicatorDownload() {
-	if a.queueContainer == nil || len(a.queueIndicators) == 0 {
-		return
-	}
+	fyne.Do(func() {
+		if a.queueContainer == nil || len(a.queueIndicators) == 0 {
+			return
+		}
 
-	// Replace first indicator with download icon
-	downloadIcon := widget.NewIcon(theme.DownloadIcon())
-	downloadIcon.Resize(fyne.NewSize(16, 16))
-	a.queueIndicators[0] = downloadIcon
+		// Replace first indicator with download icon
+		downloadIcon := widget.NewIcon(theme.DownloadIcon())
+		downloadIcon.Resize(fyne.NewSize(16, 16))
+		a.queueIndicators[0] = downloadIcon
 
-	// Update container
-	a.queueContainer.RemoveAll()
-	for _, indicator := range a.queueIndicators {
-		a.queueContainer.Add(indicator)
-	}
-	a.queueContainer.Refresh()
+		// Update container
+		a.queueContainer.RemoveAll()
+		for _, indicator := range a.queueIndicators {
+			a.queueContainer.Add(indicator)
+		}
+		a.queueContainer.Refresh()
+	})
 }
 
 // addToQueue adds a transcription request to the queue
-func (a *AppState) addToQueue(audioData []byte, mode string) {
+func (a *AppState) addToQueue(job transcriptionJob) {
 	// Check if audio data is not empty
-	if len(audioData) == 0 {
-		setStatusText(a.statusLabel, "No audio data to process")
+	if len(job.audioData) == 0 {
+		postStatusText(a.statusLabel, "No audio data to process")
 		return
 	}
 
-	// Add to queue
-	a.transcriptionQueue = append(a.transcriptionQueue, mode)
-	a.updateQueueIndicators()
+	// Add to queue; the worker processes jobs one at a time in this order
+	job.queueID = a.enqueueTranscription(job.mode)
+	a.submitJob(job)
+}
+
+// TranscriptionResult is the outcome of transcribing a single queue item
+type TranscriptionResult struct {
+	Text     string // Trimmed transcription (empty if no speech was detected)
+	Mode     string // "start" or "add", copied from the job
+	Language string // Language the final text was transcribed with
+	Err      error  // Set when transcription failed
+	Canceled bool   // Set when the user canceled before a result was produced
 
-	// Process asynchronously
-	go a.processQueueItem(audioData, mode)
+	Correction *CorrectionJSON // What the LLM changed, nil if the text was not corrected
 }
 
-// processQueueItem processes a single queue item
-func (a *AppState) processQueueItem(audioData []byte, mode string) {
+// applyTranscription returns the editor text after inserting text according to mode.
+// In "add" mode the separator was already reserved when recording started,
+// so the transcription is appended as-is; "start" mode replaces the text.
+func applyTranscription(current string, text string, mode string) string {
+	if mode == "add" {
+		return current + text
+	}
+	return text
+}
+
+// processQueueItem processes a single queue item and applies the result to the UI
+func (a *AppState) processQueueItem(job transcriptionJob) {
 	defer func() {
 		// Remove from queue when done
-		if len(a.transcriptionQueue) > 0 {
-			a.transcriptionQueue = a.transcriptionQueue[1:]
-			a.updateQueueIndicators()
-		}
+		a.dequeueTranscription(job.queueID)
 		// Reset cancel flag when done (successfully or canceled)
 		a.processingMutex.Lock()
 		a.shouldCancel = false
 		a.processingMutex.Unlock()
-		log.Printf("processQueueItem: finished, shouldCancel reset to false")
+		Debugf("processQueueItem: finished, shouldCancel reset to false")
+
+		// Reset button to original state once the queue is empty,
+		// unless a continuous recording is still running
+		fyne.Do(func() {
+			if !a.isRecording && a.queueLength() == 0 {
+				a.resetActiveButton()
+				Debugf("processQueueItem: button reset to initial state")
+			}
+		})
 	}()
 
+	// Screenshot notes are drawn onto the image instead of inserted into the editor
+	if job.caption != nil {
+		a.processCaptionJob(job)
+		return
+	}
+
+	// Long recordings may be split into parts that are shown as they arrive
+	if len(job.segments) > 1 {
+		a.processChunkedJob(job)
+		return
+	}
+
+	result := a.transcribeJob(job)
+
+	// Too large to upload at once: transcribe it in parts like a chunked recording
+	var tooLarge *uploadLimitError
+	if errors.As(result.Err, &tooLarge) {
+		if job.segments = uploadSegments(job.audioData, job.sampleRate, tooLarge.Size); len(job.segments) > 1 {
+			Infof("processQueueItem: splitting %d bytes of audio into %d parts for upload", tooLarge.Size, len(job.segments))
+			a.processChunkedJob(job)
+			return
+		}
+	}
+
+	switch {
+	case result.Canceled:
+		postStatusText(a.statusLabel, "Transcription canceled")
+	case result.Err != nil:
+		Errorf("Transcription failed: %v", result.Err)
+		postStatusText(a.statusLabel, transcriptionFailureStatus(result.Err))
+	case result.Text == "":
+		Infof("processQueueItem: transcription is empty, nothing to insert")
+		if result.Mode == "add" {
+			fyne.Do(a.unreserveAddSpace)
+		}
+		postStatusText(a.statusLabel, "No speech detected")
+	default:
+		// The editor is read and written in one UI update so typing or another
+		// result cannot slip in between
+		fyne.Do(func() {
+			newText := applyTranscription(a.correctedText.Text, result.Text, result.Mode)
+			a.correctedText.SetText(newText)
+			if result.Mode == "add" {
+				a.addSpaceReserved = false
+			}
+
+			// Auto-copy to clipboard
+			if err := copyToClipboard(newText); err != nil {
+				Errorf("Failed to copy to clipboard: %v", err)
+			} else {
+				Infof("Text automatically copied to clipboard")
+			}
+		})
+		a.showCorrection(result.Correction)
+
+		// Store transcript metadata next to the recording and in the history
+		a.saveJobTranscript(job, result.Text, result.Language)
+		a.recordHistory(job, result.Text, result.Language)
+
+		if job.windowTitle != "" {
+			postStatusText(a.statusLabel, fmt.Sprintf("Transcription completed (in %s)", job.windowTitle))
+		} else {
+			postStatusText(a.statusLabel, "Transcription completed")
+		}
+	}
+}
+
+// saveJobTranscript stores the transcript metadata next to the job's recording, if it was saved
+func (a *AppState) saveJobTranscript(job transcriptionJob, text string, language string) {
+	if job.recordingFile == "" {
+		return
+	}
+	meta := TranscriptMetadata{
+		Recording:   job.recordingFile,
+		Text:        text,
+		Language:    language,
+		Mode:        job.mode,
+		WindowTitle: job.windowTitle,
+		Timestamp:   time.Now(),
+	}
+	if err := a.audioStorage.SaveTranscriptMetadata(meta); err != nil {
+		Errorf("Failed to save transcript metadata: %v", err)
+	}
+}
+
+// transcribeJob converts and transcribes the job's audio without touching the editor.
+// Progress is still reported in the status bar; the caller applies the result.
+func (a *AppState) transcribeJob(job transcriptionJob) TranscriptionResult {
+	result := TranscriptionResult{Mode: job.mode}
+
+	// Decide on correction when the item starts so later toggles don't affect it
+	correct := a.correctionEnabled
+
+	// Escape aborts in-flight API requests through this context
+	ctx := a.requestContext()
+
 	// Check for cancel BEFORE starting transcription
 	// If Escape was pressed, we should cancel immediately
-	a.processingMutex.Lock()
-	shouldCancel := a.shouldCancel
-	a.processingMutex.Unlock()
-	if shouldCancel {
-		log.Printf("processQueueItem: canceled before starting transcription (Escape was pressed)")
-		setStatusText(a.statusLabel, "Transcription canceled")
-		a.resetActiveButton()
-		return
+	if a.processingCanceled() {
+		Infof("transcribeJob: canceled before starting transcription (Escape was pressed)")
+		result.Canceled = true
+		return result
 	}
 
 	// Only reset cancel flag AFTER we've confirmed we're starting transcription
 	// This allows Escape to work even if pressed right after recording stops
 	a.processingMutex.Lock()
 	a.shouldCancel = false
-	log.Printf("processQueueItem: starting new transcription, shouldCancel reset to false")
+	Debugf("transcribeJob: starting new transcription, shouldCancel reset to false")
 	a.processingMutex.Unlock()
 
-	// Convert to MP3 128kbps for transcription (smaller file size, faster upload)
-	mp3Data, err := a.audioStorage.ConvertToMP3(audioData, 16000, 128)
-	if err != nil {
-		log.Printf("Failed to convert to MP3, falling back to WAV: %v", err)
-		// Fallback to WAV if MP3 conversion fails
-		mp3Data = CreateWAVFile(audioData, 16000, 1)
+	// Compress for transcription (smaller file size, faster upload)
+	uploadData, uploadName := a.encodeForUpload(job.audioData, job.sampleRate)
+
+	// The OpenAI API rejects files over 25 MB; the caller splits the audio instead
+	if a.config.Transcriber == TranscriberOpenAI && len(uploadData) > whisperUploadLimit {
+		Warnf("transcribeJob: %s is %d bytes, over the upload limit", uploadName, len(uploadData))
+		result.Err = &uploadLimitError{Size: len(uploadData)}
+		return result
 	}
 
 	// Check for cancel before transcribing
-	a.processingMutex.Lock()
-	shouldCancel = a.shouldCancel
-	a.processingMutex.Unlock()
-	if shouldCancel {
-		log.Printf("processQueueItem: canceled before transcription")
-		setStatusText(a.statusLabel, "Transcription canceled")
-		a.resetActiveButton()
-		return
+	if a.processingCanceled() {
+		Infof("transcribeJob: canceled before transcription")
+		result.Canceled = true
+		return result
 	}
 
 	// Transcribe with retry (use selected language)
 	language := a.selectedLanguage
 	if language == "" {
-		language = "ru" // Default to Russian if not set
+		language = defaultLanguage
+	}
+	Infof("Processing transcription with language: %s (uploading %s, %d bytes)", language, uploadName, len(uploadData))
+	started := time.Now()
+	audioDuration := pcmDuration(job.audioData, job.sampleRate)
+	transcription, err := a.transcribeWithRetry(ctx, uploadData, uploadName, language, audioDuration)
+	if errors.Is(err, errAudioTooLarge) {
+		// Re-encode at a lower bitrate and try once more
+		Warnf("Upload too large (%d bytes), re-encoding at %d kbps", len(uploadData), fallbackBitrate)
+		postStatusText(a.statusLabel, fmt.Sprintf("Audio too large, retrying at %d kbps...", fallbackBitrate))
+		if smaller, convErr := a.audioStorage.ConvertToMP3(job.audioData, job.sampleRate, fallbackBitrate); convErr != nil {
+			Errorf("Failed to re-encode at lower bitrate: %v", convErr)
+		} else {
+			uploadData, uploadName = smaller, "recording.mp3"
+			transcription, err = a.transcribeWithRetry(ctx, uploadData, uploadName, language, audioDuration)
+		}
 	}
-	log.Printf("Processing transcription with language: %s (using MP3 128kbps)", language)
-	transcription, err := a.transcribeWithRetry(mp3Data, "recording.mp3", language)
 	if err != nil {
-		setStatusText(a.statusLabel, "Transcribed Failed")
-		a.resetActiveButton()
-		return
+		if a.processingCanceled() {
+			Infof("transcribeJob: transcription aborted by cancel: %v", err)
+			result.Canceled = true
+			return result
+		}
+		result.Err = err
+		return result
 	}
 
 	// Check for cancel after transcription
-	a.processingMutex.Lock()
-	shouldCancel = a.shouldCancel
-	a.processingMutex.Unlock()
-	if shouldCancel {
-		log.Printf("processQueueItem: canceled after transcription")
-		setStatusText(a.statusLabel, "Transcription canceled")
-		a.resetActiveButton()
-		return
+	if a.processingCanceled() {
+		Infof("transcribeJob: canceled after transcription")
+		result.Canceled = true
+		return result
+	}
+	GetLogger().LogTranscriptionEvent("transcribed", language, len(transcription), time.Since(started))
+
+	// Optionally warn when the result is not in the requested language
+	if a.config.LanguageCheck {
+		if detected, mismatch := checkLanguageMismatch(transcription, language); mismatch {
+			Warnf("Language mismatch: requested %s, transcription looks %s", language, detected)
+			postStatusText(a.statusLabel, fmt.Sprintf("Language mismatch: expected %s, got %s text", language, detected))
+
+			if a.confirmRetranscribeAuto(language, detected) {
+				Infof("Re-transcribing with language auto-detection")
+				postStatusText(a.statusLabel, "Re-transcribing with auto-detect...")
+				if autoTranscription, err := a.transcribeWithRetry(ctx, uploadData, uploadName, "auto", audioDuration); err != nil {
+					Warnf("Auto-detect re-transcription failed, keeping original: %v", err)
+				} else {
+					transcription = autoTranscription
+					language = "auto"
+				}
+			}
+		}
 	}
 
-	// Update text based on mode
+	// Polish the text with the LLM, keeping the raw transcription if that fails
 	transcription = strings.TrimSpace(transcription)
-	if mode == "add" {
-		// Add mode: append to existing text
-		// Since we already reserved space with \n\n when recording started,
-		// we just need to append the transcription
-		currentText := a.correctedText.Text
-		currentText += transcription
-		a.correctedText.SetText(currentText)
-
-		// Auto-copy to clipboard
-		if err := copyToClipboard(currentText); err != nil {
-			log.Printf("Failed to copy to clipboard: %v", err)
+	if _, llmClient := a.clients(); correct && transcription != "" && llmClient != nil {
+		postStatusText(a.statusLabel, "Correcting text...")
+		// In "add" mode the text already in the editor keeps names and terminology consistent
+		var correction *CorrectionJSON
+		var err error
+		if textContext := trimCorrectionContext(a.editorText(), maxCorrectionContext); job.mode == "add" && textContext != "" {
+			correction, err = llmClient.CorrectTextDetailedWithContext(ctx, transcription, textContext)
 		} else {
-			log.Printf("Text automatically copied to clipboard")
+			correction, err = llmClient.CorrectTextDetailed(ctx, transcription)
 		}
-	} else {
-		// Start mode: replace text
-		a.correctedText.SetText(transcription)
-
-		// Auto-copy to clipboard
-		if err := copyToClipboard(transcription); err != nil {
-			log.Printf("Failed to copy to clipboard: %v", err)
+		if err != nil {
+			if a.processingCanceled() {
+				Infof("transcribeJob: correction aborted by cancel")
+				result.Canceled = true
+				return result
+			}
+			if !errors.Is(err, errCorrectorUnreachable) {
+				Warnf("LLM correction failed, using raw transcription: %v", err)
+			}
 		} else {
-			log.Printf("Text automatically copied to clipboard")
+			a.usage.addCompletion(correction.Model, correction.Usage)
+			if corrected := strings.TrimSpace(correction.CorrectedText); corrected != "" {
+				if correction.OriginalText == "" {
+					correction.OriginalText = transcription
+				}
+				decision := reviewAccepted
+				if a.config.ReviewCorrections && corrected != transcription {
+					decision = <-a.reviewCorrection(correction)
+				}
+				switch decision {
+				case reviewCanceled:
+					Infof("transcribeJob: canceled during correction review")
+					result.Canceled = true
+					return result
+				case reviewRejected:
+					Infof("Correction rejected, keeping the raw transcription")
+				default:
+					transcription = corrected
+					result.Correction = correction
+				}
+			}
 		}
 	}
 
-	setStatusText(a.statusLabel, "Transcription completed")
+	// Carry out spoken commands such as "new line" before the text is written
+	if a.config.VoiceCommands && transcription != "" {
+		transcription = applyVoiceCommands(transcription, language)
+	}
 
-	// Reset button to original state after transcription is complete
-	a.resetActiveButton()
-	log.Printf("processQueueItem: button reset to initial state after transcription")
+	// Optionally break a long dictation into paragraphs (local, no API call)
+	if a.config.FormatParagraphs && transcription != "" {
+		transcription = formatParagraphs(transcription)
+	}
+
+	result.Text = transcription
+	result.Language = language
+	return result
+}
+
+// editorText returns the editor's text, read on the UI goroutine so the worker
+// sees it after every result queued before it has been applied. It returns ""
+// when the application shuts down first.
+func (a *AppState) editorText() string {
+	text := make(chan string, 1)
+	fyne.Do(func() { text <- a.correctedText.Text })
+
+	select {
+	case t := <-text:
+		return t
+	case <-a.ctx.Done():
+		return ""
+	}
 }
 
-// updateStoredAudioList updates the stored audio list widget
+// confirmRetranscribeAuto asks the user whether to re-transcribe with language
+// auto-detection and blocks until they answer or the application shuts down
+func (a *AppState) confirmRetranscribeAuto(language string, detected string) bool {
+	if a.mainWindow == nil {
+		return false
+	}
+
+	answer := make(chan bool, 1)
+	fyne.Do(func() {
+		dialog.ShowConfirm("Language mismatch",
+			fmt.Sprintf("Transcription was requested in %q but looks like %s text.\nRe-transcribe with language auto-detection?", language, detected),
+			func(ok bool) { answer <- ok },
+			a.mainWindow)
+	})
+
+	select {
+	case ok := <-answer:
+		return ok
+	case <-a.ctx.Done():
+		return false
+	}
+}
+
+// formatAudioFileLabel formats a stored audio file for the Audio Files list
+func formatAudioFileLabel(file AudioFile) string {
+	if file.Bitrate > 0 {
+		return fmt.Sprintf("%s (%dkbps, %s)", file.Filename, file.Bitrate, file.Timestamp.Format("15:04:05"))
+	}
+	return fmt.Sprintf("%s (%s)", file.Filename, file.Timestamp.Format("15:04:05"))
+}
+
+// updateStoredAudioList updates the stored audio list widget. The list reads
+// the recordings folder itself, so it only needs a refresh on the UI goroutine.
 func (a *AppState) updateStoredAudioList() {
 	if a.storedAudioList == nil {
 		return
 	}
+	fyne.Do(a.storedAudioList.Refresh)
+}
 
-	audioFiles, err := a.audioStorage.GetStoredAudioFiles()
-	if err != nil {
-		log.Printf("Failed to get stored audio files: %v", err)
+func main() {
+	// Diagnostic mode for hotkey problems, needs neither the API key nor the GUI
+	if len(os.Args) > 1 && os.Args[1] == "debug-modifiers" {
+		runModifierDebug()
 		return
 	}
 
-	// Create list data
-	var listData []string
-	for _, file := range audioFiles {
-		listItem := fmt.Sprintf("%s (%dkbps, %s)",
-			file.Filename,
-			file.Bitrate,
-			file.Timestamp.Format("15:04:05"))
-		listData = append(listData, listItem)
+	// Log to app.log (truncated on each start) and the console. The level is INFO
+	// until the configuration is loaded, so warnings about it are not lost.
+	// The format is read first so every line of a JSON log is JSON.
+	logFormat, formatErr := ParseLogFormat(envString("MICAPP_LOG_FORMAT", "text"))
+	if err := InitLogger(INFO, logFormat); err != nil {
+		Errorf("Failed to open log file: %v, logging to stderr", err)
+	}
+	defer GetLogger().Close()
+	if formatErr != nil {
+		Warnf("Invalid MICAPP_LOG_FORMAT, using text: %v", formatErr)
 	}
 
-	// Update list widget
-	a.storedAudioList.Refresh()
-}
+	// Libraries using the standard log package write through the logger as well
+	log.SetOutput(GetLogger())
+	log.SetFlags(0)
 
-func main() {
-	// Configure logging to write to app.log file (truncate on each start)
-	logFile, err := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
-	if err != nil {
-		log.Printf("Failed to open log file: %v, logging to stderr", err)
-	} else {
-		defer logFile.Close()
-		log.SetOutput(logFile)
-		log.SetFlags(log.LstdFlags | log.Lshortfile)
-	}
+	// Shutdown context shared by all long-lived background goroutines
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Load user configuration
+	config := LoadConfig()
 
-	// Check if OpenAI API key is set
-	if os.Getenv("OPENAI_API_KEY") == "" {
-		log.Fatal("OPENAI_API_KEY environment variable is not set. Please set it before running the application.")
+	pngCompression = config.PNGCompression
+	captureScaleOverride = config.CaptureScale
+	if config.VoiceCommandsFile != "" {
+		if commands, err := loadVoiceCommands(config.VoiceCommandsFile); err != nil {
+			Warnf("Using the built-in voice commands: %v", err)
+		} else {
+			voiceCommands = commands
+		}
 	}
 
+	GetLogger().SetLevel(config.LogLevel)
+
+	// Create Fyne application
+	myApp := app.NewWithID("com.voicetranscriber.app")
+
+	// A key entered in the window on an earlier run stands in for OPENAI_API_KEY
+	loadSavedAPIKey(myApp.Preferences())
+	applySavedSettings(config, myApp.Preferences())
+
 	// Create application state
-	appState, err := NewAppState()
+	appState, err := NewAppState(ctx, config)
 	if err != nil {
-		log.Fatalf("Failed to initialize application: %v", err)
+		Fatalf("Failed to initialize application: %v", err)
 	}
 	defer appState.Cleanup()
 
-	// Create Fyne application
-	myApp := app.NewWithID("com.voicetranscriber.app")
-
 	// Set custom theme with white text
 	myApp.Settings().SetTheme(&CustomTheme{Theme: theme.DarkTheme()})
 
 	// Create main window
-	myWindow := myApp.NewWindow("MICAPP")
-	myWindow.Resize(fyne.NewSize(300, 700))  // Increased height to accommodate 500px editor + controls
-	myWindow.SetFixedSize(false)             // Allow resizing for better UX
-	myWindow.SetIcon(resourceRedcubeiconSvg) // Set red cube icon
+	myWindow := myApp.NewWindow(appWindowTitle)
+	restoreWindowSize(myWindow, myApp.Preferences()) // Last size, 300x700 the first time
+	myWindow.SetFixedSize(false)                     // Allow resizing for better UX
+	myWindow.SetIcon(resourceRedcubeiconSvg)         // Set red cube icon
+	appState.mainWindow = myWindow
+
+	// Remember the window the user switches to, the dictation target of recordings started here
+	appState.trackOtherWindow(myApp)
 
 	// Create UI widgets
-	appState.correctedText = widget.NewMultiLineEntry()
+	editor := newEditorEntry()
+	appState.correctedText = &editor.Entry
 	appState.correctedText.SetPlaceHolder("Transcribed and corrected text will appear here...")
 	appState.correctedText.TextStyle = fyne.TextStyle{
 		Bold: true,
 	}
-	appState.correctedText.Wrapping = fyne.TextWrapWord
-	appState.correctedText.MultiLine = true
+
+	// Restore the previous session's text and keep it saved while editing
+	appState.correctedText.SetText(appState.loadSession())
+	appState.watchSession()
 
 	// Use text entry directly
-	textContainer := appState.correctedText
+	textContainer := editor
 
 	appState.recordButton = widget.NewButton("Start", appState.onRecordButtonClick)
 	appState.recordButton.Resize(fyne.NewSize(100, 40))
@@ -1092,6 +1786,30 @@ func main() {
 	appState.addButton = widget.NewButton("Add", appState.onAddButtonClick)
 	appState.addButton.Resize(fyne.NewSize(100, 40))
 
+	appState.liveButton = widget.NewButton("Live", appState.onLiveButtonClick)
+	appState.liveButton.Resize(fyne.NewSize(100, 40))
+
+	appState.pauseButton = widget.NewButton("Pause", appState.onPauseButtonClick)
+
+	// GPT correction toggle, persisted across restarts.
+	// Items already being transcribed keep the setting they started with.
+	prefs := myApp.Preferences()
+	appState.usage = newUsageTracker(config, prefs)
+	appState.correctionEnabled = prefs.BoolWithFallback(correctionPrefKey, true)
+	correctionCheck := widget.NewCheck("GPT", nil)
+	correctionCheck.SetChecked(appState.correctionEnabled)
+	correctionCheck.OnChanged = func(enabled bool) {
+		appState.correctionEnabled = enabled
+		prefs.SetBool(correctionPrefKey, enabled)
+		Infof("LLM correction enabled: %v", enabled)
+	}
+
+	// Microphone selector, persisted across restarts
+	inputDeviceSelect := appState.newInputDeviceSelect(prefs)
+
+	// Transcription language, remembered across restarts
+	languageSelect := appState.newLanguageSelect(prefs)
+
 	// Create clickable status label
 	statusLabelWidget := newClickableStatusLabel(appState.correctedText)
 	statusLabelWidget.SetText("Ready")
@@ -1099,35 +1817,28 @@ func main() {
 	appState.statusLabel = statusLabelWidget
 
 	// Create stored audio list
-	appState.storedAudioList = widget.NewList(
-		func() int {
-			audioFiles, _ := appState.audioStorage.GetStoredAudioFiles()
-			return len(audioFiles)
-		},
-		func() fyne.CanvasObject {
-			return widget.NewLabel("Template")
-		},
-		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			audioFiles, _ := appState.audioStorage.GetStoredAudioFiles()
-			if id < len(audioFiles) {
-				file := audioFiles[id]
-				label := obj.(*widget.Label)
-				label.SetText(fmt.Sprintf("%s (%dkbps, %s)",
-					file.Filename,
-					file.Bitrate,
-					file.Timestamp.Format("15:04:05")))
-			}
-		},
-	)
+	appState.audioDetailsLabel = widget.NewLabel("")
+	appState.storedAudioList = appState.newStoredAudioList()
 
 	// Create queue indicators container
 	queueContainer := container.NewHBox()
 	appState.queueContainer = queueContainer // Set reference in AppState
 
+	// Without an API key recording stays disabled until one is entered in the banner
+	apiKeyBanner := appState.newAPIKeyBanner(prefs)
+	appState.checkAPIKeyOnStart(prefs)
+
 	// Create layout using Border Layout (Method 1)
 	buttonContainer := container.NewHBox(
 		appState.recordButton,
 		appState.addButton,
+		appState.liveButton,
+		appState.pauseButton,
+		correctionCheck,
+		languageSelect,
+		inputDeviceSelect,
+		widget.NewButton("New session", appState.newSession),
+		appState.newExportButton(),
 		widget.NewSeparator(),
 		queueContainer,
 	)
@@ -1137,30 +1848,72 @@ func main() {
 	appState.imageContainer = imageContainer
 
 	// Create status container with image
+	appState.levelMeter = newLevelMeter()
 	statusContainer := container.NewVBox(
 		appState.statusLabel,
+		appState.levelMeter,
 		widget.NewSeparator(),
 		imageContainer,
 		widget.NewSeparator(),
 	)
 
+	// Create status container with the collapsible list of LLM corrections
+	statusContainer.Add(appState.newCorrectionPanel())
+	statusContainer.Add(appState.usage.newUsageLabel())
+
+	// Show the find bar above the editor and the word and character count under it
+	appState.findBar = appState.newFindBar()
+	editorContent := container.NewBorder(appState.findBar.content, appState.newTextStatsLabel(), nil, nil, container.NewScroll(textContainer))
+
 	// Create main content using Border Layout
 	mainContent := container.NewBorder(
-		buttonContainer,                    // Top: controls
-		statusContainer,                    // Bottom: status
-		nil,                                // Left: none
-		nil,                                // Right: none
-		container.NewScroll(textContainer), // Center: text editor fills remaining space
+		container.NewVBox(apiKeyBanner, buttonContainer), // Top: API key prompt and controls
+		statusContainer, // Bottom: status
+		nil,             // Left: none
+		nil,             // Right: none
+		editorContent,   // Center: text editor fills remaining space
 	)
 
-	audioTab := container.NewVBox(
+	clearRecordingsButton := widget.NewButton("Clear recordings", func() {
+		dialog.ShowConfirm("Clear recordings",
+			"Delete all stored recordings and their transcripts?",
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				removed, err := appState.audioStorage.ClearRecordings()
+				if err != nil {
+					setStatusText(appState.statusLabel, fmt.Sprintf("Clear failed: %v", err))
+					return
+				}
+				appState.storedAudioList.UnselectAll()
+				appState.audioDetailsLabel.SetText("")
+				appState.storedAudioList.Refresh()
+				setStatusText(appState.statusLabel, fmt.Sprintf("Deleted %d recordings", removed))
+			}, myWindow)
+	})
+
+	audioTab := container.NewBorder(
 		widget.NewLabel("Stored Audio Files"),
+		container.NewVBox(
+			appState.audioDetailsLabel,
+			container.NewHBox(
+				widget.NewButton("Export subtitles...", appState.onExportSubtitlesClick),
+				clearRecordingsButton,
+			),
+		),
+		nil,
+		nil,
 		appState.storedAudioList,
 	)
 
+	appState.historyTab = appState.newHistoryTab()
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Text Editor", mainContent),
 		container.NewTabItem("Audio Files", audioTab),
+		container.NewTabItem("History", appState.historyTab.content),
+		container.NewTabItem("Capture", appState.newCaptureTab()),
+		container.NewTabItem("Settings", appState.newSettingsTab(prefs, languageSelect, inputDeviceSelect, correctionCheck)),
 	)
 
 	content := tabs
@@ -1170,21 +1923,21 @@ func main() {
 	// Add Escape key handler to cancel recording
 	myWindow.Canvas().SetOnTypedKey(func(event *fyne.KeyEvent) {
 		if event.Name == fyne.KeyEscape {
-			log.Printf("=== ESCAPE KEY PRESSED ===")
-			log.Printf("ESC key pressed - isRecording: %v", appState.isRecording)
+			Debugf("=== ESCAPE KEY PRESSED ===")
+			Infof("ESC key pressed - isRecording: %v", appState.isRecording)
 
 			// Check ONLY isRecording flag - if recording is active, cancel it
 			if appState.isRecording {
-				log.Printf("ESC: Canceling recording...")
+				Infof("ESC: Canceling recording...")
 				err := appState.CancelRecording()
 				if err != nil {
-					log.Printf("ESC: Failed to cancel: %v", err)
+					Errorf("ESC: Failed to cancel: %v", err)
 					setStatusText(appState.statusLabel, fmt.Sprintf("Cancel error: %v", err))
 				} else {
-					log.Printf("ESC: Recording canceled, interface reset to initial state")
+					Infof("ESC: Recording canceled, interface reset to initial state")
 				}
 			} else {
-				log.Printf("ESC: No active recording to cancel (isRecording=%v)", appState.isRecording)
+				Infof("ESC: No active recording to cancel (isRecording=%v)", appState.isRecording)
 			}
 		} else if event.Name == fyne.KeyC {
 			// Ctrl+C: Copy all text to clipboard using xclip
@@ -1202,43 +1955,80 @@ func main() {
 		}
 	})
 
-	// Start mouse hook for Ctrl+drag screenshot capture
-	appState.startMouseHook()
+	// Ctrl+Enter: stop and keep the partial recording
+	myWindow.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyReturn,
+		Modifier: fyne.KeyModifierControl,
+	}, func(shortcut fyne.Shortcut) {
+		Infof("Ctrl+Enter pressed, finalizing recording")
+		if err := appState.FinalizeRecording(); err != nil {
+			Errorf("Failed to finalize recording: %v", err)
+			setStatusText(appState.statusLabel, fmt.Sprintf("Finalize error: %v", err))
+		}
+	})
+
+	// Ctrl+F: find and replace in the editor, also while the editor has focus
+	findShortcut := &desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: fyne.KeyModifierControl}
+	myWindow.Canvas().AddShortcut(findShortcut, func(shortcut fyne.Shortcut) {
+		appState.findBar.toggle()
+	})
+	editor.addShortcut(findShortcut, appState.findBar.toggle)
+
+	// Ctrl+Shift+V: transcribe audio from the clipboard
+	myWindow.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyV,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift,
+	}, func(shortcut fyne.Shortcut) {
+		Infof("Ctrl+Shift+V pressed, transcribing clipboard audio")
+		appState.transcribeClipboardAudio()
+	})
+
+	// Start mouse hook for Ctrl+drag screenshot capture, unless capture is disabled
+	appState.startMouseHook(ctx)
 	defer appState.stopMouseHook()
 
-	// Set close intercept to close image editor window if open
-	myWindow.SetCloseIntercept(func() {
+	// Set close intercept to stop background workers and close image editor window if open
+	shutdown := func() {
+		// Signal background goroutines to exit before Cleanup runs
+		cancel()
+
+		// Save the editor text and window geometry for the next run
+		appState.saveSession()
+		saveWindowGeometry(myWindow, prefs, myWindow.Title(), config.WindowPosition)
+
 		// Close image editor window if it's open
 		if appState.imageEditorWindow != nil {
-			log.Printf("Closing image editor window along with main window")
+			Infof("Closing image editor window along with main window")
 			appState.imageEditorWindow.Close()
 			appState.imageEditorWindow = nil
 		}
 		// Close main window
 		myWindow.Close()
+	}
+	myWindow.SetCloseIntercept(func() {
+		// Ask before discarding an in-flight recording or transcription
+		busy := appState.busyActivity()
+		if busy == "" {
+			shutdown()
+			return
+		}
+		Infof("Close requested while %s is in progress, asking for confirmation", busy)
+		dialog.ShowConfirm("Quit MICAPP?",
+			fmt.Sprintf("%s in progress — quit anyway?", busy),
+			func(confirmed bool) {
+				if confirmed {
+					shutdown()
+				}
+			}, myWindow)
 	})
 
 	// Show window first
 	myWindow.Show()
 
-	// Move window to X=0, Y=200 position (Linux only, using xdotool)
-	// This is done after Show() to ensure window is created
-	go func() {
-		// Small delay to ensure window is fully created
-		time.Sleep(200 * time.Millisecond)
-
-		// Try to move window using xdotool
-		// First, find the window by name and move it
-		cmd := exec.Command("sh", "-c", "xdotool search --name 'MICAPP' | head -1 | xargs -I {} xdotool windowmove {} 0 200")
-		if err := cmd.Run(); err != nil {
-			// If xdotool fails, try alternative method using wmctrl
-			log.Printf("xdotool failed, trying wmctrl: %v", err)
-			cmd2 := exec.Command("wmctrl", "-r", "MICAPP", "-e", "0,0,200,-1,-1")
-			if err2 := cmd2.Run(); err2 != nil {
-				log.Printf("Failed to set window position: %v (xdotool), %v (wmctrl). Window may appear at default position.", err, err2)
-			}
-		}
-	}()
+	// Fyne can't position windows, so the saved position is restored externally if enabled
+	if config.WindowPosition {
+		go restoreWindowPosition(ctx, prefs, myWindow.Title())
+	}
 
 	// Run application
 	myApp.Run()
@@ -0,0 +1,427 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestMain(m *testing.M) {
+	// Log warnings to stderr instead of creating app.log in the package directory
+	globalLogger = newTestLogger(os.Stderr, WARN, LogFormatText)
+	// fyne.Do needs a current app; the test driver runs the updates right away
+	test.NewApp()
+	os.Exit(m.Run())
+}
+
+// newTestLogger returns a logger writing only to w
+func newTestLogger(w io.Writer, level LogLevel, format LogFormat) *AppLogger {
+	logger := &AppLogger{format: format, logger: log.New(w, "", 0)}
+	logger.level.Store(int32(level))
+	return logger
+}
+
+// newTestAppState returns the state the background goroutines need, without
+// PortAudio, the recordings folder or any widgets
+func newTestAppState(ctx context.Context) *AppState {
+	return &AppState{ctx: ctx, config: &Config{}, jobQueue: make(chan transcriptionJob)}
+}
+
+// waitDone fails the test unless done is closed within a second
+func waitDone(t *testing.T, name string, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("%s did not stop after the context was cancelled", name)
+	}
+}
+
+func TestProcessingStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a := newTestAppState(ctx)
+
+	if a.processingCanceled() {
+		t.Fatal("processing reported as canceled before the context was cancelled")
+	}
+	cancel()
+	if !a.processingCanceled() {
+		t.Fatal("processing not reported as canceled after the context was cancelled")
+	}
+
+	// A transcription still waiting to start gives up without calling the API
+	if _, err := a.transcribeWithRetry(ctx, []byte("audio"), "recording.wav", "en", time.Second); err == nil {
+		t.Error("transcribeWithRetry succeeded after shutdown")
+	}
+}
+
+func TestReserveAddSpaceEmptyEditor(t *testing.T) {
+	for _, text := range []string{"", "   ", "\n\n", " \n\t"} {
+		got, reserved := reserveAddSpaceText(text)
+		if got != "" || reserved {
+			t.Errorf("reserveAddSpaceText(%q) = %q, %v; want \"\", false", text, got, reserved)
+		}
+		if after := unreserveAddSpaceText(got, reserved); after != "" {
+			t.Errorf("unreserveAddSpaceText after reserving in %q = %q, want \"\"", text, after)
+		}
+	}
+}
+
+func TestReserveAddSpaceNonEmptyEditor(t *testing.T) {
+	got, reserved := reserveAddSpaceText("First paragraph.\n")
+	if want := "First paragraph." + addModeSeparator; got != want || !reserved {
+		t.Fatalf("reserveAddSpaceText = %q, %v; want %q, true", got, reserved, want)
+	}
+	if after := unreserveAddSpaceText(got, reserved); after != "First paragraph." {
+		t.Errorf("unreserveAddSpaceText = %q, want %q", after, "First paragraph.")
+	}
+	// Nothing was reserved, so the user's own trailing blank line stays
+	if after := unreserveAddSpaceText(got, false); after != got {
+		t.Errorf("unreserveAddSpaceText without a reservation = %q, want %q", after, got)
+	}
+}
+
+func TestReserveAddSpaceCyclesDoNotAccumulate(t *testing.T) {
+	for _, start := range []string{"", "Some text"} {
+		text := start
+		for i := 0; i < 5; i++ {
+			// An "add" recording that produced no text reserves and releases the space
+			var reserved bool
+			text, reserved = reserveAddSpaceText(text)
+			text = unreserveAddSpaceText(text, reserved)
+			text = unreserveAddSpaceText(text, false) // A second release changes nothing
+		}
+		if text != start {
+			t.Errorf("after five empty add cycles from %q the text is %q", start, text)
+		}
+	}
+}
+
+// roundTripFunc answers HTTP requests in tests without the network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// runProcessAudio runs processAudio on samples and returns the job it queued, if any
+func runProcessAudio(t *testing.T, a *AppState, samples []int16) (transcriptionJob, bool) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		a.processAudio(samples)
+		close(done)
+	}()
+
+	select {
+	case job := <-a.jobQueue:
+		waitDone(t, "processAudio", done)
+		return job, true
+	case <-done:
+		return transcriptionJob{}, false
+	case <-time.After(5 * time.Second):
+		t.Fatal("processAudio neither queued a job nor returned")
+		return transcriptionJob{}, false
+	}
+}
+
+func TestProcessAudioFinalizeKeepsPartialBuffer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newState := func() *AppState {
+		a := newTestAppState(ctx)
+		a.sampleRate = recordingSampleRate
+		a.channels = 1
+		a.recordingMode = "start"
+		a.config.RecordingBitrate = 64
+		a.audioStorage = &AudioStorage{baseDir: t.TempDir()}
+		return a
+	}
+
+	// One second is below the 3 second minimum but above Whisper's 0.1s
+	partial := make([]int16, recordingSampleRate)
+	for i := range partial {
+		partial[i] = int16(1000 * (i % 2))
+	}
+
+	t.Run("finalized", func(t *testing.T) {
+		a := newState()
+		a.finalizeRequested = true
+		job, queued := runProcessAudio(t, a, partial)
+		if !queued {
+			t.Fatal("finalized partial recording was not queued")
+		}
+		if len(job.audioData) != 2*len(partial) {
+			t.Errorf("queued %d bytes, want %d", len(job.audioData), 2*len(partial))
+		}
+		if a.finalizeRequested {
+			t.Error("finalize request was not consumed")
+		}
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		a := newState()
+		if _, queued := runProcessAudio(t, a, partial); queued {
+			t.Error("partial recording below the minimum was queued without finalize")
+		}
+	})
+
+	t.Run("finalized below whisper minimum", func(t *testing.T) {
+		a := newState()
+		a.finalizeRequested = true
+		if _, queued := runProcessAudio(t, a, partial[:recordingSampleRate/20]); queued {
+			t.Error("recording shorter than 0.1s was queued")
+		}
+	})
+}
+
+func TestFinalizeRecordingWithoutRecording(t *testing.T) {
+	a := newTestAppState(context.Background())
+	if err := a.FinalizeRecording(); err == nil {
+		t.Fatal("FinalizeRecording succeeded without an active recording")
+	}
+	if a.finalizeRequested {
+		t.Error("finalize request left set after the error")
+	}
+}
+
+func TestBusyActivity(t *testing.T) {
+	tests := []struct {
+		name       string
+		recording  bool
+		processing bool
+		queued     int
+		want       string
+	}{
+		{"idle", false, false, 0, ""},
+		{"recording", true, false, 0, "Recording"},
+		{"processing", false, true, 0, "Transcription"},
+		{"queued", false, false, 2, "Transcription"},
+		{"recording while processing", true, true, 1, "Recording"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAppState(context.Background())
+			a.isRecording = tt.recording
+			a.isProcessing = tt.processing
+			for i := 0; i < tt.queued; i++ {
+				a.enqueueTranscription("start")
+			}
+			if got := a.busyActivity(); got != tt.want {
+				t.Errorf("busyActivity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeWhisper answers Whisper uploads with canned responses and records
+// the content of each uploaded file
+type fakeWhisper struct {
+	mu        sync.Mutex
+	uploads   []string
+	responses []*http.Response // Returned by successive uploads; later uploads succeed with text
+	text      string
+}
+
+func (f *fakeWhisper) client() *OpenAiSpeechClient {
+	return &OpenAiSpeechClient{apiKey: "test", client: &http.Client{Transport: roundTripFunc(f.roundTrip)}}
+}
+
+func (f *fakeWhisper) roundTrip(req *http.Request) (*http.Response, error) {
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	call := len(f.uploads)
+	f.uploads = append(f.uploads, string(data))
+	if call < len(f.responses) {
+		return f.responses[call], nil
+	}
+	body, err := json.Marshal(map[string]string{"text": f.text})
+	if err != nil {
+		return nil, err
+	}
+	return textResponse(http.StatusOK, string(body)), nil
+}
+
+func textResponse(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+// installFakeFFmpeg puts an ffmpeg on PATH that prints its arguments instead of
+// encoding, so tests can see which bitrate a conversion asked for
+func installFakeFFmpeg(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "ffmpeg"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake ffmpeg: %v", err)
+	}
+	t.Setenv("PATH", dir)
+
+	// ffmpegAvailable caches its lookup; look again now and after the test
+	ffmpegOnce = sync.Once{}
+	t.Cleanup(func() { ffmpegOnce = sync.Once{} })
+}
+
+func TestProcessQueueItemRetriesTooLargeAtLowerBitrate(t *testing.T) {
+	installFakeFFmpeg(t)
+
+	a := newTestAppState(context.Background())
+	a.audioStorage = &AudioStorage{baseDir: t.TempDir()}
+	a.usage = newUsageTracker(a.config, nil)
+	whisper := &fakeWhisper{responses: []*http.Response{
+		textResponse(http.StatusBadRequest, `{"error": {"message": "Maximum content size limit exceeded"}}`),
+	}}
+	a.setClients(whisper.client(), nil)
+
+	a.processQueueItem(transcriptionJob{audioData: make([]byte, 2*recordingSampleRate), sampleRate: recordingSampleRate, mode: "start"})
+
+	if len(whisper.uploads) != 2 {
+		t.Fatalf("got %d uploads, want the original and one retry", len(whisper.uploads))
+	}
+	if first := whisper.uploads[0]; !strings.Contains(first, "-b:a 128k") {
+		t.Errorf("first upload was not encoded at 128 kbps: %q", first)
+	}
+	if retry := whisper.uploads[1]; !strings.Contains(retry, fmt.Sprintf("-b:a %dk", fallbackBitrate)) {
+		t.Errorf("retry was not re-encoded at %d kbps: %q", fallbackBitrate, retry)
+	}
+}
+
+func TestProcessQueueItemDoesNotReencodeOtherErrors(t *testing.T) {
+	installFakeFFmpeg(t)
+
+	a := newTestAppState(context.Background())
+	a.audioStorage = &AudioStorage{baseDir: t.TempDir()}
+	a.usage = newUsageTracker(a.config, nil)
+	whisper := &fakeWhisper{responses: []*http.Response{
+		textResponse(http.StatusBadRequest, `{"error": {"message": "bad request"}}`),
+	}}
+	a.setClients(whisper.client(), nil)
+
+	a.processQueueItem(transcriptionJob{audioData: make([]byte, 2*recordingSampleRate), sampleRate: recordingSampleRate, mode: "start"})
+
+	if len(whisper.uploads) != 1 {
+		t.Errorf("got %d uploads, want no re-encoded retry", len(whisper.uploads))
+	}
+}
+
+func TestTranscribeJobResult(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		whisper      *fakeWhisper
+		cancel       bool
+		wantText     string
+		wantErr      bool
+		wantCanceled bool
+		wantUploads  int
+	}{
+		{"start", "start", &fakeWhisper{text: "  hello world \n"}, false, "hello world", false, false, 1},
+		{"add", "add", &fakeWhisper{text: "more text"}, false, "more text", false, false, 1},
+		{"no speech", "start", &fakeWhisper{text: "   "}, false, "", false, false, 1},
+		{"rejected", "start", &fakeWhisper{responses: []*http.Response{
+			textResponse(http.StatusUnauthorized, `{"error": {"message": "invalid api key"}}`),
+		}}, false, "", true, false, 1},
+		{"no transcriber", "start", nil, false, "", true, false, 0},
+		{"canceled before start", "add", &fakeWhisper{text: "unused"}, true, "", false, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAppState(context.Background())
+			a.audioStorage = &AudioStorage{baseDir: t.TempDir()}
+			a.usage = newUsageTracker(a.config, nil)
+			a.selectedLanguage = "en"
+			a.shouldCancel = tt.cancel
+			if tt.whisper != nil {
+				a.setClients(tt.whisper.client(), nil)
+			}
+
+			job := transcriptionJob{audioData: make([]byte, 2*recordingSampleRate), sampleRate: recordingSampleRate, mode: tt.mode}
+			result := a.transcribeJob(job)
+
+			if result.Mode != tt.mode {
+				t.Errorf("Mode = %q, want %q", result.Mode, tt.mode)
+			}
+			if result.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", result.Text, tt.wantText)
+			}
+			if (result.Err != nil) != tt.wantErr {
+				t.Errorf("Err = %v, want error: %v", result.Err, tt.wantErr)
+			}
+			if tt.whisper == nil && !errors.Is(result.Err, errMissingAPIKey) {
+				t.Errorf("Err = %v, want %v", result.Err, errMissingAPIKey)
+			}
+			if result.Canceled != tt.wantCanceled {
+				t.Errorf("Canceled = %v, want %v", result.Canceled, tt.wantCanceled)
+			}
+			if tt.wantText != "" && result.Language != "en" {
+				t.Errorf("Language = %q, want %q", result.Language, "en")
+			}
+			if tt.whisper != nil && len(tt.whisper.uploads) != tt.wantUploads {
+				t.Errorf("got %d uploads, want %d", len(tt.whisper.uploads), tt.wantUploads)
+			}
+		})
+	}
+}
+
+func TestApplyTranscription(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		text    string
+		mode    string
+		want    string
+	}{
+		{"start replaces", "old text", "new text", "start", "new text"},
+		{"start on empty editor", "", "new text", "start", "new text"},
+		{"add appends after reserved separator", "old text" + addModeSeparator, "new text", "add", "old text" + addModeSeparator + "new text"},
+		{"add to empty editor", "", "new text", "add", "new text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyTranscription(tt.current, tt.text, tt.mode); got != tt.want {
+				t.Errorf("applyTranscription(%q, %q, %q) = %q, want %q", tt.current, tt.text, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
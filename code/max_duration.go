@@ -0,0 +1,84 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// maxDurationWarning is how long before the recording limit the status bar starts counting down
+const maxDurationWarning = 30 * time.Second
+
+// trackRecordedSamples adds a buffer from the audio callback to the length of the
+// current recording, which runMaxDuration compares with the limit
+func (a *AppState) trackRecordedSamples(samples []int16) {
+	a.recordedSamples.Add(int64(len(samples)))
+}
+
+// recordedDuration returns how much audio the current recording has captured so far
+func (a *AppState) recordedDuration() time.Duration {
+	if a.sampleRate == 0 {
+		return 0
+	}
+	return time.Duration(a.recordedSamples.Load()) * time.Second / time.Duration(a.samplesPerSecond())
+}
+
+// runMaxDuration stops the given recording once it reaches the configured maximum
+// length and transcribes what was captured, so a forgotten recording can't grow
+// without bound or exceed the upload limit. The status bar counts down for the
+// last maxDurationWarning. Paused time doesn't count. Live recordings have no
+// limit, since their chunks are transcribed and taken out as they go.
+func (a *AppState) runMaxDuration(stream *portaudio.Stream) {
+	limit := a.config.MaxRecording
+	if limit <= 0 || a.continuous {
+		return
+	}
+	Debugf("Max duration: stopping the recording after %v", limit)
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	lastShown := time.Duration(-1)
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !a.isCurrentRecording(stream) {
+			return
+		}
+
+		remaining := limit - a.recordedDuration()
+		if remaining > maxDurationWarning {
+			continue
+		}
+		if remaining > 0 {
+			// Only update the status when the whole second changes
+			if seconds := remaining.Round(time.Second); seconds != lastShown {
+				lastShown = seconds
+				postStatusText(a.statusLabel, fmt.Sprintf("Recording limit of %s reached in %v, then transcribing", formatMinutes(limit), seconds))
+			}
+			continue
+		}
+
+		Warnf("Max duration: recording reached the %v limit, stopping", limit)
+		a.stopRecordingOnUI(stream, fmt.Sprintf("Recording limit of %s reached, transcribing...", formatMinutes(limit)))
+		return
+	}
+}
@@ -0,0 +1,141 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	hook "github.com/robotn/gohook"
+)
+
+// libuiohook virtual key codes reported in hook.Event.Keycode. Unlike raw codes
+// they are the same on X11, Windows and macOS and don't depend on the layout.
+const (
+	vcShiftL   = 0x002A
+	vcShiftR   = 0x0036
+	vcControlL = 0x001D
+	vcControlR = 0x0E1D
+	vcAltL     = 0x0038
+	vcAltR     = 0x0E38
+	vcMetaL    = 0x0E5B
+	vcMetaR    = 0x0E5C
+)
+
+// libuiohook modifier bits reported in hook.Event.Mask. On key events the mask
+// already includes a pressed modifier and no longer includes a released one.
+const (
+	maskShiftL = 1 << 0
+	maskCtrlL  = 1 << 1
+	maskMetaL  = 1 << 2
+	maskAltL   = 1 << 3
+	maskShiftR = 1 << 4
+	maskCtrlR  = 1 << 5
+	maskMetaR  = 1 << 6
+	maskAltR   = 1 << 7
+
+	maskCtrl = maskCtrlL | maskCtrlR
+)
+
+// modifierNames lists the modifier bits in the order describeModifiers prints them
+var modifierNames = []struct {
+	mask uint16
+	name string
+}{
+	{maskCtrlL, "LeftCtrl"},
+	{maskCtrlR, "RightCtrl"},
+	{maskShiftL, "LeftShift"},
+	{maskShiftR, "RightShift"},
+	{maskAltL, "LeftAlt"},
+	{maskAltR, "RightAlt"},
+	{maskMetaL, "LeftMeta"},
+	{maskMetaR, "RightMeta"},
+}
+
+// captureModifiers reports whether Ctrl (either side) and Left Shift are held
+// according to a key event. The modifier mask is authoritative; the key code of
+// the event itself is checked as well in case a platform updates the mask late.
+func captureModifiers(ev hook.Event) (ctrl bool, leftShift bool) {
+	ctrl = ev.Mask&maskCtrl != 0
+	leftShift = ev.Mask&maskShiftL != 0
+
+	switch ev.Keycode {
+	case vcControlL, vcControlR:
+		ctrl = ev.Kind == hook.KeyDown
+	case vcShiftL:
+		leftShift = ev.Kind == hook.KeyDown
+	}
+	return ctrl, leftShift
+}
+
+// isModifierKey reports whether keycode is a Shift, Ctrl, Alt or Meta key
+func isModifierKey(keycode uint16) bool {
+	switch keycode {
+	case vcShiftL, vcShiftR, vcControlL, vcControlR, vcAltL, vcAltR, vcMetaL, vcMetaR:
+		return true
+	}
+	return false
+}
+
+// describeModifiers returns the held modifiers in mask as e.g. "LeftCtrl+LeftShift"
+func describeModifiers(mask uint16) string {
+	var names []string
+	for _, modifier := range modifierNames {
+		if mask&modifier.mask != 0 {
+			names = append(names, modifier.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "+")
+}
+
+// runModifierDebug prints every key event with the modifiers detected from it
+// until interrupted. It is started with "debug-modifiers" as the first argument
+// so users can check what the screenshot hotkey sees on their system.
+func runModifierDebug() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("Press keys to see the detected modifiers; Ctrl+C in this terminal to quit.")
+	fmt.Println("The screenshot hotkey needs ctrl=true and leftShift=true while held.")
+
+	events := hook.Start()
+	defer hook.End()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if ev.Kind != hook.KeyDown && ev.Kind != hook.KeyUp {
+				continue
+			}
+			direction := "down"
+			if ev.Kind == hook.KeyUp {
+				direction = "up"
+			}
+			ctrl, leftShift := captureModifiers(ev)
+			fmt.Printf("%-4s keycode=%#04x rawcode=%d mask=%#04x modifiers=%s ctrl=%v leftShift=%v\n",
+				direction, ev.Keycode, ev.Rawcode, ev.Mask, describeModifiers(ev.Mask), ctrl, leftShift)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	hook "github.com/robotn/gohook"
+)
+
+// keyEvent returns a key event for keycode with the modifier mask libuiohook
+// reports after it: a pressed modifier is included, a released one is not
+func keyEvent(kind uint8, keycode uint16, mask uint16) hook.Event {
+	return hook.Event{Kind: kind, Keycode: keycode, Mask: mask}
+}
+
+func TestCaptureModifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		ev        hook.Event
+		wantCtrl  bool
+		wantShift bool
+	}{
+		{"left ctrl down", keyEvent(hook.KeyDown, vcControlL, maskCtrlL), true, false},
+		{"right ctrl down", keyEvent(hook.KeyDown, vcControlR, maskCtrlR), true, false},
+		{"left shift down with ctrl held", keyEvent(hook.KeyDown, vcShiftL, maskCtrlL|maskShiftL), true, true},
+		{"right shift does not count", keyEvent(hook.KeyDown, vcShiftR, maskCtrlL|maskShiftR), true, false},
+		{"ctrl down before the mask updates", keyEvent(hook.KeyDown, vcControlL, 0), true, false},
+		{"ctrl up before the mask updates", keyEvent(hook.KeyUp, vcControlL, maskCtrlL|maskShiftL), false, true},
+		{"shift up", keyEvent(hook.KeyUp, vcShiftL, maskCtrlL), true, false},
+		{"other key with both held", keyEvent(hook.KeyDown, 0x001E, maskCtrlR|maskShiftL), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl, shift := captureModifiers(tt.ev)
+			if ctrl != tt.wantCtrl || shift != tt.wantShift {
+				t.Errorf("captureModifiers = (%v, %v), want (%v, %v)", ctrl, shift, tt.wantCtrl, tt.wantShift)
+			}
+		})
+	}
+}
+
+func TestModifierComboReleaseOrder(t *testing.T) {
+	press := []hook.Event{
+		keyEvent(hook.KeyDown, vcControlL, maskCtrlL),
+		keyEvent(hook.KeyDown, vcShiftL, maskCtrlL|maskShiftL),
+	}
+	tests := []struct {
+		name    string
+		release []hook.Event
+	}{
+		{"ctrl first", []hook.Event{
+			keyEvent(hook.KeyUp, vcControlL, maskShiftL),
+			keyEvent(hook.KeyUp, vcShiftL, 0),
+		}},
+		{"shift first", []hook.Event{
+			keyEvent(hook.KeyUp, vcShiftL, maskCtrlL),
+			keyEvent(hook.KeyUp, vcControlL, 0),
+		}},
+		{"ctrl first with late mask", []hook.Event{
+			keyEvent(hook.KeyUp, vcControlL, maskCtrlL|maskShiftL),
+			keyEvent(hook.KeyUp, vcShiftL, maskShiftL),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var combo modifierCombo
+			starts, ends := 0, 0
+			for _, ev := range append(append([]hook.Event(nil), press...), tt.release...) {
+				started, ended := combo.set(captureModifiers(ev))
+				if started {
+					starts++
+				}
+				if ended {
+					ends++
+					if ev.Kind != hook.KeyUp || ev.Keycode != tt.release[0].Keycode {
+						t.Errorf("combination ended on %+v, want the first release", ev)
+					}
+				}
+			}
+			if starts != 1 || ends != 1 {
+				t.Errorf("combination started %d and ended %d times, want once each", starts, ends)
+			}
+			if combo.active() || combo.ctrl || combo.shift {
+				t.Errorf("combo still holds keys after both were released: %+v", combo)
+			}
+		})
+	}
+}
+
+func TestModifierComboShiftPressedFirst(t *testing.T) {
+	var combo modifierCombo
+	if started, _ := combo.set(captureModifiers(keyEvent(hook.KeyDown, vcShiftL, maskShiftL))); started {
+		t.Fatal("combination started with only Shift held")
+	}
+	if started, _ := combo.set(captureModifiers(keyEvent(hook.KeyDown, vcControlR, maskShiftL|maskCtrlR))); !started {
+		t.Fatal("combination did not start when Ctrl joined Shift")
+	}
+	// Key repeat while both are held must not restart the selection
+	if started, ended := combo.set(captureModifiers(keyEvent(hook.KeyDown, vcControlR, maskShiftL|maskCtrlR))); started || ended {
+		t.Errorf("key repeat reported started=%v ended=%v", started, ended)
+	}
+}
+
+func TestLogKeyEvent(t *testing.T) {
+	var buf bytes.Buffer
+	logger := GetLogger()
+	logger.logger.SetOutput(&buf)
+	defer logger.logger.SetOutput(os.Stderr)
+	defer logger.SetLevel(logger.GetLevel())
+
+	captureKey := hook.Keycode["f12"]
+	// A typed letter: the raw code is the X11 keysym, which would reveal it
+	letter := hook.Event{Kind: hook.KeyDown, Keycode: 0x0010, Rawcode: 4242, Keychar: 'q', Mask: maskShiftL}
+	ctrl := keyEvent(hook.KeyDown, vcControlL, maskCtrlL)
+
+	tests := []struct {
+		name      string
+		level     LogLevel
+		keystroke bool
+		ev        hook.Event
+		want      []string
+		forbidden []string
+	}{
+		{"info with opt-in", INFO, true, letter, nil, nil},
+		{"info modifier with opt-in", INFO, true, ctrl, nil, nil},
+		{"debug without opt-in", DEBUG, false, ctrl, nil, nil},
+		{"debug letter", DEBUG, true, letter, []string{"Key event", "other", "LeftShift"}, []string{"4242", "keycode", "rawcode", "q"}},
+		{"debug modifier", DEBUG, true, ctrl, []string{"Key event", "keycode", "29", "LeftCtrl"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.SetLevel(tt.level)
+			buf.Reset()
+			a := newTestAppState(context.Background())
+			a.config.LogKeystrokes = tt.keystroke
+
+			a.logKeyEvent("down", tt.ev, captureKey)
+			a.logKeyEvent("up", tt.ev, captureKey)
+
+			output := buf.String()
+			if tt.want == nil && output != "" {
+				t.Fatalf("logged key events: %q", output)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("log %q does not contain %q", output, want)
+				}
+			}
+			for _, forbidden := range tt.forbidden {
+				if strings.Contains(output, "="+forbidden) || strings.Contains(output, forbidden+"=") {
+					t.Errorf("log %q contains %q", output, forbidden)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/braheezy/shine-mp3/pkg/mp3"
+)
+
+// errMP3FormatUnsupported is returned by the built-in encoder for sample rates
+// and channel counts MP3 cannot store
+var errMP3FormatUnsupported = errors.New("audio format not supported by the MP3 encoder")
+
+// Layer III bitrates in kbps by frame header index (index 0 is "free format").
+// The encoder supports MPEG-2.5 (8 to 12 kHz) only up to 64 kbps.
+var (
+	mpeg1Bitrates  = []int{32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mpeg2Bitrates  = []int{8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mpeg25Bitrates = mpeg2Bitrates[:8]
+)
+
+// maxGranuleBits is the most audio data one granule of one channel can hold;
+// the encoder cannot pad frames with more
+const maxGranuleBits = 4095
+
+// mp3FrameFits reports whether the encoder can fill every frame at bitrate.
+// Frames of high bitrates at low sample rates have more room than their
+// granules can use, and the encoder would write them short.
+func mp3FrameFits(sampleRate uint32, numChannels uint16, granules int, bitrate int) bool {
+	sideInfoBytes := 4 + 9 // Header and mono side info for one granule
+	switch {
+	case granules == 2 && numChannels == 2:
+		sideInfoBytes = 4 + 32
+	case granules == 2:
+		sideInfoBytes = 4 + 17
+	case numChannels == 2:
+		sideInfoBytes = 4 + 17
+	}
+	frameBits := granules*mp3.GRANULE_SIZE*bitrate*1000/int(sampleRate) + 8 // Up to one padding byte
+	return frameBits-sideInfoBytes*8 < maxGranuleBits*granules*int(numChannels)
+}
+
+// nearestMP3Bitrate returns the header index and value of the highest bitrate in
+// bitrates that does not exceed the requested one and fits a frame, or the lowest
+// if none does
+func nearestMP3Bitrate(bitrates []int, requested int, fits func(bitrate int) bool) (int, int) {
+	index := 0
+	for i, bitrate := range bitrates {
+		if bitrate <= requested && fits(bitrate) {
+			index = i
+		}
+	}
+	return index + 1, bitrates[index]
+}
+
+// encodeMP3 converts interleaved PCM data to MP3 with the built-in shine encoder,
+// which is used when ffmpeg is not installed. The bitrate is lowered to the
+// nearest one the sample rate allows; at 16 kHz mono that is at most 112 kbps.
+func encodeMP3(pcmData []byte, sampleRate uint32, numChannels uint16, bitrate int) ([]byte, error) {
+	if numChannels != 1 && numChannels != 2 {
+		return nil, fmt.Errorf("%w: %d channels", errMP3FormatUnsupported, numChannels)
+	}
+
+	// 32 kbps exists in every MPEG version, so only the sample rate is checked here
+	version, err := mp3.CheckConfig(int(sampleRate), 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d Hz", errMP3FormatUnsupported, sampleRate)
+	}
+	bitrates, granules := mpeg1Bitrates, 2
+	switch version {
+	case mp3.MPEG_II:
+		bitrates, granules = mpeg2Bitrates, 1
+	case mp3.MPEG_25:
+		bitrates, granules = mpeg25Bitrates, 1
+	}
+
+	// shine-mp3 sizes the side information of mono MPEG-1 frames as if they were
+	// MPEG-2 and writes broken frames, so mono audio at 32 kHz and above is
+	// encoded as two identical channels
+	channels := numChannels
+	if granules == 2 && numChannels == 1 {
+		channels = 2
+	}
+
+	bitrateIndex, actual := nearestMP3Bitrate(bitrates, bitrate, func(bitrate int) bool {
+		return mp3FrameFits(sampleRate, channels, granules, bitrate)
+	})
+	if actual != bitrate {
+		Debugf("MP3 encoder: %d kbps is not available at %d Hz, using %d kbps", bitrate, sampleRate, actual)
+	}
+
+	enc := mp3.NewEncoder(int(sampleRate), int(channels))
+	// NewEncoder always sets up 128 kbps frames; recompute the frame size for the
+	// chosen bitrate. Integer division keeps e.g. 504 bytes from becoming 503.99.
+	enc.Mpeg.Bitrate = int64(actual)
+	enc.Mpeg.BitrateIndex = int64(bitrateIndex)
+	frameBits := int64(granules * mp3.GRANULE_SIZE * actual * 1000)
+	slotBits := int64(sampleRate) * enc.Mpeg.BitsPerSlot
+	enc.Mpeg.WholeSlotsPerFrame = frameBits / slotBits
+	enc.Mpeg.FracSlotsPerFrame = float64(frameBits%slotBits) / float64(slotBits)
+	enc.Mpeg.SlotLag = -enc.Mpeg.FracSlotsPerFrame
+	enc.Mpeg.Padding = 0
+
+	samples := make([]int16, len(pcmData)/2*int(channels/numChannels))
+	for i := range len(pcmData) / 2 {
+		sample := int16(binary.LittleEndian.Uint16(pcmData[i*2:]))
+		if channels != numChannels {
+			samples[i*2], samples[i*2+1] = sample, sample
+		} else {
+			samples[i] = sample
+		}
+	}
+
+	// Each frame takes a fixed number of samples per channel; the encoder pads
+	// the last one with silence
+	var out bytes.Buffer
+	frameSamples := granules * mp3.GRANULE_SIZE * int(channels)
+	for start := 0; start < len(samples); start += frameSamples {
+		end := min(start+frameSamples, len(samples))
+		data, n := enc.EncodeBufferInterleaved(samples[start:end])
+		out.Write(data[:n])
+	}
+	return out.Bytes(), nil
+}
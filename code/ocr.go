@@ -0,0 +1,124 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// errTesseractMissing is returned by OCRImage when tesseract is not installed
+var errTesseractMissing = errors.New("tesseract not found in PATH")
+
+// ocrTimeout bounds a single tesseract run
+const ocrTimeout = 60 * time.Second
+
+// tesseractLanguages maps transcription language codes to tesseract language names.
+// Auto-detect has no equivalent, so tesseract falls back to its default (English).
+var tesseractLanguages = map[string]string{
+	"ru": "rus",
+	"en": "eng",
+	"uk": "ukr",
+	"de": "deu",
+	"fr": "fra",
+	"es": "spa",
+	"it": "ita",
+	"pt": "por",
+	"pl": "pol",
+}
+
+var (
+	tesseractOnce  sync.Once
+	tesseractFound bool
+)
+
+// tesseractAvailable reports whether the tesseract binary can be run. OCR is
+// optional, so a missing binary is only logged once.
+func tesseractAvailable() bool {
+	tesseractOnce.Do(func() {
+		path, err := exec.LookPath("tesseract")
+		tesseractFound = err == nil
+		if tesseractFound {
+			Infof("Using tesseract at %s for screenshot OCR", path)
+		} else {
+			Warnf("tesseract not found; screenshot OCR is disabled")
+		}
+	})
+	return tesseractFound
+}
+
+// OCRImage recognizes the text in a PNG image with tesseract, reading it in the
+// given transcription language when tesseract knows it
+func OCRImage(png []byte, language string) (string, error) {
+	if !tesseractAvailable() {
+		return "", errTesseractMissing
+	}
+
+	args := []string{"stdin", "stdout"}
+	if lang, ok := tesseractLanguages[language]; ok {
+		args = append(args, "-l", lang)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ocrTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "tesseract", args...)
+	cmd.Stdin = bytes.NewReader(png)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// recognizeScreenshotText runs OCR on an edited screenshot in the background and
+// appends the recognized text to the editor on its own line
+func (a *AppState) recognizeScreenshotText(png []byte) {
+	setStatusText(a.statusLabel, "Recognizing text in screenshot...")
+	go func() {
+		text, err := OCRImage(png, a.selectedLanguage)
+		switch {
+		case errors.Is(err, errTesseractMissing):
+			postStatusText(a.statusLabel, "OCR needs tesseract (sudo apt install tesseract-ocr)")
+			return
+		case err != nil:
+			Errorf("Screenshot OCR failed: %v", err)
+			postStatusText(a.statusLabel, fmt.Sprintf("OCR error: %v", err))
+			return
+		case text == "":
+			postStatusText(a.statusLabel, "No text found in screenshot")
+			return
+		}
+
+		Infof("Screenshot OCR recognized %d characters", len(text))
+		fyne.Do(func() {
+			current := a.correctedText.Text
+			if current != "" && !strings.HasSuffix(current, "\n") {
+				current += "\n"
+			}
+			a.correctedText.SetText(current + text)
+		})
+		postStatusText(a.statusLabel, "Screenshot text added")
+	}()
+}
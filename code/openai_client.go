@@ -15,19 +15,42 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
+// errAudioTooLarge is returned when the API rejects the upload because of its size
+var errAudioTooLarge = errors.New("audio file too large for upload")
+
+// defaultWhisperModel is the transcription model used when WHISPER_MODEL is unset
+const defaultWhisperModel = "whisper-1"
+
+// APIStatusError is returned when the API answers with a non-200 status
+type APIStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // Delay requested by a Retry-After header, 0 if none
+	Message    string
+}
+
+// Error returns the error message
+func (e *APIStatusError) Error() string {
+	return e.Message
+}
+
 // OpenAiSpeechClient handles communication with OpenAI's Whisper API
 type OpenAiSpeechClient struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	model   string // Transcription model written into the request form
+	client  *http.Client
 }
 
 // TranscriptionResponse represents the JSON response from OpenAI's transcription API
@@ -35,16 +58,45 @@ type TranscriptionResponse struct {
 	Text string `json:"text"`
 }
 
+// VerboseTranscriptionResponse represents the verbose_json response, which adds
+// timed segments to the text
+type VerboseTranscriptionResponse struct {
+	Text     string               `json:"text"`
+	Language string               `json:"language"`
+	Duration float64              `json:"duration"`
+	Segments []TranscriptionChunk `json:"segments"`
+}
+
+// TranscriptionChunk is a segment of a verbose_json response; times are in seconds
+type TranscriptionChunk struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
 // NewOpenAiSpeechClient creates a new OpenAI speech client
-// Reads the API key from the OPENAI_API_KEY environment variable
-func NewOpenAiSpeechClient() (*OpenAiSpeechClient, error) {
+// Reads the API key from the OPENAI_API_KEY environment variable.
+// baseURL is the API root (e.g. a proxy); empty uses the official API.
+// model selects the transcription model; empty uses whisper-1.
+func NewOpenAiSpeechClient(baseURL string, model string) (*OpenAiSpeechClient, error) {
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
 	}
 
+	baseURL, err := resolveBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if model == "" {
+		model = defaultWhisperModel
+	}
+
 	return &OpenAiSpeechClient{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   model,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -53,15 +105,66 @@ func NewOpenAiSpeechClient() (*OpenAiSpeechClient, error) {
 
 // Transcribe sends audio data to OpenAI's Whisper API for transcription
 // Parameters:
+//   - ctx: Cancels the upload or the wait for the response (e.g. on Escape)
 //   - wavBytes: WAV file data as byte slice
 //   - filename: Filename for the multipart form (typically "recording.wav")
 //   - language: Language code (e.g., "ru" for Russian, "en" for English, "auto" for auto-detection)
+//   - prompt: Optional vocabulary hint to bias spelling of names and jargon (empty for none)
 //   - onRequestSent: Optional callback called after request is sent, before waiting for response
 //
 // Returns:
 //   - string: Transcribed text
 //   - error: Any error that occurred during the API call
-func (c *OpenAiSpeechClient) Transcribe(wavBytes []byte, filename string, language string, onRequestSent ...func()) (string, error) {
+func (c *OpenAiSpeechClient) Transcribe(ctx context.Context, wavBytes []byte, filename string, language string, prompt string, onRequestSent ...func()) (string, error) {
+	body, err := c.requestTranscription(ctx, wavBytes, filename, language, prompt, "", onRequestSent)
+	if err != nil {
+		return "", err
+	}
+
+	// Parse JSON response
+	var transcriptionResp TranscriptionResponse
+	err = json.Unmarshal(body, &transcriptionResp)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse response JSON: %v", err)
+	}
+
+	return transcriptionResp.Text, nil
+}
+
+// TranscribeWithSegments works like Transcribe but requests verbose_json and also
+// returns the timed segments of the transcription. Models that don't support
+// verbose_json (such as gpt-4o-transcribe) reject the request.
+func (c *OpenAiSpeechClient) TranscribeWithSegments(ctx context.Context, wavBytes []byte, filename string, language string, prompt string, onRequestSent ...func()) (string, []TranscriptSegment, error) {
+	body, err := c.requestTranscription(ctx, wavBytes, filename, language, prompt, "verbose_json", onRequestSent)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var verboseResp VerboseTranscriptionResponse
+	if err := json.Unmarshal(body, &verboseResp); err != nil {
+		return "", nil, fmt.Errorf("failed to parse response JSON: %v", err)
+	}
+
+	segments := make([]TranscriptSegment, 0, len(verboseResp.Segments))
+	for _, chunk := range verboseResp.Segments {
+		segments = append(segments, TranscriptSegment{
+			Start: secondsToDuration(chunk.Start),
+			End:   secondsToDuration(chunk.End),
+			Text:  strings.TrimSpace(chunk.Text),
+		})
+	}
+
+	return verboseResp.Text, segments, nil
+}
+
+// secondsToDuration converts fractional seconds from the API to a Duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// requestTranscription uploads the audio and returns the raw response body.
+// prompt and responseFormat are only sent when not empty.
+func (c *OpenAiSpeechClient) requestTranscription(ctx context.Context, wavBytes []byte, filename string, language string, prompt string, responseFormat string, onRequestSent []func()) ([]byte, error) {
 	// Create multipart form data
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
@@ -69,18 +172,18 @@ func (c *OpenAiSpeechClient) Transcribe(wavBytes []byte, filename string, langua
 	// Add the audio file
 	fileWriter, err := writer.CreateFormFile("file", filename)
 	if err != nil {
-		return "", fmt.Errorf("failed to create form file: %v", err)
+		return nil, fmt.Errorf("failed to create form file: %v", err)
 	}
 
 	_, err = fileWriter.Write(wavBytes)
 	if err != nil {
-		return "", fmt.Errorf("failed to write audio data: %v", err)
+		return nil, fmt.Errorf("failed to write audio data: %v", err)
 	}
 
 	// Add model parameter
-	err = writer.WriteField("model", "whisper-1")
+	err = writer.WriteField("model", c.model)
 	if err != nil {
-		return "", fmt.Errorf("failed to write model field: %v", err)
+		return nil, fmt.Errorf("failed to write model field: %v", err)
 	}
 
 	// Add optional parameters for better transcription
@@ -88,35 +191,51 @@ func (c *OpenAiSpeechClient) Transcribe(wavBytes []byte, filename string, langua
 	if language != "auto" && language != "" {
 		err = writer.WriteField("language", language)
 		if err != nil {
-			return "", fmt.Errorf("failed to write language field: %v", err)
+			return nil, fmt.Errorf("failed to write language field: %v", err)
 		}
 	}
 
 	err = writer.WriteField("temperature", "0.0") // Use deterministic output
 	if err != nil {
-		return "", fmt.Errorf("failed to write temperature field: %v", err)
+		return nil, fmt.Errorf("failed to write temperature field: %v", err)
+	}
+
+	if prompt = truncatePrompt(prompt); prompt != "" {
+		err = writer.WriteField("prompt", prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write prompt field: %v", err)
+		}
+	}
+
+	if responseFormat != "" {
+		err = writer.WriteField("response_format", responseFormat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write response format field: %v", err)
+		}
 	}
 
 	// Close the writer to finalize the form
 	err = writer.Close()
 	if err != nil {
-		return "", fmt.Errorf("failed to close multipart writer: %v", err)
+		return nil, fmt.Errorf("failed to close multipart writer: %v", err)
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/audio/transcriptions", &buf)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/audio/transcriptions", &buf)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
 
-	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	// Set headers; local servers may not need a key
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Send request (this uploads the audio file)
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %v", err)
+		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
 	defer resp.Body.Close()
 
@@ -128,29 +247,44 @@ func (c *OpenAiSpeechClient) Transcribe(wavBytes []byte, filename string, langua
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %v", err)
 	}
 
 	// Handle HTTP errors
 	if resp.StatusCode != http.StatusOK {
+		if isUploadTooLarge(resp.StatusCode, body) {
+			return nil, fmt.Errorf("%w: %s", errAudioTooLarge, string(body))
+		}
+		apiErr := &APIStatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 		switch resp.StatusCode {
 		case http.StatusUnauthorized:
-			return "", fmt.Errorf("unauthorized: check your OpenAI API key")
+			apiErr.Message = "unauthorized: check your OpenAI API key"
 		case http.StatusTooManyRequests:
-			return "", fmt.Errorf("rate limit exceeded: please try again later")
+			apiErr.Message = "rate limit exceeded: please try again later"
 		case http.StatusBadRequest:
-			return "", fmt.Errorf("bad request: %s", string(body))
+			apiErr.Message = fmt.Sprintf("bad request: %s", string(body))
 		default:
-			return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			apiErr.Message = fmt.Sprintf("API request failed with status %d: %s", resp.StatusCode, string(body))
 		}
+		return nil, apiErr
 	}
 
-	// Parse JSON response
-	var transcriptionResp TranscriptionResponse
-	err = json.Unmarshal(body, &transcriptionResp)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse response JSON: %v", err)
-	}
+	return body, nil
+}
 
-	return transcriptionResp.Text, nil
+// isUploadTooLarge reports whether an error response rejects the upload for its size
+func isUploadTooLarge(statusCode int, body []byte) bool {
+	if statusCode == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	if statusCode != http.StatusBadRequest {
+		return false
+	}
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "too large") ||
+		strings.Contains(message, "maximum content size") ||
+		strings.Contains(message, "file size")
 }
@@ -0,0 +1,93 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// transcriptionServer answers transcription requests and records the form fields
+func transcriptionServer(t *testing.T, fields map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/transcriptions" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for name, values := range r.MultipartForm.Value {
+			fields[name] = values[0]
+		}
+		json.NewEncoder(w).Encode(TranscriptionResponse{Text: "transcribed"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTranscribeSendsModel(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	tests := []struct {
+		name  string
+		model string
+		want  string
+	}{
+		{"default", "", defaultWhisperModel},
+		{"configured", "gpt-4o-transcribe", "gpt-4o-transcribe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := map[string]string{}
+			server := transcriptionServer(t, fields)
+
+			client, err := NewOpenAiSpeechClient(server.URL, tt.model)
+			if err != nil {
+				t.Fatalf("NewOpenAiSpeechClient: %v", err)
+			}
+			text, err := client.Transcribe(context.Background(), []byte("audio"), "recording.mp3", "ru", "")
+			if err != nil {
+				t.Fatalf("Transcribe: %v", err)
+			}
+			if text != "transcribed" {
+				t.Errorf("Transcribe = %q, want %q", text, "transcribed")
+			}
+			if fields["model"] != tt.want {
+				t.Errorf("form model = %q, want %q", fields["model"], tt.want)
+			}
+			if fields["language"] != "ru" {
+				t.Errorf("form language = %q, want %q", fields["language"], "ru")
+			}
+		})
+	}
+}
+
+func TestLoadConfigWhisperModel(t *testing.T) {
+	t.Setenv("WHISPER_MODEL", "")
+	if got := LoadConfig().WhisperModel; got != defaultWhisperModel {
+		t.Errorf("default WhisperModel = %q, want %q", got, defaultWhisperModel)
+	}
+
+	t.Setenv("WHISPER_MODEL", "gpt-4o-mini-transcribe")
+	if got := LoadConfig().WhisperModel; got != "gpt-4o-mini-transcribe" {
+		t.Errorf("WhisperModel = %q, want %q", got, "gpt-4o-mini-transcribe")
+	}
+}
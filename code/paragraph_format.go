@@ -0,0 +1,214 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Paragraph size limits of formatParagraphs: a paragraph is only split once it
+// has minParagraphSentences sentences, and always once it reaches either maximum
+const (
+	minParagraphSentences = 2
+	maxParagraphSentences = 5
+	maxParagraphRunes     = 600
+)
+
+// Spacing fixes applied by normalizeSpacing
+var (
+	paragraphBreak     = regexp.MustCompile(`\n\s*\n`)
+	whitespaceRun      = regexp.MustCompile(`[^\S\n]+`)
+	spaceBeforeClosing = regexp.MustCompile(`\s+([,.;:!?…)\]»”])`)
+	spaceAfterOpening  = regexp.MustCompile(`([(\[«„“])\s+`)
+	missingSpaceAfter  = regexp.MustCompile(`([,;])(\pL)`)
+	missingSpaceBefore = regexp.MustCompile(`([!?])(\p{Lu})`)
+)
+
+// nonBreakingAbbreviations end with a period but never end a sentence
+// ("Dr. Smith", "ул. Ленина"). Initials and dotted abbreviations such as
+// "J.", "e.g." or "т.е." are recognized by their shape instead.
+var nonBreakingAbbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true,
+	"jr": true, "sr": true, "vs": true, "no": true, "fig": true, "approx": true,
+	"ул": true, "им": true, "см": true, "стр": true, "рис": true, "напр": true,
+	"проф": true, "доц": true, "акад": true, "тов": true, "гр": true, "кв": true,
+}
+
+// sentenceEndingAbbreviations often close a sentence ("…and so on etc. Then"),
+// so a capital letter after them starts a new one
+var sentenceEndingAbbreviations = map[string]bool{
+	"etc": true, "a.m": true, "p.m": true, "т.д": true, "т.п": true, "др": true, "г": true, "гг": true,
+}
+
+// topicShiftMarkers open a sentence that usually starts a new topic
+var topicShiftMarkers = []string{
+	"however", "next", "another", "finally", "first", "firstly", "second", "secondly",
+	"third", "thirdly", "in addition", "moreover", "on the other hand", "meanwhile",
+	"anyway", "in conclusion", "to summarize", "moving on", "let's move on",
+	"однако", "во-первых", "во-вторых", "в-третьих", "кроме того", "итак", "далее",
+	"наконец", "в заключение", "с другой стороны", "между тем", "в итоге",
+	"перейдём", "перейдем", "следующий", "следующая", "следующее",
+}
+
+// formatParagraphs tidies a dictation without calling an API: spacing around
+// punctuation is normalized and long paragraphs are split at sentence
+// boundaries, before sentences that open a new topic ("However, …",
+// "Во-вторых, …") or when a paragraph grows too long. Existing paragraph
+// and line breaks are kept.
+func formatParagraphs(text string) string {
+	var paragraphs []string
+	for _, block := range paragraphBreak.Split(strings.TrimSpace(text), -1) {
+		// Single line breaks (e.g. a dictated "new line") are kept as well
+		var lines []string
+		for _, line := range strings.Split(block, "\n") {
+			if line = normalizeSpacing(line); line != "" {
+				lines = append(lines, strings.Join(groupSentences(splitSentences(line)), "\n\n"))
+			}
+		}
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// normalizeSpacing collapses spaces and tabs, removes spaces before closing
+// punctuation and after opening brackets, and adds a missing space after a
+// comma or semicolon, and between "!" or "?" and the capital letter of the next sentence
+func normalizeSpacing(text string) string {
+	text = whitespaceRun.ReplaceAllString(text, " ")
+	text = spaceBeforeClosing.ReplaceAllString(text, "$1")
+	text = spaceAfterOpening.ReplaceAllString(text, "$1")
+	text = missingSpaceAfter.ReplaceAllString(text, "$1 $2")
+	text = missingSpaceBefore.ReplaceAllString(text, "$1 $2")
+	return strings.TrimSpace(text)
+}
+
+// splitSentences splits normalized text after ".", "!", "?" or "…" (with any
+// closing quotes or brackets) when a space and a capital letter, digit or
+// opening quote or dash follow. A period after an abbreviation or initial
+// does not end a sentence.
+func splitSentences(text string) []string {
+	runes := []rune(text)
+	var sentences []string
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !isSentenceTerminator(runes[i]) {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && isSentenceTerminator(runes[end]) {
+			end++
+		}
+		for end < len(runes) && isClosingQuote(runes[end]) {
+			end++
+		}
+		if end+1 >= len(runes) || runes[end] != ' ' || !startsSentence(runes[end+1]) {
+			i = end - 1
+			continue
+		}
+		if end == i+1 && runes[i] == '.' && !abbreviationEndsSentence(lastWord(runes[start:i])) {
+			continue
+		}
+		sentences = append(sentences, string(runes[start:end]))
+		start = end + 1
+		i = end
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+// groupSentences joins sentences into paragraphs
+func groupSentences(sentences []string) []string {
+	var paragraphs []string
+	var current []string
+	length := 0
+	for _, sentence := range sentences {
+		size := len([]rune(sentence))
+		if len(current) >= minParagraphSentences &&
+			(startsNewTopic(sentence) || len(current) >= maxParagraphSentences || length+size > maxParagraphRunes) {
+			paragraphs = append(paragraphs, strings.Join(current, " "))
+			current, length = nil, 0
+		}
+		current = append(current, sentence)
+		length += size + 1
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, strings.Join(current, " "))
+	}
+	return paragraphs
+}
+
+// lastWord returns the text after the last space, without leading opening quotes
+func lastWord(runes []rune) string {
+	word := runes
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == ' ' {
+			word = runes[i+1:]
+			break
+		}
+	}
+	return strings.TrimLeft(string(word), "(«„“\"'")
+}
+
+// abbreviationEndsSentence reports whether a period after word can end a
+// sentence, i.e. word is not a known abbreviation, an initial ("J", "А") or a
+// dotted abbreviation ("e.g", "т.е")
+func abbreviationEndsSentence(word string) bool {
+	folded := strings.ToLower(word)
+	if sentenceEndingAbbreviations[folded] {
+		return true
+	}
+	if nonBreakingAbbreviations[folded] {
+		return false
+	}
+	for _, part := range strings.Split(folded, ".") {
+		if len([]rune(part)) != 1 || !unicode.IsLetter([]rune(part)[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// startsNewTopic reports whether sentence opens with a topic shift marker
+func startsNewTopic(sentence string) bool {
+	folded := strings.ToLower(strings.TrimLeft(sentence, "«„“\"'—–- "))
+	for _, marker := range topicShiftMarkers {
+		if rest, ok := strings.CutPrefix(folded, marker); ok {
+			if next := []rune(rest); len(next) == 0 || !isWordRune(next[0]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSentenceTerminator reports whether r can end a sentence
+func isSentenceTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+// isClosingQuote reports whether r may follow the end of a sentence
+func isClosingQuote(r rune) bool {
+	return strings.ContainsRune(`"')]»”’`, r)
+}
+
+// startsSentence reports whether r can be the first character of a sentence
+func startsSentence(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || strings.ContainsRune(`"'(«„“—–-`, r)
+}
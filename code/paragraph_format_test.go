@@ -0,0 +1,165 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentencesEnglish(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"terminators", "Hello world. How are you? Fine!", []string{"Hello world.", "How are you?", "Fine!"}},
+		{"titles", "Dr. Smith met Mr. Jones and Prof. Lee. Then they left.", []string{"Dr. Smith met Mr. Jones and Prof. Lee.", "Then they left."}},
+		{"initials", "J. R. R. Tolkien wrote it. It is long.", []string{"J. R. R. Tolkien wrote it.", "It is long."}},
+		{"dotted abbreviation", "Use tools, e.g. Hammers and saws. Done.", []string{"Use tools, e.g. Hammers and saws.", "Done."}},
+		{"etc ends a sentence", "Apples, pears etc. The rest is fine.", []string{"Apples, pears etc.", "The rest is fine."}},
+		{"p.m. ends a sentence", "We met at 5 p.m. Then we left.", []string{"We met at 5 p.m.", "Then we left."}},
+		{"abbreviation before a number", "See fig. 3 for details. It helps.", []string{"See fig. 3 for details.", "It helps."}},
+		{"decimal number", "Version 2.5 is out. 3 bugs remain.", []string{"Version 2.5 is out.", "3 bugs remain."}},
+		{"ellipsis", "Wait... What now?", []string{"Wait...", "What now?"}},
+		{"closing quote", `He said "Stop." Then he left.`, []string{`He said "Stop."`, "Then he left."}},
+		{"lowercase continues", "It costs approx. ten dollars. ok then", []string{"It costs approx. ten dollars. ok then"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSentencesRussian(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"terminators", "Привет. Как дела? Отлично!", []string{"Привет.", "Как дела?", "Отлично!"}},
+		{"street", "Мы живём на ул. Ленина. Это в центре.", []string{"Мы живём на ул. Ленина.", "Это в центре."}},
+		{"initials", "Писатель А. С. Пушкин родился в Москве. Это известно.", []string{"Писатель А. С. Пушкин родился в Москве.", "Это известно."}},
+		{"references", "См. рис. 5 и проф. Иванов. Готово.", []string{"См. рис. 5 и проф. Иванов.", "Готово."}},
+		{"dotted abbreviation", "Столица, т.е. Москва, большая. Конец.", []string{"Столица, т.е. Москва, большая.", "Конец."}},
+		{"т.д. ends a sentence", "Фрукты, овощи и т.д. Остальное потом.", []string{"Фрукты, овощи и т.д.", "Остальное потом."}},
+		{"year", "Это было в 1999 г. Потом всё изменилось.", []string{"Это было в 1999 г.", "Потом всё изменилось."}},
+		{"guillemets", "Он сказал: «Стоп.» Потом ушёл.", []string{"Он сказал: «Стоп.»", "Потом ушёл."}},
+		{"dash", "Готово. — Правда? Да.", []string{"Готово.", "— Правда?", "Да."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSpacing(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello ,world !How are you ?", "Hello, world! How are you?"},
+		{"  too   many\tspaces  ", "too many spaces"},
+		{"( inside ) and « цитата »", "(inside) and «цитата»"},
+		{"Да,конечно;хорошо", "Да, конечно; хорошо"},
+		{"3,5 stays", "3,5 stays"},
+	}
+	for _, tt := range tests {
+		if got := normalizeSpacing(tt.text); got != tt.want {
+			t.Errorf("normalizeSpacing(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFormatParagraphs(t *testing.T) {
+	var long []string
+	for i := 1; i <= 7; i++ {
+		long = append(long, fmt.Sprintf("Sentence %d.", i))
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", " \n\n ", ""},
+		{"short text", "Just one sentence.", "Just one sentence."},
+		{"topic shift", "The first point. It has detail. However, there is more. It goes on.",
+			"The first point. It has detail.\n\nHowever, there is more. It goes on."},
+		{"russian topic shift", "Это первое. Это второе. Во-вторых, новая тема. Конец.",
+			"Это первое. Это второе.\n\nВо-вторых, новая тема. Конец."},
+		{"too early to split", "Intro. However, not yet. More.", "Intro. However, not yet. More."},
+		{"too many sentences", strings.Join(long, " "),
+			strings.Join(long[:maxParagraphSentences], " ") + "\n\n" + strings.Join(long[maxParagraphSentences:], " ")},
+		{"existing breaks and spacing", "Hello ,world !How are you?\n\n\n  New   paragraph .\nSame  paragraph.",
+			"Hello, world! How are you?\n\nNew paragraph.\nSame paragraph."},
+		{"abbreviations don't split", "Dr. Smith spoke. Mr. Jones agreed. Next, ул. Ленина. Finally, done.",
+			"Dr. Smith spoke. Mr. Jones agreed.\n\nNext, ул. Ленина. Finally, done."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatParagraphs(tt.text)
+			if got != tt.want {
+				t.Errorf("formatParagraphs(%q) =\n%q\nwant\n%q", tt.text, got, tt.want)
+			}
+			if again := formatParagraphs(got); again != got {
+				t.Errorf("formatting again changed the text:\n%q\nto\n%q", got, again)
+			}
+		})
+	}
+}
+
+func TestTranscribeJobFormatsParagraphs(t *testing.T) {
+	const text = "The first point. It has detail. However, there is more."
+	for _, enabled := range []bool{false, true} {
+		a := newTestAppState(context.Background())
+		a.audioStorage = &AudioStorage{baseDir: t.TempDir()}
+		a.usage = newUsageTracker(a.config, nil)
+		a.config.FormatParagraphs = enabled
+		whisper := &fakeWhisper{text: text}
+		a.setClients(whisper.client(), nil)
+
+		job := transcriptionJob{audioData: make([]byte, 2*recordingSampleRate), sampleRate: recordingSampleRate, mode: "start"}
+		want := text
+		if enabled {
+			want = formatParagraphs(text)
+		}
+		if result := a.transcribeJob(job); result.Text != want {
+			t.Errorf("FormatParagraphs=%v: Text = %q, want %q", enabled, result.Text, want)
+		}
+	}
+}
+
+func TestFormatParagraphsLengthLimit(t *testing.T) {
+	// Two of these fit in a paragraph, three don't
+	sentence := "Some" + strings.Repeat(" word", 48) + "."
+	text := strings.Repeat(sentence+" ", 6)
+	paragraphs := strings.Split(formatParagraphs(text), "\n\n")
+	if len(paragraphs) != 3 {
+		t.Errorf("got %d paragraphs, want 3", len(paragraphs))
+	}
+	for i, paragraph := range paragraphs {
+		if n := len([]rune(paragraph)); n > maxParagraphRunes {
+			t.Errorf("paragraph %d has %d runes, want at most %d", i, n, maxParagraphRunes)
+		}
+	}
+}
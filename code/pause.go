@@ -0,0 +1,104 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// PauseRecording stops capturing audio without ending the recording.
+// The captured audio is kept, so Send and Escape work as usual while paused.
+func (a *AppState) PauseRecording() error {
+	if !a.isRecording || a.stream == nil {
+		return fmt.Errorf("no active recording to pause")
+	}
+	if a.isPaused {
+		return nil
+	}
+
+	if err := a.stream.Stop(); err != nil {
+		return fmt.Errorf("failed to pause audio stream: %v", err)
+	}
+	a.setPaused(true)
+
+	captured := a.capturedDuration().Round(time.Second)
+	Infof("Recording paused with %v of audio captured", captured)
+	setStatusText(a.statusLabel, fmt.Sprintf("Paused (%v captured)", captured))
+	return nil
+}
+
+// ResumeRecording continues capturing into the paused recording
+func (a *AppState) ResumeRecording() error {
+	if !a.isRecording || a.stream == nil {
+		return fmt.Errorf("no active recording to resume")
+	}
+	if !a.isPaused {
+		return nil
+	}
+
+	// Silence while paused must not count towards auto-stop
+	a.silentSamples.Store(0)
+	if err := a.stream.Start(); err != nil {
+		return fmt.Errorf("failed to resume audio stream: %v", err)
+	}
+	a.setPaused(false)
+
+	Infof("Recording resumed")
+	setStatusText(a.statusLabel, "Recording...")
+	return nil
+}
+
+// setPaused records the paused state and updates the pause button label
+func (a *AppState) setPaused(paused bool) {
+	a.isPaused = paused
+	if a.pauseButton == nil {
+		return
+	}
+	if paused {
+		a.pauseButton.SetText("Resume")
+	} else {
+		a.pauseButton.SetText("Pause")
+	}
+}
+
+// capturedDuration returns how much audio the current recording holds.
+// Paused time is not included since nothing is captured meanwhile.
+func (a *AppState) capturedDuration() time.Duration {
+	if a.sampleRate == 0 {
+		return 0
+	}
+	a.audioMutex.Lock()
+	defer a.audioMutex.Unlock()
+	return time.Duration(len(a.audioBuffer)) * time.Second / time.Duration(a.samplesPerSecond())
+}
+
+// onPauseButtonClick toggles between pausing and resuming the current recording
+func (a *AppState) onPauseButtonClick() {
+	if !a.isRecording {
+		setStatusText(a.statusLabel, "Nothing to pause")
+		return
+	}
+
+	var err error
+	if a.isPaused {
+		err = a.ResumeRecording()
+	} else {
+		err = a.PauseRecording()
+	}
+	if err != nil {
+		Errorf("Pause toggle failed: %v", err)
+		setStatusText(a.statusLabel, fmt.Sprintf("Pause error: %v", err))
+	}
+}
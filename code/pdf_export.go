@@ -0,0 +1,68 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/go-pdf/fpdf"
+)
+
+// A4 page layout for PDF exports, in points
+const (
+	pdfMargin     = 56.0
+	pdfFontSize   = 11.0
+	pdfLeading    = 15.0
+	pdfFontFamily = "UI"
+)
+
+// pdfExporter lays the text out on A4 pages in the UI font. The font is embedded
+// as a Unicode subset, so Cyrillic and the other scripts it covers render on any
+// viewer and can be copied back out as text.
+type pdfExporter struct{}
+
+func (pdfExporter) Name() string      { return "PDF" }
+func (pdfExporter) Extension() string { return ".pdf" }
+
+func (pdfExporter) Export(text string) ([]byte, error) {
+	return renderPDF(text, theme.TextFont().Content())
+}
+
+// renderPDF writes text to A4 pages in the TrueType font fontData, wrapping long
+// lines and leaving half a line between paragraphs
+func renderPDF(text string, fontData []byte) ([]byte, error) {
+	pdf := fpdf.New("P", "pt", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", fontData)
+	pdf.SetFont(pdfFontFamily, "", pdfFontSize)
+	pdf.AddPage()
+
+	for i, paragraph := range exportParagraphs(text) {
+		if i > 0 {
+			pdf.Ln(pdfLeading / 2)
+		}
+		for _, line := range paragraph {
+			pdf.MultiCell(0, pdfLeading, line, "", "L", false)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %v", err)
+	}
+	return buf.Bytes(), nil
+}
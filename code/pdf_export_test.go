@@ -0,0 +1,160 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+var (
+	pdfStream    = regexp.MustCompile(`<<([^<>]*)>>\nstream\n`)
+	pdfLength    = regexp.MustCompile(`/Length (\d+)`)
+	pdfPageCount = regexp.MustCompile(`/Type /Pages\n/Kids \[[^\]]*\]\n/Count (\d+)`)
+)
+
+// pdfStreams checks the PDF header and end-of-file marker and returns the data
+// of every stream in the file, decompressed where it is Flate encoded
+func pdfStreams(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.")) {
+		t.Fatalf("missing PDF header: %q", data[:min(len(data), 16)])
+	}
+	if !bytes.HasSuffix(bytes.TrimSpace(data), []byte("%%EOF")) {
+		t.Fatal("missing end-of-file marker")
+	}
+
+	var streams [][]byte
+	for _, match := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		dict := string(data[match[2]:match[3]])
+		length := pdfLength.FindStringSubmatch(dict)
+		if length == nil {
+			t.Fatalf("stream has no length: %q", dict)
+		}
+		n, _ := strconv.Atoi(length[1])
+		raw := data[match[1]:]
+		if n > len(raw) || !bytes.HasPrefix(raw[n:], []byte("\nendstream")) {
+			t.Fatalf("stream /Length %d does not end at endstream", n)
+		}
+		if !strings.Contains(dict, "/FlateDecode") {
+			streams = append(streams, raw[:n])
+			continue
+		}
+		r, err := zlib.NewReader(bytes.NewReader(raw[:n]))
+		if err != nil {
+			t.Fatalf("stream is not zlib data: %v", err)
+		}
+		stream, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("failed to decompress stream: %v", err)
+		}
+		streams = append(streams, stream)
+	}
+	return streams
+}
+
+// pdfText encodes s the way the exporter's Unicode font shows it: as UTF-16BE
+func pdfText(s string) []byte {
+	var text []byte
+	for _, unit := range utf16.Encode([]rune(s)) {
+		text = append(text, byte(unit>>8), byte(unit))
+	}
+	return text
+}
+
+func TestPDFExportText(t *testing.T) {
+	text := "Hello, world!\nSecond line.\n\nПривет, мир! Ёжик в тумане."
+	data, err := pdfExporter{}.Export(text)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	var contents, cidToGID []byte
+	for _, stream := range pdfStreams(t, data) {
+		switch {
+		case bytes.Contains(stream, []byte(")Tj")):
+			contents = append(contents, stream...)
+		case len(stream) == 2*(0xFFFF+1):
+			cidToGID = stream
+		}
+	}
+
+	// Three lines of text, each drawn with one Tj
+	if n := bytes.Count(contents, []byte(")Tj")); n != 3 {
+		t.Errorf("content streams draw %d lines, want 3:\n%q", n, contents)
+	}
+	for _, line := range []string{"Hello, world!", "Second line.", "Привет, мир! Ёжик в тумане."} {
+		if !bytes.Contains(contents, pdfText(line)) {
+			t.Errorf("content streams do not show %q", line)
+		}
+	}
+	// Every letter, Cyrillic included, maps to a glyph of the embedded font
+	// rather than the .notdef glyph
+	if cidToGID == nil {
+		t.Fatal("missing CIDToGIDMap stream")
+	}
+	for _, r := range "HeloПриветмЁжкнуа" {
+		if cidToGID[2*r] == 0 && cidToGID[2*r+1] == 0 {
+			t.Errorf("embedded font has no glyph for %q", r)
+		}
+	}
+}
+
+func TestPDFExportPages(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		pages int
+	}{
+		{"empty", "", 1},
+		{"short", "Одна строка.", 1},
+		{"long", strings.Repeat("Строка текста для проверки разбиения на страницы.\n", 120), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := pdfExporter{}.Export(tt.text)
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			pdfStreams(t, data)
+
+			if pages := bytes.Count(data, []byte("/Type /Page\n")); pages != tt.pages {
+				t.Errorf("got %d page objects, want %d", pages, tt.pages)
+			}
+			if match := pdfPageCount.FindSubmatch(data); match == nil || string(match[1]) != strconv.Itoa(tt.pages) {
+				t.Errorf("page tree /Count %q, want %d", match, tt.pages)
+			}
+		})
+	}
+}
+
+func TestPDFExportWrapsLongLines(t *testing.T) {
+	data, err := pdfExporter{}.Export(strings.Repeat("слово ", 200))
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	var contents []byte
+	for _, stream := range pdfStreams(t, data) {
+		contents = append(contents, stream...)
+	}
+	if n := bytes.Count(contents, []byte(")Tj")); n < 10 {
+		t.Errorf("content streams draw %d lines, expected the text to wrap", n)
+	}
+}
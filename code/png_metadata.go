@@ -0,0 +1,135 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// pngSignature is the 8-byte header every PNG file starts with
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// pngDescriptionKeyword is the standard PNG keyword used for the embedded transcript
+const pngDescriptionKeyword = "Description"
+
+// embedPNGText inserts a text chunk with the given keyword right after the IHDR chunk.
+// The text is stored as an uncompressed iTXt chunk so UTF-8 (e.g. Cyrillic) survives;
+// readers that only understand tEXt will simply ignore it.
+func embedPNGText(pngData []byte, keyword string, text string) ([]byte, error) {
+	if len(keyword) == 0 || len(keyword) > 79 {
+		return nil, fmt.Errorf("invalid PNG text keyword length: %d", len(keyword))
+	}
+	if !bytes.HasPrefix(pngData, pngSignature) {
+		return nil, fmt.Errorf("data is not a PNG image")
+	}
+
+	// IHDR is always the first chunk: 4 length + 4 type + 13 data + 4 CRC
+	ihdrEnd := len(pngSignature) + 25
+	if len(pngData) < ihdrEnd || string(pngData[len(pngSignature)+4:len(pngSignature)+8]) != "IHDR" {
+		return nil, fmt.Errorf("PNG is missing the IHDR chunk")
+	}
+
+	// iTXt: keyword, null, compression flag, compression method, language tag, null, translated keyword, null, text
+	var data bytes.Buffer
+	data.WriteString(keyword)
+	data.Write([]byte{0, 0, 0, 0, 0})
+	data.WriteString(text)
+
+	var out bytes.Buffer
+	out.Write(pngData[:ihdrEnd])
+	writePNGChunk(&out, "iTXt", data.Bytes())
+	out.Write(pngData[ihdrEnd:])
+	return out.Bytes(), nil
+}
+
+// readPNGText returns the text stored under keyword in a tEXt or uncompressed iTXt chunk
+func readPNGText(pngData []byte, keyword string) (string, bool, error) {
+	if !bytes.HasPrefix(pngData, pngSignature) {
+		return "", false, fmt.Errorf("data is not a PNG image")
+	}
+
+	pos := len(pngSignature)
+	for pos+8 <= len(pngData) {
+		length := int(binary.BigEndian.Uint32(pngData[pos : pos+4]))
+		chunkType := string(pngData[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(pngData) {
+			return "", false, fmt.Errorf("truncated %s chunk", chunkType)
+		}
+		data := pngData[pos+8 : pos+8+length]
+
+		switch chunkType {
+		case "tEXt":
+			if parts := bytes.SplitN(data, []byte{0}, 2); len(parts) == 2 && string(parts[0]) == keyword {
+				return latin1ToString(parts[1]), true, nil
+			}
+		case "iTXt":
+			if text, ok := parseITXt(data, keyword); ok {
+				return text, true, nil
+			}
+		case "IEND":
+			return "", false, nil
+		}
+
+		pos += 12 + length
+	}
+
+	return "", false, nil
+}
+
+// parseITXt extracts the text of an uncompressed iTXt chunk matching keyword
+func parseITXt(data []byte, keyword string) (string, bool) {
+	parts := bytes.SplitN(data, []byte{0}, 2)
+	if len(parts) != 2 || string(parts[0]) != keyword {
+		return "", false
+	}
+	rest := parts[1]
+	if len(rest) < 2 || rest[0] != 0 {
+		// Compressed iTXt is not produced by embedPNGText
+		return "", false
+	}
+	// Skip compression flag/method, then language tag and translated keyword
+	fields := bytes.SplitN(rest[2:], []byte{0}, 3)
+	if len(fields) != 3 {
+		return "", false
+	}
+	return string(fields[2]), true
+}
+
+// latin1ToString converts ISO 8859-1 bytes (the tEXt encoding) to a Go string
+func latin1ToString(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// writePNGChunk writes a chunk with its length prefix and CRC
+func writePNGChunk(buf *bytes.Buffer, chunkType string, data []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], chunkType)
+	buf.Write(header[:])
+	buf.Write(data)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	buf.Write(sum[:])
+}
@@ -0,0 +1,125 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// testPNG encodes a small image for the metadata tests
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestEmbedPNGTextRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"ascii", "Meeting notes: ship on Friday"},
+		{"cyrillic", "Привет, мир! Текст на русском"},
+		{"multiline", "first line\nsecond line"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := testPNG(t)
+			withText, err := embedPNGText(original, pngDescriptionKeyword, tt.text)
+			if err != nil {
+				t.Fatalf("embedPNGText: %v", err)
+			}
+
+			// The image itself must still decode; the decoder checks every chunk's CRC
+			if _, err := png.Decode(bytes.NewReader(withText)); err != nil {
+				t.Fatalf("png.Decode after embedding: %v", err)
+			}
+
+			got, ok, err := readPNGText(withText, pngDescriptionKeyword)
+			if err != nil {
+				t.Fatalf("readPNGText: %v", err)
+			}
+			if !ok {
+				t.Fatalf("readPNGText found no %q chunk", pngDescriptionKeyword)
+			}
+			if got != tt.text {
+				t.Errorf("readPNGText = %q, want %q", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestReadPNGTextLatin1TEXt(t *testing.T) {
+	original := testPNG(t)
+	ihdrEnd := len(pngSignature) + 25
+
+	// A tEXt chunk as written by other tools: keyword, null, ISO 8859-1 text
+	var chunk bytes.Buffer
+	writePNGChunk(&chunk, "tEXt", append([]byte("Comment\x00caf"), 0xe9))
+
+	var withText bytes.Buffer
+	withText.Write(original[:ihdrEnd])
+	withText.Write(chunk.Bytes())
+	withText.Write(original[ihdrEnd:])
+
+	got, ok, err := readPNGText(withText.Bytes(), "Comment")
+	if err != nil || !ok {
+		t.Fatalf("readPNGText = %q, %v, %v; want the tEXt text", got, ok, err)
+	}
+	if got != "café" {
+		t.Errorf("readPNGText = %q, want %q", got, "café")
+	}
+}
+
+func TestReadPNGTextMissingKeyword(t *testing.T) {
+	withText, err := embedPNGText(testPNG(t), pngDescriptionKeyword, "text")
+	if err != nil {
+		t.Fatalf("embedPNGText: %v", err)
+	}
+	if got, ok, err := readPNGText(withText, "Author"); ok || err != nil {
+		t.Errorf("readPNGText(Author) = %q, %v, %v; want not found", got, ok, err)
+	}
+	if _, ok, err := readPNGText(testPNG(t), pngDescriptionKeyword); ok || err != nil {
+		t.Errorf("readPNGText on a PNG without text = %v, %v; want not found", ok, err)
+	}
+}
+
+func TestEmbedPNGTextRejectsInvalidInput(t *testing.T) {
+	original := testPNG(t)
+	if _, err := embedPNGText(original, "", "text"); err == nil {
+		t.Error("embedPNGText accepted an empty keyword")
+	}
+	if _, err := embedPNGText(original, strings.Repeat("k", 80), "text"); err == nil {
+		t.Error("embedPNGText accepted an 80-byte keyword")
+	}
+	if _, err := embedPNGText([]byte("GIF89a"), pngDescriptionKeyword, "text"); err == nil {
+		t.Error("embedPNGText accepted data that is not a PNG")
+	}
+	// Cut inside the chunk after IHDR, whose declared length runs past the end
+	truncated := original[:len(pngSignature)+25+10]
+	if _, _, err := readPNGText(truncated, pngDescriptionKeyword); err == nil {
+		t.Error("readPNGText accepted a truncated PNG")
+	}
+}
@@ -0,0 +1,90 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// promptPrefKey is the preferences key storing the transcription vocabulary hint
+const promptPrefKey = "transcriptionPrompt"
+
+// maxPromptTokens is how much of a prompt Whisper considers; longer prompts are cut
+const maxPromptTokens = 224
+
+// estimatePromptTokens roughly estimates the token count of a word. It errs on the
+// high side (about 3 characters per token) so non-English text stays within the limit.
+func estimatePromptTokens(word string) int {
+	return 1 + utf8.RuneCountInString(word)/3
+}
+
+// truncatePrompt trims whitespace and drops trailing words that would exceed
+// Whisper's prompt limit, so the first words entered always take effect
+func truncatePrompt(prompt string) string {
+	words := strings.Fields(prompt)
+	tokens := 0
+	for i, word := range words {
+		tokens += estimatePromptTokens(word)
+		if tokens > maxPromptTokens {
+			Infof("Transcription prompt truncated to %d of %d words (limit %d tokens)", i, len(words), maxPromptTokens)
+			return strings.Join(words[:i], " ")
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// transcriptionPrompt returns the vocabulary hint. The transcription worker reads
+// it while the Settings tab may be changing it.
+func (a *AppState) transcriptionPrompt() string {
+	if prompt := a.whisperPrompt.Load(); prompt != nil {
+		return *prompt
+	}
+	return ""
+}
+
+// newPromptEntry builds the vocabulary hint editor and restores the saved prompt
+func (a *AppState) newPromptEntry(prefs fyne.Preferences) *widget.Entry {
+	prompt := prefs.String(promptPrefKey)
+	a.whisperPrompt.Store(&prompt)
+
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("Product names, jargon and spellings, e.g. MicApp, Kubernetes, PostgreSQL")
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetText(prompt)
+	entry.OnChanged = func(text string) {
+		a.whisperPrompt.Store(&text)
+		prefs.SetString(promptPrefKey, text)
+	}
+	return entry
+}
+
+// newSettingsTab builds the Settings tab, which gathers the toolbar controls
+// and the settings otherwise only available as environment variables
+func (a *AppState) newSettingsTab(prefs fyne.Preferences, languageSelect *widget.Select, deviceSelect *widget.Select, correctionCheck *widget.Check) fyne.CanvasObject {
+	return container.NewVScroll(container.NewVBox(
+		a.newGeneralSettings(prefs, languageSelect, deviceSelect, correctionCheck),
+		widget.NewSeparator(),
+		a.newServiceSettings(prefs),
+		widget.NewSeparator(),
+		widget.NewLabel("Vocabulary hint (sent to Whisper with every transcription, about 224 tokens max)"),
+		a.newPromptEntry(prefs),
+		widget.NewLabel("Correction instructions (sent to GPT with every correction)"),
+		a.newCorrectionInstructionsEntry(prefs),
+	))
+}
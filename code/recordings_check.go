@@ -0,0 +1,150 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// quarantineDirName is the recordings subfolder that receives unreadable files
+const quarantineDirName = "quarantine"
+
+// RecordingsCheckSummary counts what ValidateRecordings found
+type RecordingsCheckSummary struct {
+	Healthy     int
+	Removed     int // Zero-byte files deleted
+	Quarantined int // Unreadable or invalid MP3 files moved aside
+}
+
+// ValidateRecordings cleans up the recordings folder after a crash without
+// losing good recordings: zero-byte files are removed, and .mp3 files that
+// cannot be read or do not start with a valid MP3 frame are moved to the
+// quarantine subfolder. Other files (e.g. transcript sidecars) are left alone.
+func (as *AudioStorage) ValidateRecordings() (RecordingsCheckSummary, error) {
+	var summary RecordingsCheckSummary
+
+	if err := os.MkdirAll(as.baseDir, 0755); err != nil {
+		return summary, fmt.Errorf("failed to create recordings folder: %v", err)
+	}
+
+	entries, err := os.ReadDir(as.baseDir)
+	if err != nil {
+		return summary, fmt.Errorf("failed to read recordings folder: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(as.baseDir, entry.Name())
+
+		info, err := entry.Info()
+		if err != nil {
+			Errorf("ValidateRecordings: cannot stat %s: %v", entry.Name(), err)
+			continue
+		}
+
+		if info.Size() == 0 {
+			if err := os.Remove(path); err != nil {
+				Errorf("ValidateRecordings: failed to remove empty file %s: %v", entry.Name(), err)
+				continue
+			}
+			Infof("ValidateRecordings: removed empty file %s", entry.Name())
+			summary.Removed++
+			continue
+		}
+
+		if filepath.Ext(entry.Name()) != ".mp3" {
+			continue
+		}
+
+		if err := checkMP3File(path); err != nil {
+			Infof("ValidateRecordings: quarantining %s: %v", entry.Name(), err)
+			if err := as.quarantine(entry.Name()); err != nil {
+				Errorf("ValidateRecordings: failed to quarantine %s: %v", entry.Name(), err)
+				continue
+			}
+			summary.Quarantined++
+			continue
+		}
+		summary.Healthy++
+	}
+
+	Infof("Recordings check: %d healthy, %d empty removed, %d quarantined",
+		summary.Healthy, summary.Removed, summary.Quarantined)
+	return summary, nil
+}
+
+// quarantine moves a file from the recordings folder into the quarantine subfolder
+func (as *AudioStorage) quarantine(filename string) error {
+	dir := filepath.Join(as.baseDir, quarantineDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(as.baseDir, filename), filepath.Join(dir, filename))
+}
+
+// checkMP3File verifies that the file starts with a valid MP3 frame header,
+// skipping a leading ID3v2 tag if present
+func checkMP3File(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var header [10]byte
+	n, err := io.ReadFull(file, header[:])
+	if (err != nil && err != io.ErrUnexpectedEOF) || n < 4 {
+		return fmt.Errorf("file too short: %d bytes", n)
+	}
+
+	offset := int64(0)
+	if n == len(header) && string(header[:3]) == "ID3" {
+		// ID3v2 size is a 28-bit syncsafe integer excluding the 10-byte header
+		size := int64(header[6]&0x7f)<<21 | int64(header[7]&0x7f)<<14 | int64(header[8]&0x7f)<<7 | int64(header[9]&0x7f)
+		offset = 10 + size
+		if header[5]&0x10 != 0 {
+			offset += 10 // Footer present
+		}
+	}
+
+	var frame [4]byte
+	if _, err := file.ReadAt(frame[:], offset); err != nil {
+		return fmt.Errorf("no audio frame after tag: %v", err)
+	}
+	if !isMP3FrameHeader(frame[:]) {
+		return fmt.Errorf("invalid MP3 frame header % x", frame)
+	}
+	return nil
+}
+
+// isMP3FrameHeader reports whether b starts with a valid MPEG audio frame header
+func isMP3FrameHeader(b []byte) bool {
+	if len(b) < 4 {
+		return false
+	}
+	// 11-bit frame sync
+	if b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return false
+	}
+	version := (b[1] >> 3) & 0x03
+	layer := (b[1] >> 1) & 0x03
+	bitrateIndex := b[2] >> 4
+	sampleRateIndex := (b[2] >> 2) & 0x03
+	return version != 0x01 && layer != 0x00 && bitrateIndex != 0x0F && sampleRateIndex != 0x03
+}
@@ -0,0 +1,141 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// validMP3Frame is an MPEG-1 Layer III frame header (128 kbps, 44.1 kHz)
+var validMP3Frame = []byte{0xFF, 0xFB, 0x90, 0x00, 0x00, 0x00}
+
+func TestValidateRecordings(t *testing.T) {
+	dir := t.TempDir()
+
+	// ID3v2 tag of 5 bytes (syncsafe size) followed by a frame
+	id3 := append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 5, 1, 2, 3, 4, 5}, validMP3Frame...)
+
+	healthy := map[string][]byte{
+		"recording_20240501_120000_64kbps.mp3": validMP3Frame,
+		"recording_20240501_120100_64kbps.mp3": id3,
+	}
+	empty := []string{"recording_20240501_120200_64kbps.mp3", "notes.json"}
+	junk := map[string][]byte{
+		"recording_20240501_120300_64kbps.mp3": []byte("<html>not audio</html>"),
+		"recording_20240501_120400_64kbps.mp3": {0xFF},
+		"truncated_tag.mp3":                    {'I', 'D', '3', 4, 0, 0, 0, 0, 1, 0},
+	}
+	untouched := map[string][]byte{
+		"recording_20240501_120000_64kbps.json": []byte(`{"text":"hello"}`),
+		"recording_20240501_120500.wav":         []byte("RIFF"),
+	}
+
+	for name, data := range healthy {
+		writeTestFile(t, dir, name, data)
+	}
+	for _, name := range empty {
+		writeTestFile(t, dir, name, nil)
+	}
+	for name, data := range junk {
+		writeTestFile(t, dir, name, data)
+	}
+	for name, data := range untouched {
+		writeTestFile(t, dir, name, data)
+	}
+
+	as := &AudioStorage{baseDir: dir, sortOrder: AudioSortNewest}
+	summary, err := as.ValidateRecordings()
+	if err != nil {
+		t.Fatalf("ValidateRecordings: %v", err)
+	}
+	want := RecordingsCheckSummary{Healthy: len(healthy), Removed: len(empty), Quarantined: len(junk)}
+	if summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+
+	for _, name := range empty {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("empty file %s was not removed", name)
+		}
+	}
+	for name := range junk {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("junk file %s is still in the recordings folder", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, quarantineDirName, name)); err != nil {
+			t.Errorf("junk file %s was not quarantined: %v", name, err)
+		}
+	}
+	for name := range untouched {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("non-MP3 file %s was touched: %v", name, err)
+		}
+	}
+
+	// Only the healthy recordings (and the WAV fallback) are listed afterwards
+	files, err := as.GetStoredAudioFiles()
+	if err != nil {
+		t.Fatalf("GetStoredAudioFiles: %v", err)
+	}
+	listed := map[string]bool{}
+	for _, file := range files {
+		listed[file.Filename] = true
+	}
+	for name := range healthy {
+		if !listed[name] {
+			t.Errorf("healthy recording %s is not listed", name)
+		}
+	}
+	for name := range junk {
+		if listed[name] {
+			t.Errorf("quarantined file %s is still listed", name)
+		}
+	}
+
+	// A second run finds nothing left to repair
+	summary, err = as.ValidateRecordings()
+	if err != nil {
+		t.Fatalf("second ValidateRecordings: %v", err)
+	}
+	if want := (RecordingsCheckSummary{Healthy: len(healthy)}); summary != want {
+		t.Errorf("second summary = %+v, want %+v", summary, want)
+	}
+}
+
+func TestIsMP3FrameHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{"mpeg1 layer3", []byte{0xFF, 0xFB, 0x90, 0x00}, true},
+		{"mpeg2 layer3", []byte{0xFF, 0xF3, 0x48, 0x00}, true},
+		{"no sync", []byte{0x49, 0x44, 0x33, 0x04}, false},
+		{"reserved version", []byte{0xFF, 0xEB, 0x90, 0x00}, false},
+		{"reserved layer", []byte{0xFF, 0xF9, 0x90, 0x00}, false},
+		{"bad bitrate", []byte{0xFF, 0xFB, 0xF0, 0x00}, false},
+		{"reserved sample rate", []byte{0xFF, 0xFB, 0x9C, 0x00}, false},
+		{"too short", []byte{0xFF, 0xFB}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMP3FrameHeader(tt.header); got != tt.want {
+				t.Errorf("isMP3FrameHeader(% x) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"image"
+	"image/color"
+)
+
+// redactBlurRadius is the box blur radius used by the redact tool. Three passes of
+// this radius smear text beyond recognition while keeping the region's colours.
+const (
+	redactBlurRadius = 12
+	redactBlurPasses = 3
+)
+
+// Redaction blurs the area between two corners. The blur is drawn into the image
+// that is saved and copied, so the original pixels are not recoverable from it.
+type Redaction struct {
+	StartX, StartY int
+	EndX, EndY     int
+}
+
+// Extend implements Annotation by moving the opposite corner
+func (r *Redaction) Extend(x, y int) {
+	r.EndX, r.EndY = x, y
+}
+
+// Draw implements Annotation
+func (r *Redaction) Draw(img *image.RGBA) {
+	// Include both corner pixels whichever way the rectangle was dragged
+	rect := image.Rect(r.StartX, r.StartY, r.EndX, r.EndY)
+	rect.Max = rect.Max.Add(image.Pt(1, 1))
+	for i := 0; i < redactBlurPasses; i++ {
+		blurRegion(img, rect, redactBlurRadius)
+	}
+}
+
+// blurRegion applies a box blur of the given radius to rect, in place. Only pixels
+// inside rect are read and written, so the blur never bleeds into its surroundings.
+func blurRegion(img *image.RGBA, rect image.Rectangle, radius int) {
+	rect = rect.Canon().Intersect(img.Bounds())
+	if rect.Empty() || radius <= 0 {
+		return
+	}
+
+	// Separable blur: a horizontal pass into tmp, then a vertical pass back into img
+	tmp := image.NewRGBA(rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		boxBlurLine(rect.Min.X, rect.Max.X, radius,
+			func(x int) color.RGBA { return img.RGBAAt(x, y) },
+			func(x int, c color.RGBA) { tmp.SetRGBA(x, y, c) })
+	}
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		boxBlurLine(rect.Min.Y, rect.Max.Y, radius,
+			func(y int) color.RGBA { return tmp.RGBAAt(x, y) },
+			func(y int, c color.RGBA) { img.SetRGBA(x, y, c) })
+	}
+}
+
+// boxBlurLine averages each position in [lo, hi) with its neighbours within radius,
+// using a running sum so the cost does not grow with the radius
+func boxBlurLine(lo, hi, radius int, get func(int) color.RGBA, set func(int, color.RGBA)) {
+	var sumR, sumG, sumB, sumA, count int
+	add := func(i int, sign int) {
+		c := get(i)
+		sumR += sign * int(c.R)
+		sumG += sign * int(c.G)
+		sumB += sign * int(c.B)
+		sumA += sign * int(c.A)
+		count += sign
+	}
+
+	for i := lo; i < min(lo+radius, hi); i++ {
+		add(i, 1)
+	}
+	for i := lo; i < hi; i++ {
+		if i+radius < hi {
+			add(i+radius, 1)
+		}
+		if i-radius-1 >= lo {
+			add(i-radius-1, -1)
+		}
+		set(i, color.RGBA{
+			R: uint8(sumR / count),
+			G: uint8(sumG / count),
+			B: uint8(sumB / count),
+			A: uint8(sumA / count),
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// checkerboard returns an image of alternating black and white pixels, the
+// worst case for a blur to hide
+func checkerboard(bounds image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBA{A: 255}
+			if (x+y)%2 == 0 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// changedPixels reports which pixels of img differ from original, split into
+// those inside and outside region
+func changedPixels(original, img *image.RGBA, region image.Rectangle) (inside, outside, insideTotal int) {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			in := image.Pt(x, y).In(region)
+			if in {
+				insideTotal++
+			}
+			if img.RGBAAt(x, y) == original.RGBAAt(x, y) {
+				continue
+			}
+			if in {
+				inside++
+			} else {
+				outside++
+			}
+		}
+	}
+	return inside, outside, insideTotal
+}
+
+func TestBlurRegion(t *testing.T) {
+	bounds := image.Rect(0, 0, 40, 30)
+	tests := []struct {
+		name   string
+		rect   image.Rectangle
+		radius int
+		want   image.Rectangle // Region expected to change
+	}{
+		{"inside", image.Rect(5, 5, 20, 15), 3, image.Rect(5, 5, 20, 15)},
+		{"reversed corners", image.Rect(20, 15, 5, 5), 3, image.Rect(5, 5, 20, 15)},
+		{"clipped to image", image.Rect(30, 20, 60, 50), 4, image.Rect(30, 20, 40, 30)},
+		{"large radius", image.Rect(10, 10, 14, 14), 50, image.Rect(10, 10, 14, 14)},
+		{"outside image", image.Rect(50, 50, 60, 60), 3, image.Rectangle{}},
+		{"zero radius", image.Rect(5, 5, 20, 15), 0, image.Rectangle{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := checkerboard(bounds)
+			img := checkerboard(bounds)
+			blurRegion(img, tt.rect, tt.radius)
+
+			inside, outside, total := changedPixels(original, img, tt.want)
+			if outside != 0 {
+				t.Errorf("%d pixels outside %v changed", outside, tt.want)
+			}
+			if inside != total {
+				t.Errorf("%d of %d pixels inside %v changed, want all", inside, total, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlurRegionKeepsUniformColour(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	grey := color.RGBA{R: 100, G: 110, B: 120, A: 255}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetRGBA(x, y, grey)
+		}
+	}
+	blurRegion(img, img.Bounds(), 5)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			if img.RGBAAt(x, y) != grey {
+				t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, img.RGBAAt(x, y), grey)
+			}
+		}
+	}
+}
+
+func TestRedactionDrawIncludesCorners(t *testing.T) {
+	bounds := image.Rect(0, 0, 40, 30)
+	original := checkerboard(bounds)
+	img := checkerboard(bounds)
+
+	// Dragged from bottom right to top left
+	redaction := &Redaction{StartX: 25, StartY: 20}
+	redaction.Extend(10, 5)
+	redaction.Draw(img)
+
+	region := image.Rect(10, 5, 26, 21)
+	inside, outside, total := changedPixels(original, img, region)
+	if outside != 0 {
+		t.Errorf("%d pixels outside %v changed", outside, region)
+	}
+	if inside != total {
+		t.Errorf("%d of %d pixels inside %v changed, want all", inside, total, region)
+	}
+}
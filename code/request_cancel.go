@@ -0,0 +1,48 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+)
+
+// requestContext returns the context that API requests should use. It is
+// cancelled by cancelRequests and by application shutdown.
+func (a *AppState) requestContext() context.Context {
+	a.processingMutex.Lock()
+	defer a.processingMutex.Unlock()
+
+	if a.requestCtx == nil {
+		parent := a.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		a.requestCtx, a.requestCancel = context.WithCancel(parent)
+	}
+	return a.requestCtx
+}
+
+// cancelRequests aborts all in-flight API requests immediately instead of
+// waiting for the socket timeout. Requests started afterwards get a fresh context.
+func (a *AppState) cancelRequests() {
+	a.processingMutex.Lock()
+	defer a.processingMutex.Unlock()
+
+	if a.requestCancel != nil {
+		Infof("Aborting in-flight API requests")
+		a.requestCancel()
+		a.requestCtx = nil
+		a.requestCancel = nil
+	}
+}
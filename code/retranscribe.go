@@ -0,0 +1,77 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// retranscribeStoredAudio transcribes a stored recording again with the currently
+// selected language and replaces the editor text with the result. A recording that
+// is already being re-transcribed is not submitted a second time.
+func (a *AppState) retranscribeStoredAudio(filename string) {
+	if !a.clientsReady() {
+		return
+	}
+	if _, busy := a.retranscribing.LoadOrStore(filename, true); busy {
+		setStatusText(a.statusLabel, fmt.Sprintf("%s is already being re-transcribed", filename))
+		return
+	}
+
+	language := a.selectedLanguage
+	if language == "" {
+		language = defaultLanguage
+	}
+
+	queueID := a.enqueueTranscription("start")
+	setStatusText(a.statusLabel, fmt.Sprintf("Re-transcribing %s... (%d in queue)", filename, a.queueLength()))
+
+	go func() {
+		defer func() {
+			a.dequeueTranscription(queueID)
+			a.retranscribing.Delete(filename)
+		}()
+
+		audioData, err := os.ReadFile(a.audioStorage.GetAudioFilePath(filename))
+		if err != nil {
+			Errorf("Re-transcribe: failed to read %s: %v", filename, err)
+			postStatusText(a.statusLabel, fmt.Sprintf("Cannot read %s", filename))
+			return
+		}
+
+		// The stored file's extension tells Whisper its format
+		Infof("Re-transcribing %s with language %s (%d bytes)", filename, language, len(audioData))
+		text, err := a.transcribeWithRetry(a.requestContext(), audioData, filename, language, a.storedAudioDuration(filename))
+		if err != nil {
+			Errorf("Re-transcribe of %s failed: %v", filename, err)
+			postStatusText(a.statusLabel, fmt.Sprintf("Re-transcription failed: %s", transcriptionFailureReason(err)))
+			return
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			postStatusText(a.statusLabel, "No speech detected")
+			return
+		}
+
+		fyne.Do(func() { a.correctedText.SetText(text) })
+		a.saveJobTranscript(transcriptionJob{mode: "start", recordingFile: filename}, text, language)
+		postStatusText(a.statusLabel, fmt.Sprintf("Re-transcribed %s", filename))
+		Infof("Re-transcribed %s (%d characters)", filename, len(text))
+	}()
+}
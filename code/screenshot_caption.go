@@ -0,0 +1,190 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"golang.org/x/image/font"
+)
+
+// captionMargin is the distance of a dictated caption from the image edges in pixels
+const captionMargin = 10
+
+// captionHandler receives the transcription of a dictated screenshot note, or an
+// empty string if the note was canceled or produced no text. It is called on the
+// UI goroutine.
+type captionHandler func(text string)
+
+// takeCaptionTarget returns the handler waiting for the current recording and clears it
+func (a *AppState) takeCaptionTarget() captionHandler {
+	handler := a.captionTarget
+	a.captionTarget = nil
+	return handler
+}
+
+// dropCaptionTarget tells a waiting editor that its note will not arrive
+func (a *AppState) dropCaptionTarget() {
+	if handler := a.takeCaptionTarget(); handler != nil {
+		handler("")
+	}
+}
+
+// startCaptionRecording starts a normal recording whose transcription is passed
+// to onText instead of being inserted into the text area
+func (a *AppState) startCaptionRecording(onText captionHandler) error {
+	if a.isRecording {
+		return errors.New("a recording is already in progress")
+	}
+
+	a.captionTarget = onText
+	a.beginRecording("start", nil)
+	if !a.isRecording {
+		a.captionTarget = nil
+		return errors.New("recording could not be started")
+	}
+	setStatusText(a.statusLabel, "Recording note for screenshot...")
+	return nil
+}
+
+// processCaptionJob transcribes a dictated screenshot note and hands the text to
+// the editor that requested it
+func (a *AppState) processCaptionJob(job transcriptionJob) {
+	result := a.transcribeJob(job)
+
+	text := ""
+	switch {
+	case result.Canceled:
+		postStatusText(a.statusLabel, "Note canceled")
+	case result.Err != nil:
+		Errorf("Note transcription failed: %v", result.Err)
+		postStatusText(a.statusLabel, "Note transcription failed: "+transcriptionFailureReason(result.Err))
+	case result.Text == "":
+		postStatusText(a.statusLabel, "No speech detected in note")
+	default:
+		text = result.Text
+		a.saveJobTranscript(job, text, result.Language)
+		postStatusText(a.statusLabel, "Note added to screenshot")
+	}
+	fyne.Do(func() { job.caption(text) })
+}
+
+// addCaption draws text along the bottom of the screenshot in the current style,
+// wrapped to the image width. It is a single annotation, so one undo removes it.
+func (c *imageEditorCanvas) addCaption(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	size := textSizeForWidth(c.drawWidth)
+	face := annotationFace(size)
+	bounds := c.baseImage.Bounds()
+	lines := wrapCaption(text, face, bounds.Dx()-2*captionMargin)
+
+	height := len(lines) * face.Metrics().Height.Ceil()
+	caption := &TextAnnotation{
+		X:     bounds.Min.X + captionMargin,
+		Y:     max(bounds.Min.Y, bounds.Max.Y-captionMargin-height),
+		Text:  strings.Join(lines, "\n"),
+		Color: c.drawColor,
+		Size:  size,
+	}
+
+	c.commitText()
+	c.addAnnotation(caption)
+	Debugf("Image editor: dictated caption added (%d lines)", len(lines))
+	c.Refresh()
+}
+
+// wrapCaption breaks text into lines no wider than maxWidth pixels. Words longer
+// than a line are kept whole.
+func wrapCaption(text string, face font.Face, maxWidth int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && font.MeasureString(face, candidate).Ceil() > maxWidth {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	return append(lines, line)
+}
+
+// newRecordNoteButton builds the editor button that dictates a caption for the
+// screenshot. The first click starts recording, the second one stops it, and the
+// transcription is drawn onto the image when it arrives.
+func newRecordNoteButton(canvasWidget *imageEditorCanvas, appState *AppState) *widget.Button {
+	var button *widget.Button
+	button = widget.NewButton("Record note", func() {
+		if canvasWidget.notePending {
+			if !appState.isRecording {
+				return // Already transcribing
+			}
+			if err := appState.FinalizeRecording(); err != nil {
+				Errorf("Failed to stop note recording: %v", err)
+				setStatusText(appState.statusLabel, fmt.Sprintf("Stop error: %v", err))
+				return
+			}
+			button.SetText("Transcribing note...")
+			button.Disable()
+			return
+		}
+
+		err := appState.startCaptionRecording(func(text string) {
+			canvasWidget.notePending = false
+			canvasWidget.addCaption(text)
+			button.SetText("Record note")
+			button.Enable()
+		})
+		if err != nil {
+			Errorf("Failed to start note recording: %v", err)
+			setStatusText(appState.statusLabel, fmt.Sprintf("Cannot record note: %v", err))
+			return
+		}
+		canvasWidget.notePending = true
+		button.SetText("Stop note")
+	})
+	return button
+}
+
+// notePendingBlocksClose reports whether the editor must stay open because a
+// dictated note has not been added yet, telling the user so
+func notePendingBlocksClose(canvasWidget *imageEditorCanvas, appState *AppState) bool {
+	if !canvasWidget.notePending {
+		return false
+	}
+	setStatusText(appState.statusLabel, "Waiting for the note; Escape in the main window cancels it")
+	return true
+}
+
+// editorContent lays out the editor canvas, with the note button below it when
+// the editor belongs to the main window
+func editorContent(canvasWidget *imageEditorCanvas, appState *AppState) fyne.CanvasObject {
+	canvasContainer := container.NewMax(canvasWidget)
+	if appState == nil {
+		return canvasContainer
+	}
+	return container.NewBorder(nil, newRecordNoteButton(canvasWidget, appState), nil, nil, canvasContainer)
+}
@@ -0,0 +1,145 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Preference keys for saving edited screenshots to disk. The folder is shared
+// with text exports.
+const (
+	screenshotDirPrefKey     = "screenshotSaveDir"
+	screenshotQualityPrefKey = "screenshotJPEGQuality"
+	defaultJPEGQuality       = 90
+)
+
+// isJPEGPath reports whether path has a JPEG file extension
+func isJPEGPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".jpg" || ext == ".jpeg"
+}
+
+// encodeScreenshot encodes img as JPEG at quality (1-100) or as PNG
+func encodeScreenshot(img image.Image, asJPEG bool, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if asJPEG {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode JPEG: %v", err)
+		}
+		return buf.Bytes(), nil
+	}
+	if err := encodePNG(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// showSaveScreenshotDialog asks where to save the edited screenshot. Files ending
+// in .jpg or .jpeg are saved as JPEG after asking for the quality, anything else
+// as PNG. The folder and quality are remembered for next time.
+func showSaveScreenshotDialog(window fyne.Window, c *imageEditorCanvas, appState *AppState) {
+	c.commitText()
+	prefs := fyne.CurrentApp().Preferences()
+
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			Errorf("Save screenshot dialog failed: %v", err)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+
+		path := writer.URI().Path()
+		prefs.SetString(screenshotDirPrefKey, filepath.Dir(path))
+		if !isJPEGPath(path) {
+			writeScreenshot(writer, c.renderAnnotated(), false, 0, appState)
+			return
+		}
+
+		quality := widget.NewSlider(1, 100)
+		quality.Step = 1
+		quality.SetValue(float64(prefs.IntWithFallback(screenshotQualityPrefKey, defaultJPEGQuality)))
+		qualityLabel := widget.NewLabel("")
+		quality.OnChanged = func(value float64) {
+			qualityLabel.SetText(fmt.Sprintf("%.0f", value))
+		}
+		quality.OnChanged(quality.Value)
+
+		dialog.ShowForm("JPEG quality", "Save", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Quality", container.NewBorder(nil, nil, nil, qualityLabel, quality))},
+			func(confirmed bool) {
+				if !confirmed {
+					writer.Close()
+					return
+				}
+				prefs.SetInt(screenshotQualityPrefKey, int(quality.Value))
+				writeScreenshot(writer, c.renderAnnotated(), true, int(quality.Value), appState)
+			}, window)
+	}, window)
+
+	save.SetFileName(fmt.Sprintf("screenshot_%s.png", time.Now().Format("20060102_150405")))
+	save.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg"}))
+	setSaveLocation(save, prefs)
+	save.Resize(window.Canvas().Size())
+	save.Show()
+}
+
+// setSaveLocation opens save in the folder last saved to, if it still exists
+func setSaveLocation(save *dialog.FileDialog, prefs fyne.Preferences) {
+	if dir := prefs.String(screenshotDirPrefKey); dir != "" {
+		if location, err := storage.ListerForURI(storage.NewFileURI(dir)); err == nil {
+			save.SetLocation(location)
+		}
+	}
+}
+
+// writeScreenshot encodes img and writes it to writer, reporting the outcome in the status bar
+func writeScreenshot(writer fyne.URIWriteCloser, img image.Image, asJPEG bool, quality int, appState *AppState) {
+	defer writer.Close()
+
+	data, err := encodeScreenshot(img, asJPEG, quality)
+	if err == nil && !asJPEG && appState != nil && appState.config.EmbedTranscript && appState.correctedText != nil {
+		data = embedTranscriptInPNG(data, appState.correctedText.Text)
+	}
+	if err == nil {
+		_, err = writer.Write(data)
+	}
+
+	name := writer.URI().Name()
+	if err != nil {
+		Errorf("Failed to save screenshot to %s: %v", writer.URI().Path(), err)
+		if appState != nil {
+			setStatusText(appState.statusLabel, fmt.Sprintf("Save failed: %v", err))
+		}
+		return
+	}
+	Infof("Saved screenshot to %s (%d bytes)", writer.URI().Path(), len(data))
+	if appState != nil {
+		setStatusText(appState.statusLabel, fmt.Sprintf("Screenshot saved as %s", name))
+	}
+}
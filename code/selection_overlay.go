@@ -0,0 +1,229 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+const (
+	overlayTitle          = "MICAPP selection"
+	overlayThickness      = 2
+	overlayOpacity        = "0x66666666"           // _NET_WM_WINDOW_OPACITY of the window, 40%
+	overlayUpdateInterval = 100 * time.Millisecond // Limits how often xdotool is spawned while dragging
+	overlayHideDelay      = 100 * time.Millisecond // Lets the compositor remove the frame before capturing
+)
+
+// overlayColor is the colour of the selection frame, overlayFill of the area inside
+var (
+	overlayColor = color.RGBA{R: 255, G: 60, B: 60, A: 255}
+	overlayFill  = color.RGBA{R: 255, G: 60, B: 60, A: 40}
+)
+
+// selectionOverlay draws the screenshot selection as one borderless window covering
+// it, framed in red and made translucent where a compositor supports window opacity.
+// The window is created once and moved, resized, shown and hidden as the selection
+// changes. Fyne cannot position windows itself, so it is placed with xdotool like the
+// main window.
+type selectionOverlay struct {
+	mutex   sync.Mutex
+	rect    image.Rectangle // Selection in global screen coordinates
+	visible bool
+	changed chan struct{}
+
+	applyMutex sync.Mutex      // Serializes window updates
+	window     fyne.Window     // Created on first use
+	windowID   string          // X window ID of window, looked up once it is shown
+	shown      bool            // Whether window was last shown
+	placed     image.Rectangle // Geometry last given to xdotool, to skip unchanged updates
+	raised     bool            // Whether the window was asked to stay above other windows
+}
+
+// newSelectionOverlay creates an overlay, or returns nil where it cannot work
+// (Wayland, or xdotool not installed)
+func newSelectionOverlay() *selectionOverlay {
+	if waylandSession() {
+		Infof("Selection overlay disabled: windows cannot be positioned under Wayland")
+		return nil
+	}
+	if _, err := exec.LookPath("xdotool"); err != nil {
+		Infof("Selection overlay disabled: xdotool not found")
+		return nil
+	}
+	return &selectionOverlay{changed: make(chan struct{}, 1)}
+}
+
+// show displays the frame around rect, or moves it there
+func (o *selectionOverlay) show(rect image.Rectangle) {
+	if o == nil {
+		return
+	}
+	o.mutex.Lock()
+	o.rect, o.visible = rect.Canon(), true
+	o.mutex.Unlock()
+	o.kick()
+}
+
+// hide removes the frame
+func (o *selectionOverlay) hide() {
+	if o == nil {
+		return
+	}
+	o.mutex.Lock()
+	o.visible = false
+	o.mutex.Unlock()
+	o.kick()
+}
+
+// hideNow removes the frame and waits until it is gone from the screen,
+// so it does not end up in the screenshot. It must not be called on the UI goroutine.
+func (o *selectionOverlay) hideNow() {
+	if o == nil {
+		return
+	}
+	o.mutex.Lock()
+	wasVisible := o.visible
+	o.visible = false
+	o.mutex.Unlock()
+
+	o.apply()
+	if wasVisible {
+		time.Sleep(overlayHideDelay)
+	}
+}
+
+// kick asks the update loop to apply the latest state
+func (o *selectionOverlay) kick() {
+	select {
+	case o.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run applies overlay changes until ctx is cancelled, at most once per overlayUpdateInterval
+func (o *selectionOverlay) run(ctx context.Context) {
+	if o == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			o.hideNow()
+			return
+		case <-o.changed:
+			o.apply()
+			time.Sleep(overlayUpdateInterval)
+		}
+	}
+}
+
+// apply shows, places or hides the window to match the current state. Window
+// calls are made on the UI goroutine and waited for, so xdotool finds the window
+// as it is; apply itself must therefore run on another goroutine.
+func (o *selectionOverlay) apply() {
+	o.applyMutex.Lock()
+	defer o.applyMutex.Unlock()
+
+	o.mutex.Lock()
+	rect, visible := o.rect, o.visible
+	o.mutex.Unlock()
+
+	if !visible || rect.Dx() < 2*overlayThickness || rect.Dy() < 2*overlayThickness {
+		if o.shown {
+			fyne.DoAndWait(o.window.Hide)
+			o.shown = false
+			o.placed = image.Rectangle{}
+		}
+		return
+	}
+	if rect == o.placed {
+		return
+	}
+
+	ok := true
+	size := fyne.NewSize(float32(rect.Dx()), float32(rect.Dy()))
+	fyne.DoAndWait(func() {
+		if o.window == nil {
+			if ok = o.createWindow(); !ok {
+				return
+			}
+		}
+		o.window.Resize(size)
+		o.window.Show()
+	})
+	if !ok {
+		return
+	}
+	o.shown = true
+
+	if o.windowID == "" {
+		out, err := exec.Command("xdotool", "search", "--name", "^"+overlayTitle+"$").Output()
+		ids := strings.Fields(string(out))
+		if err != nil || len(ids) == 0 {
+			Debugf("Selection overlay window not found yet: %v", err)
+			return
+		}
+		o.windowID = ids[0]
+	}
+
+	x, y := fmt.Sprint(rect.Min.X), fmt.Sprint(rect.Min.Y)
+	w, h := fmt.Sprint(rect.Dx()), fmt.Sprint(rect.Dy())
+	if err := exec.Command("xdotool", "windowmove", o.windowID, x, y, "windowsize", o.windowID, w, h).Run(); err != nil {
+		Errorf("Failed to position selection overlay: %v", err)
+		return
+	}
+	o.placed = rect
+
+	// Best effort once the window exists: without wmctrl the frame may end up
+	// behind the window being selected, and without a compositor it is opaque
+	if !o.raised {
+		o.raised = true
+		exec.Command("wmctrl", "-r", overlayTitle, "-b", "add,above").Run()
+		exec.Command("xprop", "-id", o.windowID, "-f", "_NET_WM_WINDOW_OPACITY", "32c",
+			"-set", "_NET_WM_WINDOW_OPACITY", overlayOpacity).Run()
+	}
+}
+
+// createWindow creates the borderless overlay window. It runs on the UI goroutine.
+func (o *selectionOverlay) createWindow() bool {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return false
+	}
+	drv, ok := app.Driver().(desktop.Driver)
+	if !ok {
+		Infof("Selection overlay disabled: driver cannot create borderless windows")
+		return false
+	}
+
+	frame := canvas.NewRectangle(overlayFill)
+	frame.StrokeColor = overlayColor
+	frame.StrokeWidth = overlayThickness
+	o.window = drv.CreateSplashWindow()
+	o.window.SetTitle(overlayTitle)
+	o.window.SetContent(frame)
+	o.window.SetPadded(false)
+	return true
+}
@@ -0,0 +1,133 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	sessionFile       = "session.txt" // Editor text saved between runs
+	sessionArchiveDir = "sessions"    // Previous sessions archived by "New session"
+	sessionSaveDelay  = 2 * time.Second
+)
+
+// loadSession returns the editor text saved by the previous run, or "" if there is none.
+// Session files of earlier versions are moved from the working directory first.
+func (a *AppState) loadSession() string {
+	moveToAppData(a.dataDir, sessionFile)
+	moveToAppData(a.dataDir, sessionArchiveDir)
+
+	path := filepath.Join(a.dataDir, sessionFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			Errorf("Failed to load session: %v", err)
+		}
+		return ""
+	}
+	Infof("Restored session text (%d bytes) from %s", len(data), path)
+	return string(data)
+}
+
+// writeSession saves text to path through a temporary file, so a crash
+// mid-write never leaves a truncated session behind
+func writeSession(path string, text string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(text), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %v", path, err)
+	}
+	return nil
+}
+
+// saveSession writes the current editor text to the session file. It must be
+// called on the UI goroutine.
+func (a *AppState) saveSession() {
+	if a.correctedText == nil {
+		return
+	}
+	a.saveSessionText(a.correctedText.Text)
+}
+
+// saveSessionText writes text to the session file
+func (a *AppState) saveSessionText(text string) {
+	if err := writeSession(filepath.Join(a.dataDir, sessionFile), text); err != nil {
+		Errorf("Failed to save session: %v", err)
+	}
+}
+
+// watchSession saves the editor text shortly after it stops changing
+func (a *AppState) watchSession() {
+	a.correctedText.OnChanged = func(text string) {
+		a.updateTextStats(text)
+		select {
+		case a.sessionChanged <- struct{}{}:
+		default:
+		}
+	}
+	go a.runSessionAutosave()
+}
+
+// runSessionAutosave debounces editor changes so typing doesn't write on every keystroke.
+// The text is taken on the UI goroutine and written from here.
+func (a *AppState) runSessionAutosave() {
+	timer := time.NewTimer(sessionSaveDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			timer.Stop()
+			return
+		case <-a.sessionChanged:
+			timer.Reset(sessionSaveDelay)
+		case <-timer.C:
+			text := a.editorText()
+			if a.ctx.Err() != nil {
+				return // Shutting down; the final text is saved on close
+			}
+			a.saveSessionText(text)
+		}
+	}
+}
+
+// newSession archives the current editor text under sessions/ in the application
+// data folder with a timestamp and starts over with an empty editor
+func (a *AppState) newSession() {
+	if text := a.correctedText.Text; text != "" {
+		archiveDir := filepath.Join(a.dataDir, sessionArchiveDir)
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			Errorf("Failed to create session archive: %v", err)
+			setStatusText(a.statusLabel, fmt.Sprintf("New session failed: %v", err))
+			return
+		}
+		name := fmt.Sprintf("session_%s.txt", time.Now().Format("20060102_150405"))
+		if err := writeSession(filepath.Join(archiveDir, name), text); err != nil {
+			Errorf("Failed to archive session: %v", err)
+			setStatusText(a.statusLabel, fmt.Sprintf("New session failed: %v", err))
+			return
+		}
+		Infof("Archived session as %s", name)
+	}
+
+	a.clearCorrectedText()
+	a.saveSession()
+	setStatusText(a.statusLabel, "New session started")
+}
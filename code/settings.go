@@ -0,0 +1,321 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	hook "github.com/robotn/gohook"
+)
+
+// Preferences keys of the settings saved with "Save" in the Settings tab. Saved
+// values win over the environment variables, which only provide the defaults.
+const (
+	transcriptionModelPrefKey = "transcriptionModel"
+	correctionModelPrefKey    = "correctionModel"
+	captureKeyPrefKey         = "captureKey"
+	reviewCorrectionsPrefKey  = "reviewCorrections"
+	reviewTimeoutPrefKey      = "reviewTimeoutSeconds"
+	autoStopSilencePrefKey    = "autoStopSilenceSeconds"
+	formatParagraphsPrefKey   = "formatParagraphs"
+	voiceCommandsPrefKey      = "voiceCommands"
+	windowPositionPrefKey     = "restoreWindowPosition"
+	captureEnabledPrefKey     = "screenshotCapture"
+	highPassCutoffPrefKey     = "highPassCutoffHz"
+	noiseGatePrefKey          = "noiseGateLevel"
+)
+
+// applySavedSettings overrides config with the settings saved in the Settings tab
+func applySavedSettings(config *Config, prefs fyne.Preferences) {
+	config.WhisperModel = prefs.StringWithFallback(transcriptionModelPrefKey, config.WhisperModel)
+	config.CorrectionModel = prefs.StringWithFallback(correctionModelPrefKey, config.CorrectionModel)
+	config.CaptureEnabled = prefs.BoolWithFallback(captureEnabledPrefKey, config.CaptureEnabled)
+	config.CaptureKey = prefs.StringWithFallback(captureKeyPrefKey, config.CaptureKey)
+	config.ReviewCorrections = prefs.BoolWithFallback(reviewCorrectionsPrefKey, config.ReviewCorrections)
+	config.ReviewTimeout = time.Duration(prefs.IntWithFallback(reviewTimeoutPrefKey, int(config.ReviewTimeout/time.Second))) * time.Second
+	config.AutoStopSilence = time.Duration(prefs.IntWithFallback(autoStopSilencePrefKey, int(config.AutoStopSilence/time.Second))) * time.Second
+	config.FormatParagraphs = prefs.BoolWithFallback(formatParagraphsPrefKey, config.FormatParagraphs)
+	config.VoiceCommands = prefs.BoolWithFallback(voiceCommandsPrefKey, config.VoiceCommands)
+	config.HighPassCutoff = prefs.IntWithFallback(highPassCutoffPrefKey, config.HighPassCutoff)
+	config.NoiseGate = prefs.IntWithFallback(noiseGatePrefKey, config.NoiseGate)
+	config.WindowPosition = prefs.BoolWithFallback(windowPositionPrefKey, config.WindowPosition)
+}
+
+// validateSeconds accepts a whole, non-negative number of seconds
+func validateSeconds(text string) error {
+	seconds, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || seconds < 0 {
+		return errors.New("enter a whole number of seconds, 0 or more")
+	}
+	return nil
+}
+
+// validateLevel accepts a whole, non-negative number such as a frequency or RMS level
+func validateLevel(text string) error {
+	value, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || value < 0 {
+		return errors.New("enter a whole number, 0 or more")
+	}
+	return nil
+}
+
+// validateAPIKeyFormat accepts an empty field (keep the current key) or
+// something shaped like an OpenAI key
+func validateAPIKeyFormat(key string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil
+	}
+	if !strings.HasPrefix(key, "sk-") || strings.ContainsAny(key, " \t") {
+		return errors.New("OpenAI keys start with sk- and contain no spaces")
+	}
+	return nil
+}
+
+// validateCaptureKey accepts an empty field (single-key capture off) or a key gohook knows
+func validateCaptureKey(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil
+	}
+	if _, ok := extraCaptureKeycodes[name]; ok {
+		return nil
+	}
+	if _, ok := hook.Keycode[name]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown key %q", name)
+}
+
+// mirrorSelect returns a selector that stays in sync with original, so a toolbar
+// control can also be changed from the Settings tab
+func mirrorSelect(original *widget.Select) *widget.Select {
+	mirror := widget.NewSelect(original.Options, nil)
+	mirror.SetSelected(original.Selected)
+
+	onChanged := original.OnChanged
+	original.OnChanged = func(selected string) {
+		if onChanged != nil {
+			onChanged(selected)
+		}
+		if mirror.Selected != selected {
+			mirror.SetSelected(selected)
+		}
+	}
+	mirror.OnChanged = func(selected string) {
+		if original.Selected != selected {
+			original.SetSelected(selected)
+		}
+	}
+	return mirror
+}
+
+// mirrorCheck returns a check box that stays in sync with original
+func mirrorCheck(original *widget.Check) *widget.Check {
+	mirror := widget.NewCheck("", nil)
+	mirror.SetChecked(original.Checked)
+
+	onChanged := original.OnChanged
+	original.OnChanged = func(checked bool) {
+		if onChanged != nil {
+			onChanged(checked)
+		}
+		if mirror.Checked != checked {
+			mirror.SetChecked(checked)
+		}
+	}
+	mirror.OnChanged = func(checked bool) {
+		if original.Checked != checked {
+			original.SetChecked(checked)
+		}
+	}
+	return mirror
+}
+
+// newGeneralSettings lists the toolbar controls in the Settings tab. Like in the
+// toolbar, changes apply and are saved immediately.
+func (a *AppState) newGeneralSettings(prefs fyne.Preferences, languageSelect *widget.Select, deviceSelect *widget.Select, correctionCheck *widget.Check) *widget.Form {
+	return widget.NewForm(
+		widget.NewFormItem("Language", mirrorSelect(languageSelect)),
+		widget.NewFormItem("Microphone", mirrorSelect(deviceSelect)),
+		widget.NewFormItem("GPT correction", mirrorCheck(correctionCheck)),
+		widget.NewFormItem("Paragraphs", a.newFormatParagraphsCheck(prefs)),
+		widget.NewFormItem("Voice commands", a.newVoiceCommandsCheck(prefs)),
+		widget.NewFormItem("Window", a.newWindowPositionCheck(prefs)),
+		widget.NewFormItem("Log level", a.newLogLevelSelect(prefs)),
+	)
+}
+
+// newFormatParagraphsCheck toggles splitting transcriptions into paragraphs
+func (a *AppState) newFormatParagraphsCheck(prefs fyne.Preferences) *widget.Check {
+	check := widget.NewCheck("Split long transcriptions into paragraphs", nil)
+	check.SetChecked(a.config.FormatParagraphs)
+	check.OnChanged = func(enabled bool) {
+		a.config.FormatParagraphs = enabled
+		prefs.SetBool(formatParagraphsPrefKey, enabled)
+	}
+	return check
+}
+
+// newVoiceCommandsCheck toggles carrying out spoken commands such as "new line"
+func (a *AppState) newVoiceCommandsCheck(prefs fyne.Preferences) *widget.Check {
+	check := widget.NewCheck(`Act on "new line", "comma", "scratch that"…`, nil)
+	check.SetChecked(a.config.VoiceCommands)
+	check.OnChanged = func(enabled bool) {
+		a.config.VoiceCommands = enabled
+		prefs.SetBool(voiceCommandsPrefKey, enabled)
+	}
+	return check
+}
+
+// newServiceSettings builds the form of settings applied with "Save": API key,
+// models, capture key, correction review and auto-stop. Fields are validated
+// before saving and a new key is checked with the API; a changed key or model
+// recreates the clients.
+func (a *AppState) newServiceSettings(prefs fyne.Preferences) *widget.Form {
+	apiKeyEntry := widget.NewPasswordEntry()
+	apiKeyEntry.SetPlaceHolder("Leave empty to keep the current key")
+	apiKeyEntry.Validator = validateAPIKeyFormat
+
+	transcriptionModelEntry := widget.NewEntry()
+	transcriptionModelEntry.SetText(a.config.WhisperModel)
+	correctionModelEntry := widget.NewEntry()
+	correctionModelEntry.SetText(a.config.CorrectionModel)
+
+	captureKeyEntry := widget.NewEntry()
+	captureKeyEntry.SetPlaceHolder("e.g. printscreen, empty to disable")
+	captureKeyEntry.SetText(a.config.CaptureKey)
+	captureKeyEntry.Validator = validateCaptureKey
+
+	reviewCheck := widget.NewCheck("", nil)
+	reviewCheck.SetChecked(a.config.ReviewCorrections)
+
+	reviewTimeoutEntry := widget.NewEntry()
+	reviewTimeoutEntry.SetText(strconv.Itoa(int(a.config.ReviewTimeout / time.Second)))
+	reviewTimeoutEntry.Validator = validateSeconds
+
+	autoStopEntry := widget.NewEntry()
+	autoStopEntry.SetText(strconv.Itoa(int(a.config.AutoStopSilence / time.Second)))
+	autoStopEntry.Validator = validateSeconds
+
+	highPassEntry := widget.NewEntry()
+	highPassEntry.SetText(strconv.Itoa(a.config.HighPassCutoff))
+	highPassEntry.Validator = validateLevel
+
+	noiseGateEntry := widget.NewEntry()
+	noiseGateEntry.SetText(strconv.Itoa(a.config.NoiseGate))
+	noiseGateEntry.Validator = validateLevel
+
+	form := widget.NewForm(
+		widget.NewFormItem("OpenAI API key", apiKeyEntry),
+		widget.NewFormItem("Transcription model", transcriptionModelEntry),
+		widget.NewFormItem("Correction model", correctionModelEntry),
+		widget.NewFormItem("Capture key", captureKeyEntry),
+		widget.NewFormItem("Review corrections", reviewCheck),
+		&widget.FormItem{Text: "Review timeout (s)", Widget: reviewTimeoutEntry, HintText: "0 waits until you decide"},
+		&widget.FormItem{Text: "Auto-stop after silence (s)", Widget: autoStopEntry, HintText: "0 disables auto-stop"},
+		&widget.FormItem{Text: "High-pass filter (Hz)", Widget: highPassEntry, HintText: "Removes hum below this, e.g. 80; 0 disables"},
+		&widget.FormItem{Text: "Noise gate (RMS)", Widget: noiseGateEntry, HintText: "Silences quieter audio, e.g. 300; 0 disables"},
+	)
+	form.SubmitText = "Save"
+	// save applies and stores the fields; key is empty or has passed ValidateKey
+	save := func(key string) {
+		transcriptionModel := strings.TrimSpace(transcriptionModelEntry.Text)
+		correctionModel := strings.TrimSpace(correctionModelEntry.Text)
+		reviewTimeout, _ := strconv.Atoi(strings.TrimSpace(reviewTimeoutEntry.Text))
+		autoStop, _ := strconv.Atoi(strings.TrimSpace(autoStopEntry.Text))
+		highPass, _ := strconv.Atoi(strings.TrimSpace(highPassEntry.Text))
+		noiseGate, _ := strconv.Atoi(strings.TrimSpace(noiseGateEntry.Text))
+
+		recreate := key != "" || transcriptionModel != a.config.WhisperModel || correctionModel != a.config.CorrectionModel
+		a.config.WhisperModel = transcriptionModel
+		a.config.CorrectionModel = correctionModel
+		captureKey := strings.ToLower(strings.TrimSpace(captureKeyEntry.Text))
+		restartHook := captureKey != a.config.CaptureKey
+		a.config.CaptureKey = captureKey
+		a.config.ReviewCorrections = reviewCheck.Checked
+		a.config.ReviewTimeout = time.Duration(reviewTimeout) * time.Second
+		a.config.AutoStopSilence = time.Duration(autoStop) * time.Second
+		a.config.HighPassCutoff = highPass
+		a.config.NoiseGate = noiseGate
+
+		prefs.SetString(transcriptionModelPrefKey, a.config.WhisperModel)
+		prefs.SetString(correctionModelPrefKey, a.config.CorrectionModel)
+		prefs.SetString(captureKeyPrefKey, a.config.CaptureKey)
+		prefs.SetBool(reviewCorrectionsPrefKey, a.config.ReviewCorrections)
+		prefs.SetInt(reviewTimeoutPrefKey, reviewTimeout)
+		prefs.SetInt(autoStopSilencePrefKey, autoStop)
+		prefs.SetInt(highPassCutoffPrefKey, highPass)
+		prefs.SetInt(noiseGatePrefKey, noiseGate)
+		Infof("Settings saved")
+
+		// The capture key is read when the hook starts
+		if restartHook {
+			a.stopMouseHook()
+			a.startMouseHook(a.ctx)
+		}
+
+		if recreate {
+			if key != "" {
+				os.Setenv("OPENAI_API_KEY", key)
+			}
+			if err := a.createClients(); err != nil {
+				Errorf("Failed to apply the new settings: %v", err)
+				dialog.ShowError(fmt.Errorf("settings saved, but the clients could not be created: %v", err), a.mainWindow)
+				return
+			}
+			if key != "" {
+				prefs.SetString(apiKeyPrefKey, key)
+				prefs.SetString(apiKeyCheckedPrefKey, apiKeyFingerprint(key))
+				apiKeyEntry.SetText("")
+			}
+			a.clientsCreated()
+		}
+		setStatusText(a.statusLabel, "Settings saved")
+	}
+
+	form.OnSubmit = func() {
+		key := strings.TrimSpace(apiKeyEntry.Text)
+		if key == "" {
+			save("")
+			return
+		}
+
+		// A new key is checked against the API before anything is saved
+		setStatusText(a.statusLabel, "Checking the API key...")
+		form.Disable()
+		go func() {
+			defer fyne.Do(form.Enable)
+			if err := validateAPIKey(a.ctx, a.config.OpenAIBaseURL, key); err != nil {
+				Warnf("API key entered in settings failed validation: %v", err)
+				postStatusText(a.statusLabel, "API key not saved")
+				fyne.Do(func() { dialog.ShowError(errors.New(describeKeyError(err)), a.mainWindow) })
+				return
+			}
+			fyne.Do(func() {
+				dialog.ShowInformation("API key verified", "The key works and has been saved.", a.mainWindow)
+				save(key)
+			})
+		}()
+	}
+	return form
+}
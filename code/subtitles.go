@@ -0,0 +1,146 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Subtitle formats supported by ExportSubtitles
+const (
+	SubtitleFormatSRT = "srt"
+	SubtitleFormatVTT = "vtt"
+)
+
+// minSubtitleDuration is the shortest cue kept on its own; shorter segments are
+// merged into the previous one so subtitles don't flash by unreadably
+const minSubtitleDuration = time.Second
+
+// TranscriptSegment is a piece of transcribed text with its position in the recording
+type TranscriptSegment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// formatTimestamp formats d as HH:MM:SS<sep>mmm, rounding to the nearest millisecond.
+// SRT uses ',' as separator and WebVTT uses '.'. Hours are always at least two digits
+// and negative durations are clamped to zero.
+func formatTimestamp(d time.Duration, sep rune) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Round(time.Millisecond).Milliseconds()
+
+	hours := ms / 3600000
+	minutes := (ms / 60000) % 60
+	seconds := (ms / 1000) % 60
+	millis := ms % 1000
+
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", hours, minutes, seconds, sep, millis)
+}
+
+// ExportSubtitles renders segments as an SRT or WebVTT document ("srt" or "vtt").
+// Very short segments are merged into their neighbours first.
+func ExportSubtitles(segments []TranscriptSegment, format string) ([]byte, error) {
+	merged := mergeShortSegments(segments, minSubtitleDuration)
+	switch strings.ToLower(format) {
+	case SubtitleFormatSRT:
+		return []byte(ExportSRT(merged)), nil
+	case SubtitleFormatVTT:
+		return []byte(ExportVTT(merged)), nil
+	default:
+		return nil, fmt.Errorf("unsupported subtitle format: %q", format)
+	}
+}
+
+// mergeShortSegments joins segments shorter than minDuration onto the previous
+// segment (or the next one, for a short first segment). Empty segments are dropped.
+func mergeShortSegments(segments []TranscriptSegment, minDuration time.Duration) []TranscriptSegment {
+	var merged []TranscriptSegment
+	carry := TranscriptSegment{}
+	hasCarry := false
+
+	for _, seg := range segments {
+		seg.Text = strings.TrimSpace(seg.Text)
+		if seg.Text == "" {
+			continue
+		}
+		if hasCarry {
+			seg.Start = carry.Start
+			seg.Text = carry.Text + " " + seg.Text
+			hasCarry = false
+		}
+
+		switch {
+		case seg.End-seg.Start >= minDuration:
+			merged = append(merged, seg)
+		case len(merged) > 0:
+			last := &merged[len(merged)-1]
+			last.End = max(last.End, seg.End)
+			last.Text += " " + seg.Text
+		default:
+			carry, hasCarry = seg, true
+		}
+	}
+	if hasCarry {
+		merged = append(merged, carry)
+	}
+	return merged
+}
+
+// cueText prepares segment text for a cue. Blank lines would end the cue early,
+// so lines are collapsed; WebVTT additionally needs markup characters escaped.
+func cueText(text string, vtt bool) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if vtt {
+		text = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+	} else {
+		text = strings.ReplaceAll(text, "-->", "->")
+	}
+	return text
+}
+
+// ExportSRT renders segments as a SubRip (.srt) document
+func ExportSRT(segments []TranscriptSegment) string {
+	var b strings.Builder
+	index := 1
+	for _, seg := range segments {
+		text := cueText(seg.Text, false)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n",
+			index, formatTimestamp(seg.Start, ','), formatTimestamp(seg.End, ','), text)
+		index++
+	}
+	return b.String()
+}
+
+// ExportVTT renders segments as a WebVTT (.vtt) document
+func ExportVTT(segments []TranscriptSegment) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, seg := range segments {
+		text := cueText(seg.Text, true)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n",
+			formatTimestamp(seg.Start, '.'), formatTimestamp(seg.End, '.'), text)
+	}
+	return b.String()
+}
@@ -0,0 +1,151 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		name string
+		d    time.Duration
+		sep  rune
+		want string
+	}{
+		{"zero", 0, ',', "00:00:00,000"},
+		{"zero vtt", 0, '.', "00:00:00.000"},
+		{"milliseconds", 1234 * time.Millisecond, ',', "00:00:01,234"},
+		{"minutes", 2*time.Minute + 3*time.Second + 45*time.Millisecond, '.', "00:02:03.045"},
+		{"over an hour", time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, ',', "01:02:03,004"},
+		{"over ten hours", 12*time.Hour + 59*time.Minute + 59*time.Second + 999*time.Millisecond, ',', "12:59:59,999"},
+		{"over a hundred hours", 123 * time.Hour, ',', "123:00:00,000"},
+		{"sub-millisecond rounds down", 1499 * time.Microsecond, ',', "00:00:00,001"},
+		{"sub-millisecond rounds up", 1500 * time.Microsecond, ',', "00:00:00,002"},
+		{"rounding carries into seconds", 59*time.Second + 999600*time.Microsecond, ',', "00:01:00,000"},
+		{"rounding carries into hours", time.Hour - 400*time.Microsecond, '.', "01:00:00.000"},
+		{"negative clamped", -5 * time.Second, ',', "00:00:00,000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTimestamp(tt.d, tt.sep); got != tt.want {
+				t.Errorf("formatTimestamp(%v, %q) = %q, want %q", tt.d, tt.sep, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportSubtitlesExactOutput(t *testing.T) {
+	segments := []TranscriptSegment{
+		{Start: 0, End: 2500 * time.Millisecond, Text: " Hello there. "},
+		{Start: 2500 * time.Millisecond, End: 2900 * time.Millisecond, Text: "Short"},
+		{Start: 3 * time.Second, End: time.Hour + 5*time.Second + 120*time.Millisecond, Text: "Use <b> & -->\n\nnot a new cue"},
+		{Start: time.Hour + 6*time.Second, End: time.Hour + 8*time.Second, Text: "   "},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{SubtitleFormatSRT, "1\n" +
+			"00:00:00,000 --> 00:00:02,900\n" +
+			"Hello there. Short\n" +
+			"\n" +
+			"2\n" +
+			"00:00:03,000 --> 01:00:05,120\n" +
+			"Use <b> & -> not a new cue\n" +
+			"\n"},
+		{SubtitleFormatVTT, "WEBVTT\n" +
+			"\n" +
+			"00:00:00.000 --> 00:00:02.900\n" +
+			"Hello there. Short\n" +
+			"\n" +
+			"00:00:03.000 --> 01:00:05.120\n" +
+			"Use &lt;b&gt; &amp; --&gt; not a new cue\n" +
+			"\n"},
+		{"SRT", "1\n" +
+			"00:00:00,000 --> 00:00:02,900\n" +
+			"Hello there. Short\n" +
+			"\n" +
+			"2\n" +
+			"00:00:03,000 --> 01:00:05,120\n" +
+			"Use <b> & -> not a new cue\n" +
+			"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ExportSubtitles(segments, tt.format)
+			if err != nil {
+				t.Fatalf("ExportSubtitles: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ExportSubtitles(%q) =\n%q\nwant\n%q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportSubtitlesUnsupportedFormat(t *testing.T) {
+	if _, err := ExportSubtitles(nil, "ass"); err == nil {
+		t.Error("ExportSubtitles accepted an unsupported format")
+	}
+}
+
+func TestMergeShortSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []TranscriptSegment
+		want     []TranscriptSegment
+	}{
+		{"short first joins next", []TranscriptSegment{
+			{Start: 0, End: 500 * time.Millisecond, Text: "So"},
+			{Start: 500 * time.Millisecond, End: 3 * time.Second, Text: "let's begin"},
+		}, []TranscriptSegment{
+			{Start: 0, End: 3 * time.Second, Text: "So let's begin"},
+		}},
+		{"short later joins previous", []TranscriptSegment{
+			{Start: 0, End: 2 * time.Second, Text: "First part"},
+			{Start: 2 * time.Second, End: 2500 * time.Millisecond, Text: "ok"},
+			{Start: 3 * time.Second, End: 5 * time.Second, Text: "Second part"},
+		}, []TranscriptSegment{
+			{Start: 0, End: 2500 * time.Millisecond, Text: "First part ok"},
+			{Start: 3 * time.Second, End: 5 * time.Second, Text: "Second part"},
+		}},
+		{"only short segment kept", []TranscriptSegment{
+			{Start: 0, End: 300 * time.Millisecond, Text: "Hi"},
+		}, []TranscriptSegment{
+			{Start: 0, End: 300 * time.Millisecond, Text: "Hi"},
+		}},
+		{"empty dropped", []TranscriptSegment{
+			{Start: 0, End: 2 * time.Second, Text: " "},
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeShortSegments(tt.segments, minSubtitleDuration)
+			if len(got) != len(tt.want) {
+				t.Fatalf("mergeShortSegments = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("segment %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
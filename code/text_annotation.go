@@ -0,0 +1,158 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// textCaret is shown after a caption while it is being typed
+const textCaret = "|"
+
+// TextAnnotation is a caption typed onto the screenshot, with its top-left corner at X, Y
+type TextAnnotation struct {
+	X, Y  int
+	Text  string
+	Color color.RGBA
+	Size  int // Font size in pixels
+}
+
+// Extend implements Annotation; dragging moves the insertion point
+func (t *TextAnnotation) Extend(x, y int) {
+	t.X, t.Y = x, y
+}
+
+// Draw implements Annotation
+func (t *TextAnnotation) Draw(img *image.RGBA) {
+	drawText(img, t.X, t.Y, t.Text, t.Color, t.Size)
+}
+
+// drawText renders text with its top-left corner at x, y. Lines are separated by '\n'.
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA, size int) {
+	face := annotationFace(size)
+	metrics := face.Metrics()
+	for i, line := range strings.Split(text, "\n") {
+		drawer := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(c),
+			Face: face,
+			Dot:  fixed.P(x, y+i*metrics.Height.Ceil()+metrics.Ascent.Ceil()),
+		}
+		drawer.DrawString(line)
+	}
+}
+
+var (
+	annotationFacesMutex sync.Mutex
+	annotationFaces      = map[int]font.Face{}
+)
+
+// annotationFace returns the UI text font at size pixels, so captions can use any
+// script the UI can show. It falls back to a fixed Latin bitmap font.
+func annotationFace(size int) font.Face {
+	annotationFacesMutex.Lock()
+	defer annotationFacesMutex.Unlock()
+
+	if face, ok := annotationFaces[size]; ok {
+		return face
+	}
+
+	var face font.Face = basicfont.Face7x13
+	if parsed, err := opentype.Parse(theme.TextFont().Content()); err != nil {
+		Warnf("Failed to parse UI font for text annotations, using basic font: %v", err)
+	} else if sized, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    float64(size),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	}); err != nil {
+		Warnf("Failed to create %dpx font face, using basic font: %v", size, err)
+	} else {
+		face = sized
+	}
+	annotationFaces[size] = face
+	return face
+}
+
+// textSizeForWidth scales captions with the selected line width
+func textSizeForWidth(width int) int {
+	return 12 + 2*width
+}
+
+// startTextAnnotation finishes any caption being typed and makes t the one being edited
+func (c *imageEditorCanvas) startTextAnnotation(t *TextAnnotation) {
+	c.commitText()
+	c.editingText = t
+	Debugf("Image editor: typing caption at (%d, %d)", t.X, t.Y)
+	c.Refresh()
+}
+
+// commitText adds the caption being typed to the annotations; empty captions are dropped
+func (c *imageEditorCanvas) commitText() {
+	if c.editingText == nil {
+		return
+	}
+	if c.editingText.Text != "" {
+		c.addAnnotation(c.editingText)
+		Debugf("Image editor: caption added, total annotations: %d", len(c.annotations))
+	}
+	c.editingText = nil
+	c.Refresh()
+}
+
+// handleTextRune appends a typed character to the caption being edited
+func (c *imageEditorCanvas) handleTextRune(r rune) {
+	if c.editingText == nil {
+		return
+	}
+	c.editingText.Text += string(r)
+	c.Refresh()
+}
+
+// handleTextKey handles keys while a caption is being typed: Backspace deletes
+// the last character and Enter or Escape finish the caption. All other keys
+// are swallowed so they type text instead of triggering editor shortcuts.
+func (c *imageEditorCanvas) handleTextKey(key fyne.KeyName) bool {
+	if c.editingText == nil {
+		return false
+	}
+	switch key {
+	case fyne.KeyBackspace:
+		if text := []rune(c.editingText.Text); len(text) > 0 {
+			c.editingText.Text = string(text[:len(text)-1])
+			c.Refresh()
+		}
+	case fyne.KeyReturn, fyne.KeyEnter, fyne.KeyEscape:
+		c.commitText()
+	}
+	return true
+}
+
+// drawEditingText draws the caption being typed followed by a caret
+func (c *imageEditorCanvas) drawEditingText(img *image.RGBA) {
+	if c.editingText == nil {
+		return
+	}
+	t := c.editingText
+	drawText(img, t.X, t.Y, t.Text+textCaret, t.Color, t.Size)
+}
@@ -0,0 +1,120 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Average pace used for the time estimates, in words per minute
+const (
+	speakingWordsPerMinute = 130
+	readingWordsPerMinute  = 230
+)
+
+// TextStats summarizes the editor text for the counter under it
+type TextStats struct {
+	Words      int
+	Characters int           // Unicode characters (runes), including spaces
+	Speaking   time.Duration // Time to read the text aloud
+	Reading    time.Duration // Time to read the text silently
+}
+
+// textStats counts the words and characters of s. A word is a run of letters,
+// digits or combining marks in any script, so Cyrillic and accented text count
+// like Latin; an apostrophe or hyphen between two letters ("don't", "что-то")
+// does not split a word.
+func textStats(s string) TextStats {
+	stats := TextStats{Characters: utf8.RuneCountInString(s)}
+
+	inWord := false
+	var prev rune
+	for i, r := range s {
+		if isWordRune(r) {
+			if !inWord {
+				stats.Words++
+				inWord = true
+			}
+		} else if inWord && isWordJoiner(r) && isWordRune(prev) {
+			// Stay in the word only if a letter follows the joiner
+			next, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(r):])
+			inWord = isWordRune(next)
+		} else {
+			inWord = false
+		}
+		prev = r
+	}
+
+	stats.Speaking = wordsDuration(stats.Words, speakingWordsPerMinute)
+	stats.Reading = wordsDuration(stats.Words, readingWordsPerMinute)
+	return stats
+}
+
+// isWordRune reports whether r is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// isWordJoiner reports whether r can join two parts of one word
+func isWordJoiner(r rune) bool {
+	switch r {
+	case '\'', '’', '-', '‐':
+		return true
+	}
+	return false
+}
+
+// wordsDuration is how long words take at the given pace, rounded to seconds
+func wordsDuration(words int, perMinute int) time.Duration {
+	return (time.Duration(words) * time.Minute / time.Duration(perMinute)).Round(time.Second)
+}
+
+// formatTextStats renders stats as one line, e.g. "245 words · 1402 characters · 1:53 speaking · 1:04 reading"
+func formatTextStats(stats TextStats) string {
+	word := "words"
+	if stats.Words == 1 {
+		word = "word"
+	}
+	return fmt.Sprintf("%d %s · %d characters · %s speaking · %s reading",
+		stats.Words, word, stats.Characters, formatMinutes(stats.Speaking), formatMinutes(stats.Reading))
+}
+
+// formatMinutes formats d as minutes and seconds, e.g. "1:05"
+func formatMinutes(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// newTextStatsLabel creates the counter shown under the editor
+func (a *AppState) newTextStatsLabel() *widget.Label {
+	a.textStatsLabel = widget.NewLabel("")
+	a.textStatsLabel.Alignment = fyne.TextAlignTrailing
+	a.updateTextStats(a.correctedText.Text)
+	return a.textStatsLabel
+}
+
+// updateTextStats refreshes the counter for the editor text. It is called from
+// the editor's OnChanged, which runs on the UI goroutine.
+func (a *AppState) updateTextStats(text string) {
+	if a.textStatsLabel == nil {
+		return
+	}
+	a.textStatsLabel.SetText(formatTextStats(textStats(text)))
+}
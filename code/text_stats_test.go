@@ -0,0 +1,69 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTextStatsWords(t *testing.T) {
+	tests := []struct {
+		text  string
+		words int
+	}{
+		{"", 0},
+		{"   \n\t", 0},
+		{"Hello, world!", 2},
+		{"Привет, как дела?", 3},
+		{"Ça va très bien", 4},
+		{"don't stop", 2},
+		{"что-то случилось", 2},
+		{"well - then", 2},    // A free-standing dash is not a word
+		{"end-", 1},           // Nor is a trailing one
+		{"'quoted' words", 2}, // Quotes around a word do not join anything
+		{"version 2.5 released", 4},
+	}
+	for _, tt := range tests {
+		if got := textStats(tt.text).Words; got != tt.words {
+			t.Errorf("textStats(%q).Words = %d, want %d", tt.text, got, tt.words)
+		}
+	}
+}
+
+func TestTextStatsCountsRunes(t *testing.T) {
+	if got := textStats("Ёжик в тумане").Characters; got != 13 {
+		t.Errorf("Characters = %d, want 13 runes", got)
+	}
+}
+
+func TestFormatTextStats(t *testing.T) {
+	stats := textStats("word")
+	if got, want := formatTextStats(stats), "1 word · 4 characters · 0:00 speaking · 0:00 reading"; got != want {
+		t.Errorf("formatTextStats = %q, want %q", got, want)
+	}
+
+	stats = TextStats{Words: 245, Characters: 1402, Speaking: wordsDuration(245, speakingWordsPerMinute), Reading: wordsDuration(245, readingWordsPerMinute)}
+	if got, want := formatTextStats(stats), "245 words · 1402 characters · 1:53 speaking · 1:04 reading"; got != want {
+		t.Errorf("formatTextStats = %q, want %q", got, want)
+	}
+}
+
+func TestWordsDurationRoundsToSeconds(t *testing.T) {
+	if got := wordsDuration(130, speakingWordsPerMinute); got != time.Minute {
+		t.Errorf("130 words at 130 wpm = %v, want 1m", got)
+	}
+	if got := wordsDuration(1, readingWordsPerMinute); got != 0 {
+		t.Errorf("1 word at 230 wpm = %v, want 0s after rounding", got)
+	}
+}
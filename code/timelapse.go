@@ -0,0 +1,167 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// parseRegion parses a screen region in the form "x,y,width,height"
+func parseRegion(s string) (image.Rectangle, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("region must be x,y,width,height")
+	}
+
+	values := make([]int, 4)
+	for i, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("invalid region value %q", part)
+		}
+		values[i] = value
+	}
+
+	if values[2] <= 0 || values[3] <= 0 {
+		return image.Rectangle{}, fmt.Errorf("region width and height must be positive")
+	}
+
+	return image.Rect(values[0], values[1], values[0]+values[2], values[1]+values[3]), nil
+}
+
+// startTimeLapse captures region every interval and stores each frame as a PNG
+// until stopTimeLapse is called or the application shuts down
+func (a *AppState) startTimeLapse(region image.Rectangle, interval time.Duration) {
+	a.stopTimeLapse()
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.timeLapseCancel = cancel
+
+	Infof("Time-lapse started: region=%v, interval=%v", region, interval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		frames := 0
+		for {
+			imageData, err := a.captureRegion(region)
+			if err != nil {
+				Errorf("Time-lapse capture failed: %v", err)
+				postStatusText(a.statusLabel, fmt.Sprintf("Time-lapse capture failed: %v", err))
+			} else if filename, err := a.audioStorage.SaveScreenshot(imageData, time.Now()); err != nil {
+				Errorf("Failed to save time-lapse frame: %v", err)
+				postStatusText(a.statusLabel, fmt.Sprintf("Time-lapse save failed: %v", err))
+			} else {
+				frames++
+				Infof("Time-lapse frame %d saved as %s", frames, filename)
+				postStatusText(a.statusLabel, fmt.Sprintf("Time-lapse: %d frames captured", frames))
+			}
+
+			select {
+			case <-ctx.Done():
+				Infof("Time-lapse stopped after %d frames", frames)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopTimeLapse stops a running time-lapse capture, if any
+func (a *AppState) stopTimeLapse() {
+	if a.timeLapseCancel != nil {
+		a.timeLapseCancel()
+		a.timeLapseCancel = nil
+	}
+}
+
+// newCaptureTab builds the capture settings and the controls for running a time-lapse capture
+func (a *AppState) newCaptureTab() fyne.CanvasObject {
+	regionEntry := widget.NewEntry()
+	regionEntry.SetPlaceHolder("x,y,width,height")
+	regionEntry.SetText(a.config.TimeLapseRegion)
+
+	intervalEntry := widget.NewEntry()
+	intervalEntry.SetPlaceHolder("seconds")
+	intervalEntry.SetText(strconv.Itoa(int(a.config.TimeLapseInterval / time.Second)))
+
+	// Opt-in key event logging for diagnosing hotkeys (written only at DEBUG level)
+	keyLogCheck := widget.NewCheck("Log key events (DEBUG level)", nil)
+	keyLogCheck.SetChecked(a.config.LogKeystrokes)
+	keyLogCheck.OnChanged = func(enabled bool) {
+		a.config.LogKeystrokes = enabled
+		Infof("Keystroke logging enabled: %v", enabled)
+	}
+
+	// Screenshot capture needs a global keyboard and mouse hook, which only runs while this is on
+	captureCheck := widget.NewCheck("Screenshot capture (Ctrl+Shift+drag or capture key)", nil)
+	captureCheck.SetChecked(a.config.CaptureEnabled)
+	captureCheck.OnChanged = func(enabled bool) {
+		a.config.CaptureEnabled = enabled
+		fyne.CurrentApp().Preferences().SetBool(captureEnabledPrefKey, enabled)
+		if enabled {
+			a.startMouseHook(a.ctx)
+		} else {
+			a.stopMouseHook()
+		}
+		Infof("Screenshot capture enabled: %v", enabled)
+	}
+
+	var toggleButton *widget.Button
+	toggleButton = widget.NewButton("Start Time-lapse", func() {
+		if a.timeLapseCancel != nil {
+			a.stopTimeLapse()
+			toggleButton.SetText("Start Time-lapse")
+			setStatusText(a.statusLabel, "Time-lapse stopped")
+			return
+		}
+
+		region, err := parseRegion(regionEntry.Text)
+		if err != nil {
+			setStatusText(a.statusLabel, fmt.Sprintf("Invalid region: %v", err))
+			return
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(intervalEntry.Text))
+		if err != nil || seconds <= 0 {
+			setStatusText(a.statusLabel, "Interval must be a positive number of seconds")
+			return
+		}
+
+		a.startTimeLapse(region, time.Duration(seconds)*time.Second)
+		toggleButton.SetText("Stop Time-lapse")
+	})
+
+	return container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Target display", a.newDisplaySelect()),
+		),
+		captureCheck,
+		keyLogCheck,
+		widget.NewLabel("Time-lapse Capture"),
+		widget.NewForm(
+			widget.NewFormItem("Region", regionEntry),
+			widget.NewFormItem("Interval (s)", intervalEntry),
+		),
+		toggleButton,
+	)
+}
@@ -0,0 +1,98 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transcription backends for MICAPP_TRANSCRIBER
+const (
+	TranscriberOpenAI        = "openai"         // OpenAI's Whisper API (or OPENAI_BASE_URL)
+	TranscriberWhisperCpp    = "whisper-cpp"    // Local whisper.cpp command-line tool
+	TranscriberWhisperServer = "whisper-server" // Local OpenAI-compatible transcription server
+)
+
+// defaultWhisperServerURL is the API root of a local whisper server started with default options
+const defaultWhisperServerURL = "http://127.0.0.1:8080/v1"
+
+// localTranscriptionTimeout bounds a local transcription, which runs much slower than the API
+// on machines without a GPU
+const localTranscriptionTimeout = 10 * time.Minute
+
+// Transcriber turns recorded audio into text. AppState holds the backend selected
+// by MICAPP_TRANSCRIBER, so the rest of the app doesn't know where audio is sent.
+// filename only carries the audio format in its extension; onRequestSent is called
+// once the audio has been handed over and the result is being waited for.
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio []byte, filename string, language string, prompt string, onRequestSent ...func()) (string, error)
+	TranscribeWithSegments(ctx context.Context, audio []byte, filename string, language string, prompt string, onRequestSent ...func()) (string, []TranscriptSegment, error)
+}
+
+// newTranscriber creates the transcription backend selected in the config
+func newTranscriber(config *Config, storage *AudioStorage) (Transcriber, error) {
+	switch config.Transcriber {
+	case TranscriberWhisperCpp:
+		transcriber, err := NewWhisperCppTranscriber(config.WhisperCppBinary, config.WhisperCppModel, storage)
+		if err != nil {
+			return nil, err
+		}
+		Infof("Using whisper.cpp at %s for transcription (model %s)", transcriber.binary, transcriber.model)
+		return transcriber, nil
+	case TranscriberWhisperServer:
+		client, err := NewWhisperServerClient(config.WhisperServerURL, config.WhisperModel)
+		if err != nil {
+			return nil, err
+		}
+		Infof("Using local whisper server at %s for transcription (model %s)", client.baseURL, client.model)
+		return client, nil
+	default:
+		client, err := NewOpenAiSpeechClient(config.OpenAIBaseURL, config.WhisperModel)
+		if err != nil {
+			return nil, err
+		}
+		Infof("Using OpenAI API base URL: %s (transcription model %s)", client.baseURL, client.model)
+		return client, nil
+	}
+}
+
+// NewWhisperServerClient creates a speech client for a local server implementing
+// OpenAI's /audio/transcriptions endpoint, such as faster-whisper-server or
+// whisper.cpp's server. OPENAI_API_KEY is sent if set but not required.
+func NewWhisperServerClient(baseURL string, model string) (*OpenAiSpeechClient, error) {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = defaultWhisperServerURL
+	}
+	baseURL, err := resolveBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if model == "" {
+		model = defaultWhisperModel
+	}
+
+	return &OpenAiSpeechClient{
+		apiKey:  os.Getenv("OPENAI_API_KEY"),
+		baseURL: baseURL,
+		model:   model,
+		client: &http.Client{
+			Timeout: localTranscriptionTimeout,
+		},
+	}, nil
+}
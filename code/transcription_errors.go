@@ -0,0 +1,70 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// maxErrorStatusLength keeps error details short enough for the status line
+const maxErrorStatusLength = 120
+
+// transcriptionFailureStatus returns the status line for a failed transcription,
+// naming the cause in terms the user can act on: a bad API key, a rate limit,
+// a network problem or the API's own message
+func transcriptionFailureStatus(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return "Transcription canceled"
+	}
+	return "Transcription failed: " + transcriptionFailureReason(err)
+}
+
+// transcriptionFailureReason describes why a transcription request failed
+func transcriptionFailureReason(err error) string {
+	var apiErr *APIStatusError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errAudioTooLarge):
+		return "recording too large"
+	case errors.Is(err, errInvalidAPIKey):
+		return "check API key"
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized:
+		return "check API key"
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+		return "rate limited, retry later"
+	case errors.As(err, &apiErr):
+		return shortenStatus(apiErr.Message)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "request timed out, check your connection"
+	case errors.As(err, &netErr):
+		return shortenStatus(fmt.Sprintf("network error (%v)", netErr))
+	case errors.Is(err, errWhisperCppFailed):
+		return "whisper.cpp error, see app.log"
+	default:
+		return shortenStatus(err.Error())
+	}
+}
+
+// shortenStatus cuts text to maxErrorStatusLength characters
+func shortenStatus(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxErrorStatusLength {
+		return text
+	}
+	return string(runes[:maxErrorStatusLength-1]) + "…"
+}
@@ -0,0 +1,102 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+// transcriptionQueueSize is how many recordings can wait for the worker before
+// addToQueue blocks its caller (never the UI thread)
+const transcriptionQueueSize = 32
+
+// queueEntry is one pending transcription shown as a queue indicator
+type queueEntry struct {
+	id   uint64
+	mode string // "start" or "add"
+}
+
+// enqueueTranscription records a pending transcription and returns its id,
+// which dequeueTranscription needs to remove exactly this entry
+func (a *AppState) enqueueTranscription(mode string) uint64 {
+	a.queueMutex.Lock()
+	a.nextQueueID++
+	id := a.nextQueueID
+	a.transcriptionQueue = append(a.transcriptionQueue, queueEntry{id: id, mode: mode})
+	a.queueMutex.Unlock()
+
+	a.updateQueueIndicators()
+	return id
+}
+
+// dequeueTranscription removes the entry with the given id. Jobs finish in any
+// order (a short clip can overtake a long one), so the entry is looked up
+// rather than assumed to be first.
+func (a *AppState) dequeueTranscription(id uint64) {
+	a.queueMutex.Lock()
+	for i, entry := range a.transcriptionQueue {
+		if entry.id == id {
+			a.transcriptionQueue = append(a.transcriptionQueue[:i], a.transcriptionQueue[i+1:]...)
+			break
+		}
+	}
+	a.queueMutex.Unlock()
+
+	a.updateQueueIndicators()
+}
+
+// runTranscriptionWorker processes queued jobs one at a time, in the order they
+// were recorded, until the application shuts down. One worker keeps uploads from
+// piling into rate limits and keeps results landing in the editor in order.
+func (a *AppState) runTranscriptionWorker() {
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case job := <-a.jobQueue:
+			a.processQueueItem(job)
+		}
+	}
+}
+
+// submitJob hands a queued job to the worker, giving up if the application is shutting down
+func (a *AppState) submitJob(job transcriptionJob) {
+	select {
+	case a.jobQueue <- job:
+	case <-a.ctx.Done():
+		a.dequeueTranscription(job.queueID)
+	}
+}
+
+// discardQueuedJobs drops every job still waiting for the worker, so that
+// canceling stops the whole queue and not just the item in progress
+func (a *AppState) discardQueuedJobs() {
+	for {
+		select {
+		case job := <-a.jobQueue:
+			Infof("Discarding queued %q transcription", job.mode)
+			a.dequeueTranscription(job.queueID)
+			if job.caption != nil {
+				job.caption("")
+			} else if job.mode == "add" {
+				a.unreserveAddSpace()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// queueLength returns the number of pending transcriptions
+func (a *AppState) queueLength() int {
+	a.queueMutex.Lock()
+	defer a.queueMutex.Unlock()
+	return len(a.transcriptionQueue)
+}
@@ -0,0 +1,180 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDequeueTranscriptionRemovesMatchingEntry(t *testing.T) {
+	a := newTestAppState(context.Background())
+	first := a.enqueueTranscription("start")
+	second := a.enqueueTranscription("add")
+	third := a.enqueueTranscription("start")
+
+	// The second job finishes first, e.g. a short clip overtaking a long one
+	a.dequeueTranscription(second)
+	a.dequeueTranscription(second) // Already gone: must not remove another entry
+
+	a.queueMutex.Lock()
+	got := append([]queueEntry(nil), a.transcriptionQueue...)
+	a.queueMutex.Unlock()
+	want := []queueEntry{{id: first, mode: "start"}, {id: third, mode: "start"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("queue = %+v, want %+v", got, want)
+	}
+}
+
+func TestTranscriptionWorkerStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a := newTestAppState(ctx)
+
+	worker := make(chan struct{})
+	go func() {
+		a.runTranscriptionWorker()
+		close(worker)
+	}()
+	select {
+	case <-worker:
+		t.Fatal("runTranscriptionWorker returned before the context was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	waitDone(t, "runTranscriptionWorker", worker)
+
+	// Nobody receives from the queue anymore, so a submit must give up
+	submitted := make(chan struct{})
+	go func() {
+		a.submitJob(transcriptionJob{queueID: a.enqueueTranscription("start")})
+		close(submitted)
+	}()
+	waitDone(t, "submitJob", submitted)
+	if n := a.queueLength(); n != 0 {
+		t.Errorf("queue length after a refused submit = %d, want 0", n)
+	}
+}
+
+// TestTranscriptionQueueConcurrentAccess enqueues and dequeues from many
+// goroutines at once while others read the queue length. Run with -race.
+func TestTranscriptionQueueConcurrentAccess(t *testing.T) {
+	a := newTestAppState(context.Background())
+	const goroutines = 50
+	const perGoroutine = 20
+
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					if n := a.queueLength(); n < 0 || n > goroutines*perGoroutine {
+						t.Errorf("queueLength() = %d", n)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			ids := make([]uint64, 0, perGoroutine)
+			for i := 0; i < perGoroutine; i++ {
+				ids = append(ids, a.enqueueTranscription("start"))
+			}
+			mu.Lock()
+			for _, id := range ids {
+				if seen[id] {
+					t.Errorf("queue id %d was handed out twice", id)
+				}
+				seen[id] = true
+			}
+			mu.Unlock()
+			// Finish in reverse order, so entries are rarely at the front
+			for i := len(ids) - 1; i >= 0; i-- {
+				a.dequeueTranscription(ids[i])
+			}
+		}()
+	}
+	writers.Wait()
+	close(stop)
+	readers.Wait()
+
+	if n := a.queueLength(); n != 0 {
+		t.Errorf("queue length after every job finished = %d, want 0", n)
+	}
+	if len(seen) != goroutines*perGoroutine {
+		t.Errorf("got %d distinct queue ids, want %d", len(seen), goroutines*perGoroutine)
+	}
+}
+
+// TestAddToQueueStress submits many recordings at once to a running worker and
+// checks each one is transcribed once and leaves the queue
+func TestAddToQueueStress(t *testing.T) {
+	installFakeFFmpeg(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a := newTestAppState(ctx)
+	a.audioStorage = &AudioStorage{baseDir: t.TempDir()}
+	a.usage = newUsageTracker(a.config, nil)
+	transcriber := &fakeWhisper{} // No speech, so nothing touches the editor
+	a.setClients(transcriber.client(), nil)
+	go a.runTranscriptionWorker()
+
+	const jobs = 40
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.addToQueue(transcriptionJob{audioData: make([]byte, recordingSampleRate/5), sampleRate: recordingSampleRate, mode: "start"})
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		transcriber.mu.Lock()
+		uploads := len(transcriber.uploads)
+		transcriber.mu.Unlock()
+		if uploads == jobs && a.queueLength() == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("after 10s: %d of %d jobs transcribed, %d still queued", uploads, jobs, a.queueLength())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Nothing more may arrive once the queue has drained
+	time.Sleep(50 * time.Millisecond)
+	transcriber.mu.Lock()
+	defer transcriber.mu.Unlock()
+	if len(transcriber.uploads) != jobs {
+		t.Errorf("got %d uploads, want %d", len(transcriber.uploads), jobs)
+	}
+}
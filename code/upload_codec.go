@@ -0,0 +1,46 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+// Upload codecs for MICAPP_UPLOAD_CODEC
+const (
+	UploadCodecMP3  = "mp3"
+	UploadCodecOpus = "opus"
+)
+
+// Upload bitrates in kbps; Opus needs far less than MP3 for the same speech quality
+const (
+	mp3UploadBitrate  = 128
+	opusUploadBitrate = 32
+)
+
+// encodeForUpload compresses PCM audio with the configured codec and returns the data
+// with a filename whose extension matches it, which Whisper uses to detect the format.
+// Opus falls back to MP3, and MP3 falls back to uncompressed WAV.
+func (a *AppState) encodeForUpload(pcmData []byte, sampleRate uint32) ([]byte, string) {
+	if a.config.UploadCodec == UploadCodecOpus {
+		data, err := a.audioStorage.ConvertToOpus(pcmData, sampleRate, opusUploadBitrate)
+		if err == nil {
+			return data, "recording.ogg"
+		}
+		Warnf("Failed to convert to Opus, falling back to MP3: %v", err)
+	}
+
+	data, err := a.audioStorage.ConvertToMP3(pcmData, sampleRate, mp3UploadBitrate)
+	if err == nil {
+		return data, "recording.mp3"
+	}
+	Warnf("Failed to convert to MP3, falling back to WAV: %v", err)
+	return CreateWAVFile(pcmData, sampleRate, 1), "recording.wav"
+}
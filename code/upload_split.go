@@ -0,0 +1,58 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// whisperUploadLimit is the largest file the OpenAI transcription API accepts
+const whisperUploadLimit = 25 << 20
+
+// uploadLimitError reports encoded audio over whisperUploadLimit, caught before
+// it is sent. It matches errAudioTooLarge with errors.Is.
+type uploadLimitError struct {
+	Size int // Encoded size in bytes
+}
+
+func (e *uploadLimitError) Error() string {
+	return fmt.Sprintf("encoded audio is %d bytes, over the %d byte upload limit", e.Size, whisperUploadLimit)
+}
+
+func (e *uploadLimitError) Unwrap() error {
+	return errAudioTooLarge
+}
+
+// pcmToSamples converts little-endian 16-bit PCM bytes back to samples
+func pcmToSamples(pcm []byte) []int16 {
+	samples := make([]int16, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[i*2:]))
+	}
+	return samples
+}
+
+// uploadSegments splits audio that encoded to size bytes into parts that each
+// stay well under the upload limit. Cuts are made at pauses where possible and
+// fall back to fixed-length parts that overlap by chunkOverlap.
+func uploadSegments(pcmData []byte, sampleRate uint32, size int) []audioSegment {
+	samples := pcmToSamples(pcmData)
+	// splitSegments merges a short tail into the last part, which can then be
+	// half as long again, so aim for 60% of the limit
+	chunkSamples := int(int64(len(samples)) * whisperUploadLimit * 6 / 10 / int64(size))
+	overlapSamples := int(time.Duration(sampleRate) * chunkOverlap / time.Second)
+	return splitSegments(samples, int(sampleRate), chunkSamples, overlapSamples)
+}
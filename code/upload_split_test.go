@@ -0,0 +1,65 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestUploadLimitErrorIsTooLarge(t *testing.T) {
+	err := fmt.Errorf("transcribe: %w", &uploadLimitError{Size: whisperUploadLimit + 1})
+	if !errors.Is(err, errAudioTooLarge) {
+		t.Error("uploadLimitError does not match errAudioTooLarge")
+	}
+}
+
+func TestPCMToSamplesRoundTrip(t *testing.T) {
+	samples := []int16{0, 1, -1, math.MaxInt16, math.MinInt16, 12345}
+	got := pcmToSamples(samplesToPCM(samples))
+	if fmt.Sprint(got) != fmt.Sprint(samples) {
+		t.Errorf("round trip = %v, want %v", got, samples)
+	}
+}
+
+func TestUploadSegmentsStayUnderLimit(t *testing.T) {
+	const sampleRate = 8000
+	// Ten minutes of tone with no pauses, so cuts fall back to fixed lengths
+	samples := make([]int16, 10*60*sampleRate)
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(float64(i)*2*math.Pi*440/sampleRate))
+	}
+
+	for _, factor := range []float64{1.2, 2.5, 4} {
+		size := int(factor * whisperUploadLimit)
+		segments := uploadSegments(samplesToPCM(samples), sampleRate, size)
+		if len(segments) < 2 {
+			t.Fatalf("%v times the limit: got %d parts, want a split", factor, len(segments))
+		}
+		if segments[0].Start != 0 || segments[len(segments)-1].End != len(samples) {
+			t.Errorf("%v times the limit: parts cover %d-%d, want the whole recording", factor, segments[0].Start, segments[len(segments)-1].End)
+		}
+		for i, segment := range segments {
+			// Encoded size scales with duration
+			partSize := float64(size) * float64(segment.End-segment.Start) / float64(len(samples))
+			if partSize > whisperUploadLimit {
+				t.Errorf("%v times the limit: part %d would encode to %.0f bytes", factor, i, partSize)
+			}
+			if i > 0 && segment.Start > segments[i-1].End {
+				t.Errorf("%v times the limit: gap between parts %d and %d", factor, i-1, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Preferences keys of the persisted daily spend
+const (
+	usageDayPrefKey  = "usageDay"
+	usageCostPrefKey = "usageDayCost"
+)
+
+// transcriptionPrices are OpenAI's transcription prices in USD per audio minute
+var transcriptionPrices = map[string]float64{
+	"whisper-1":              0.006,
+	"gpt-4o-transcribe":      0.006,
+	"gpt-4o-mini-transcribe": 0.003,
+}
+
+// chatPrice is a chat model's price in USD per million prompt and completion tokens
+type chatPrice struct {
+	prompt     float64
+	completion float64
+}
+
+// chatPrices are OpenAI's chat prices by model name prefix; a dated model such as
+// gpt-4o-mini-2024-07-18 uses the longest matching prefix
+var chatPrices = map[string]chatPrice{
+	"gpt-4o-mini":  {0.15, 0.60},
+	"gpt-4o":       {2.50, 10.00},
+	"gpt-4.1-nano": {0.10, 0.40},
+	"gpt-4.1-mini": {0.40, 1.60},
+	"gpt-4.1":      {2.00, 8.00},
+}
+
+// UsageTracker adds up the audio transcribed and tokens used by the API calls of
+// this session and estimates their cost. The day's cost is kept in preferences
+// so it survives restarts. Models without a known price (local backends) cost 0.
+type UsageTracker struct {
+	mu    sync.Mutex
+	prefs fyne.Preferences
+	label *widget.Label
+
+	config *Config // Transcription backend and model, which the Settings tab can change
+
+	audio            time.Duration
+	promptTokens     int
+	completionTokens int
+	sessionCost      float64
+}
+
+// newUsageTracker creates a tracker pricing transcriptions with the configured model
+func newUsageTracker(config *Config, prefs fyne.Preferences) *UsageTracker {
+	return &UsageTracker{prefs: prefs, config: config}
+}
+
+// newUsageLabel returns the label showing the running session tally
+func (u *UsageTracker) newUsageLabel() *widget.Label {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.label = widget.NewLabel(u.summaryLocked())
+	u.label.Alignment = fyne.TextAlignCenter
+	return u.label
+}
+
+// addTranscription records a transcription of duration worth of audio
+func (u *UsageTracker) addTranscription(duration time.Duration) {
+	cost := 0.0
+	if u.config.Transcriber == TranscriberOpenAI {
+		cost = transcriptionPrices[u.config.WhisperModel] * duration.Minutes()
+	}
+	Debugf("Transcription usage: %v of audio, ~$%.4f", duration.Round(time.Second), cost)
+
+	u.mu.Lock()
+	u.audio += duration
+	u.addCostLocked(cost)
+	summary, label := u.summaryLocked(), u.label
+	u.mu.Unlock()
+
+	if label != nil {
+		fyne.Do(func() { label.SetText(summary) })
+	}
+}
+
+// addCompletion records the tokens a chat completion used
+func (u *UsageTracker) addCompletion(model string, usage Usage) {
+	price := chatModelPrice(model)
+	cost := (float64(usage.PromptTokens)*price.prompt + float64(usage.CompletionTokens)*price.completion) / 1e6
+	Debugf("Completion usage: %s, %d prompt + %d completion tokens, ~$%.4f", model, usage.PromptTokens, usage.CompletionTokens, cost)
+
+	u.mu.Lock()
+	u.promptTokens += usage.PromptTokens
+	u.completionTokens += usage.CompletionTokens
+	u.addCostLocked(cost)
+	summary, label := u.summaryLocked(), u.label
+	u.mu.Unlock()
+
+	if label != nil {
+		fyne.Do(func() { label.SetText(summary) })
+	}
+}
+
+// addCostLocked adds cost to the session and to today's persisted total,
+// starting a new total on a new day; u.mu must be held
+func (u *UsageTracker) addCostLocked(cost float64) {
+	u.sessionCost += cost
+	if u.prefs == nil {
+		return
+	}
+	u.prefs.SetFloat(usageCostPrefKey, u.todayCostLocked()+cost)
+	u.prefs.SetString(usageDayPrefKey, time.Now().Format("2006-01-02"))
+}
+
+// todayCostLocked returns the estimated spend of today across sessions; u.mu must be held
+func (u *UsageTracker) todayCostLocked() float64 {
+	if u.prefs == nil || u.prefs.String(usageDayPrefKey) != time.Now().Format("2006-01-02") {
+		return 0
+	}
+	return u.prefs.Float(usageCostPrefKey)
+}
+
+// summaryLocked describes the session usage and today's spend; u.mu must be held
+func (u *UsageTracker) summaryLocked() string {
+	return fmt.Sprintf("Session: %.1f min audio, %d tokens, ~$%.2f · today ~$%.2f",
+		u.audio.Minutes(), u.promptTokens+u.completionTokens, u.sessionCost, u.todayCostLocked())
+}
+
+// chatModelPrice returns the price of the longest known prefix of model, zero if none matches
+func chatModelPrice(model string) chatPrice {
+	var price chatPrice
+	matched := 0
+	for prefix, p := range chatPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > matched {
+			price, matched = p, len(prefix)
+		}
+	}
+	return price
+}
+
+// pcmDuration returns how long 16-bit mono PCM audio at sampleRate lasts
+func pcmDuration(pcmData []byte, sampleRate uint32) time.Duration {
+	if sampleRate == 0 {
+		return 0
+	}
+	return time.Duration(len(pcmData)/2) * time.Second / time.Duration(sampleRate)
+}
+
+// storedAudioDuration returns the duration of a stored recording, 0 if it is unknown
+func (a *AppState) storedAudioDuration(filename string) time.Duration {
+	files, err := a.audioStorage.GetStoredAudioFiles()
+	if err != nil {
+		return 0
+	}
+	for _, file := range files {
+		if file.Filename == filename {
+			return file.Duration
+		}
+	}
+	return 0
+}
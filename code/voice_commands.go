@@ -0,0 +1,302 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Actions of a VoiceCommand
+const (
+	voiceActionInsert         = "insert"          // Put the command's text in place of the phrase
+	voiceActionDeleteSentence = "delete-sentence" // Remove the sentence dictated before the phrase
+	voiceActionOff            = "off"             // Disable a built-in command (in the commands file)
+)
+
+// VoiceCommand is a phrase that is replaced by an action when it is dictated
+type VoiceCommand struct {
+	Phrase string `json:"phrase"`
+	Action string `json:"action,omitempty"` // voiceActionInsert (default), voiceActionDeleteSentence or voiceActionOff
+	Text   string `json:"text,omitempty"`   // Inserted text of voiceActionInsert
+}
+
+// VoiceCommandSet is the command vocabulary of one language. Saying the escape
+// word before a command ("literal comma") writes the command's words instead.
+type VoiceCommandSet struct {
+	Escape   string         `json:"escape,omitempty"`
+	Commands []VoiceCommand `json:"commands"`
+}
+
+// defaultVoiceCommands is the built-in vocabulary by language code
+var defaultVoiceCommands = map[string]VoiceCommandSet{
+	"en": {
+		Escape: "literal",
+		Commands: []VoiceCommand{
+			{Phrase: "new line", Text: "\n"},
+			{Phrase: "new paragraph", Text: "\n\n"},
+			{Phrase: "comma", Text: ","},
+			{Phrase: "period", Text: "."},
+			{Phrase: "full stop", Text: "."},
+			{Phrase: "question mark", Text: "?"},
+			{Phrase: "exclamation mark", Text: "!"},
+			{Phrase: "colon", Text: ":"},
+			{Phrase: "scratch that", Action: voiceActionDeleteSentence},
+			{Phrase: "delete that", Action: voiceActionDeleteSentence},
+		},
+	},
+	"ru": {
+		Escape: "буквально",
+		Commands: []VoiceCommand{
+			{Phrase: "новая строка", Text: "\n"},
+			{Phrase: "новый абзац", Text: "\n\n"},
+			{Phrase: "запятая", Text: ","},
+			{Phrase: "вопросительный знак", Text: "?"},
+			{Phrase: "восклицательный знак", Text: "!"},
+			{Phrase: "двоеточие", Text: ":"},
+			{Phrase: "удалить последнее", Action: voiceActionDeleteSentence},
+			{Phrase: "удали последнее", Action: voiceActionDeleteSentence},
+		},
+	},
+}
+
+// voiceCommands is the vocabulary in use: the defaults, extended by
+// MICAPP_VOICE_COMMANDS_FILE at startup
+var voiceCommands = defaultVoiceCommands
+
+// loadVoiceCommands reads a JSON file of command sets by language code, e.g.
+// {"en": {"commands": [{"phrase": "smiley", "text": ":)"}]}}, and merges it into
+// the defaults: a phrase already known is replaced, a new one is added and
+// "action": "off" removes it.
+func loadVoiceCommands(path string) (map[string]VoiceCommandSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file map[string]VoiceCommandSet
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid voice commands file %s: %w", path, err)
+	}
+
+	merged := make(map[string]VoiceCommandSet, len(defaultVoiceCommands))
+	for lang, set := range defaultVoiceCommands {
+		merged[lang] = VoiceCommandSet{Escape: set.Escape, Commands: append([]VoiceCommand(nil), set.Commands...)}
+	}
+	for lang, set := range file {
+		current := merged[lang]
+		if set.Escape != "" {
+			current.Escape = set.Escape
+		}
+		for _, command := range set.Commands {
+			switch command.Action {
+			case "", voiceActionInsert, voiceActionDeleteSentence, voiceActionOff:
+			default:
+				return nil, fmt.Errorf("invalid voice commands file %s: unknown action %q for %q", path, command.Action, command.Phrase)
+			}
+			if len(strings.Fields(command.Phrase)) == 0 {
+				return nil, fmt.Errorf("invalid voice commands file %s: empty phrase", path)
+			}
+			current.Commands = slices.DeleteFunc(current.Commands, func(known VoiceCommand) bool {
+				return strings.EqualFold(known.Phrase, command.Phrase)
+			})
+			if command.Action != voiceActionOff {
+				current.Commands = append(current.Commands, command)
+			}
+		}
+		merged[lang] = current
+	}
+	return merged, nil
+}
+
+// voicePhrase is a command prepared for matching
+type voicePhrase struct {
+	words   []string
+	command VoiceCommand
+	escape  string
+}
+
+// voicePhrasesFor returns the commands of lang, or of every language when lang
+// is not known (e.g. "auto"), longest phrase first
+func voicePhrasesFor(lang string) []voicePhrase {
+	_, known := voiceCommands[lang]
+	var phrases []voicePhrase
+	for code, set := range voiceCommands {
+		if known && code != lang {
+			continue
+		}
+		for _, command := range set.Commands {
+			phrases = append(phrases, voicePhrase{words: strings.Fields(command.Phrase), command: command, escape: set.Escape})
+		}
+	}
+	sort.SliceStable(phrases, func(i, j int) bool { return len(phrases[i].words) > len(phrases[j].words) })
+	return phrases
+}
+
+// textToken is a word or the text between two words
+type textToken struct {
+	text string
+	word bool
+}
+
+// tokenizeWords splits text into alternating words and separators. Words are
+// letter runs as counted by textStats, so "comma-separated" is a single word.
+func tokenizeWords(text string) []textToken {
+	var tokens []textToken
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		if start < i {
+			tokens = append(tokens, textToken{text: string(runes[start:i])})
+		}
+		end := i
+		for end < len(runes) && (isWordRune(runes[end]) ||
+			(isWordJoiner(runes[end]) && end+1 < len(runes) && isWordRune(runes[end+1]))) {
+			end++
+		}
+		tokens = append(tokens, textToken{text: string(runes[i:end]), word: true})
+		start, i = end, end
+	}
+	if start < len(runes) {
+		tokens = append(tokens, textToken{text: string(runes[start:])})
+	}
+	return tokens
+}
+
+// applyVoiceCommands carries out the spoken commands in a transcription of the
+// given language: "new line" becomes a line break, "comma" a comma, "scratch
+// that" removes the sentence dictated before it, and so on. Commands work in
+// the middle of a sentence; the punctuation the transcriber put around them is
+// dropped. Preceded by the escape word ("literal comma") a command is written
+// out as words.
+func applyVoiceCommands(text string, lang string) string {
+	phrases := voicePhrasesFor(lang)
+	tokens := tokenizeWords(text)
+
+	var out []rune
+	capitalize := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if !token.word {
+			out = append(out, []rune(token.text)...)
+			continue
+		}
+
+		// "literal comma": write the command's words without the escape word
+		if phrase, end, ok := matchVoicePhrase(tokens, i+2, phrases); ok && i+1 < len(tokens) &&
+			strings.TrimSpace(tokens[i+1].text) == "" && strings.EqualFold(token.text, phrase.escape) {
+			for _, literal := range tokens[i+2 : end] {
+				out = append(out, []rune(literal.text)...)
+			}
+			i = end - 1
+			continue
+		}
+
+		phrase, end, ok := matchVoicePhrase(tokens, i, phrases)
+		if !ok {
+			if capitalize {
+				token.text = capitalizeFirst(token.text)
+				capitalize = false
+			}
+			out = append(out, []rune(token.text)...)
+			continue
+		}
+
+		if phrase.command.Action == voiceActionDeleteSentence {
+			out = deleteLastSentence(out)
+		} else {
+			inserted := phrase.command.Text
+			if strings.Contains(inserted, "\n") {
+				out = trimRunesRight(out, " \t,;:")
+			} else {
+				out = trimRunesRight(out, " \t,;:.")
+			}
+			out = append(out, []rune(inserted)...)
+		}
+
+		// Drop the punctuation the transcriber put after the command
+		if end < len(tokens) && !tokens[end].word && strings.Trim(tokens[end].text, " \t,.;:!?…") == "" {
+			end++
+		}
+		if len(out) > 0 && end < len(tokens) && !strings.HasSuffix(string(out), "\n") {
+			out = append(out, ' ')
+		}
+		trimmed := trimRunesRight(out, " ")
+		capitalize = len(trimmed) == 0 || strings.ContainsRune(".!?…\n", trimmed[len(trimmed)-1])
+		i = end - 1
+	}
+	return strings.Trim(string(out), " \t")
+}
+
+// matchVoicePhrase returns the command whose words start at tokens[i], and the
+// index after its last word. Only spaces and punctuation may separate the words.
+func matchVoicePhrase(tokens []textToken, i int, phrases []voicePhrase) (voicePhrase, int, bool) {
+	for _, phrase := range phrases {
+		t := i
+		matched := true
+		for w, word := range phrase.words {
+			if w > 0 {
+				if t >= len(tokens) || tokens[t].word || strings.ContainsRune(tokens[t].text, '\n') {
+					matched = false
+					break
+				}
+				t++
+			}
+			if t >= len(tokens) || !tokens[t].word || !strings.EqualFold(tokens[t].text, word) {
+				matched = false
+				break
+			}
+			t++
+		}
+		if matched {
+			return phrase, t, true
+		}
+	}
+	return voicePhrase{}, 0, false
+}
+
+// deleteLastSentence removes the last sentence from text, up to the previous
+// sentence end or line break
+func deleteLastSentence(text []rune) []rune {
+	text = trimRunesRight(text, " \t,;:.!?…")
+	for i := len(text) - 1; i >= 0; i-- {
+		if strings.ContainsRune(".!?…\n", text[i]) {
+			return text[:i+1]
+		}
+	}
+	return text[:0]
+}
+
+// trimRunesRight removes trailing runes contained in cutset
+func trimRunesRight(text []rune, cutset string) []rune {
+	for len(text) > 0 && strings.ContainsRune(cutset, text[len(text)-1]) {
+		text = text[:len(text)-1]
+	}
+	return text
+}
+
+// capitalizeFirst upper-cases the first letter of word
+func capitalizeFirst(word string) string {
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
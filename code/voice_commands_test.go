@@ -0,0 +1,105 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyVoiceCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		lang string
+		want string
+	}{
+		{"no commands", "Just some text.", "en", "Just some text."},
+		{"punctuation", "Hello comma world period", "en", "Hello, world."},
+		{"transcriber punctuation dropped", "Hello, comma, world. Period.", "en", "Hello, world."},
+		{"capitalize after sentence end", "Is it question mark yes it is period", "en", "Is it? Yes it is."},
+		{"new line", "First line. New line. Second line.", "en", "First line.\nSecond line."},
+		{"new paragraph", "First part new paragraph second part", "en", "First part\n\nSecond part"},
+		{"scratch that", "Keep this. Drop this. Scratch that. Go on.", "en", "Keep this. Go on."},
+		{"scratch everything", "Drop this, scratch that", "en", ""},
+		{"literal", "Write literal comma here", "en", "Write comma here"},
+		{"whole words only", "Commas and periods", "en", "Commas and periods"},
+		{"russian", "Привет запятая мир вопросительный знак", "ru", "Привет, мир?"},
+		{"russian delete", "Первое. Второе. Удалить последнее.", "ru", "Первое."},
+		{"other language not used", "Привет запятая мир", "en", "Привет запятая мир"},
+		{"auto uses every language", "Hello comma мир запятая да", "auto", "Hello, мир, да"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyVoiceCommands(tt.text, tt.lang); got != tt.want {
+				t.Errorf("applyVoiceCommands(%q, %q) = %q, want %q", tt.text, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadVoiceCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commands.json")
+	file := `{
+		"en": {"commands": [
+			{"phrase": "smiley", "text": ":)"},
+			{"phrase": "Comma", "text": ";"},
+			{"phrase": "scratch that", "action": "off"}
+		]},
+		"de": {"escape": "wörtlich", "commands": [{"phrase": "neue Zeile", "text": "\n"}]}
+	}`
+	if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := loadVoiceCommands(path)
+	if err != nil {
+		t.Fatalf("loadVoiceCommands: %v", err)
+	}
+
+	texts := make(map[string]string)
+	for _, command := range merged["en"].Commands {
+		texts[command.Phrase] = command.Text
+	}
+	if texts["smiley"] != ":)" {
+		t.Error("new command was not added")
+	}
+	if _, ok := texts["comma"]; ok || texts["Comma"] != ";" {
+		t.Errorf("known command was not replaced: %v", texts)
+	}
+	if _, ok := texts["scratch that"]; ok {
+		t.Error("command turned off is still present")
+	}
+	if merged["de"].Escape != "wörtlich" || len(merged["de"].Commands) != 1 {
+		t.Errorf("new language = %+v", merged["de"])
+	}
+	if len(defaultVoiceCommands["en"].Commands) != 10 {
+		t.Error("loading a file changed the built-in commands")
+	}
+}
+
+func TestLoadVoiceCommandsRejectsInvalidFiles(t *testing.T) {
+	for name, file := range map[string]string{
+		"not json":       `{`,
+		"unknown action": `{"en": {"commands": [{"phrase": "x", "action": "explode"}]}}`,
+		"empty phrase":   `{"en": {"commands": [{"phrase": "  ", "text": "x"}]}}`,
+	} {
+		path := filepath.Join(t.TempDir(), "commands.json")
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadVoiceCommands(path); err == nil {
+			t.Errorf("%s: loadVoiceCommands succeeded", name)
+		}
+	}
+}
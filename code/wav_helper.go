@@ -16,6 +16,9 @@ package main
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
 )
 
 // WAVHeader represents the structure of a WAV file header
@@ -35,6 +38,17 @@ type WAVHeader struct {
 	DataSize      uint32  // Data size
 }
 
+// WAV AudioFormat codes written by CreateWAVFileBits
+const (
+	wavFormatPCM       = 1 // Integer PCM
+	wavFormatIEEEFloat = 3 // 32-bit IEEE float
+)
+
+// wavFormatExtensible is the AudioFormat of WAVE_FORMAT_EXTENSIBLE files, which
+// keep the real format code in the first two bytes of the SubFormat GUID.
+// ffmpeg writes it for more than 16 bits per sample or rates above 48 kHz.
+const wavFormatExtensible = 0xFFFE
+
 // CreateWAVFile creates a WAV file from raw PCM audio data
 // Parameters:
 //   - pcmData: Raw PCM audio data (16-bit samples)
@@ -44,11 +58,39 @@ type WAVHeader struct {
 // Returns:
 //   - []byte: Complete WAV file as byte slice
 func CreateWAVFile(pcmData []byte, sampleRate uint32, numChannels uint16) []byte {
+	return buildWAV(pcmData, sampleRate, numChannels, 16, wavFormatPCM)
+}
+
+// CreateWAVFileBits creates a WAV file from raw audio data at the given bit depth.
+// 16 and 24 bits are written as integer PCM, 32 bits as IEEE float (AudioFormat 3).
+// The data length must be a whole number of frames (channels * bitsPerSample / 8 bytes).
+func CreateWAVFileBits(pcm []byte, sampleRate uint32, channels, bitsPerSample uint16) ([]byte, error) {
+	var audioFormat uint16
+	switch bitsPerSample {
+	case 16, 24:
+		audioFormat = wavFormatPCM
+	case 32:
+		audioFormat = wavFormatIEEEFloat
+	default:
+		return nil, fmt.Errorf("unsupported bits per sample: %d (want 16, 24 or 32)", bitsPerSample)
+	}
+	if channels == 0 {
+		return nil, fmt.Errorf("channel count must be at least 1")
+	}
+
+	blockAlign := int(channels) * int(bitsPerSample) / 8
+	if len(pcm)%blockAlign != 0 {
+		return nil, fmt.Errorf("data length %d is not a multiple of block align %d", len(pcm), blockAlign)
+	}
+	return buildWAV(pcm, sampleRate, channels, bitsPerSample, audioFormat), nil
+}
+
+// buildWAV writes a canonical 44-byte WAV header followed by the audio data
+func buildWAV(pcmData []byte, sampleRate uint32, numChannels uint16, bitsPerSample uint16, audioFormat uint16) []byte {
 	dataSize := uint32(len(pcmData))
 	fileSize := uint32(36 + dataSize) // 36 bytes for header + data size
 
 	// Calculate derived values
-	bitsPerSample := uint16(16) // 16-bit samples
 	byteRate := sampleRate * uint32(numChannels) * uint32(bitsPerSample) / 8
 	blockAlign := numChannels * bitsPerSample / 8
 
@@ -59,7 +101,7 @@ func CreateWAVFile(pcmData []byte, sampleRate uint32, numChannels uint16) []byte
 		WaveHeader:    [4]byte{'W', 'A', 'V', 'E'},
 		FmtHeader:     [4]byte{'f', 'm', 't', ' '},
 		FmtChunkSize:  16, // Standard PCM format chunk size
-		AudioFormat:   1,  // PCM format
+		AudioFormat:   audioFormat,
 		NumChannels:   numChannels,
 		SampleRate:    sampleRate,
 		ByteRate:      byteRate,
@@ -78,3 +120,125 @@ func CreateWAVFile(pcmData []byte, sampleRate uint32, numChannels uint16) []byte
 
 	return buf.Bytes()
 }
+
+// ParseWAV reads a WAV file and returns its format and the audio payload of its data chunk.
+// Chunks other than fmt and data (LIST, fact, ...) are skipped. The returned header
+// describes the file as if it had been written by CreateWAVFile; for an extensible
+// fmt chunk AudioFormat is the code from its SubFormat.
+func ParseWAV(data []byte) (WAVHeader, []byte, error) {
+	var header WAVHeader
+	if len(data) < 12 {
+		return header, nil, fmt.Errorf("file too short for a RIFF header: %d bytes", len(data))
+	}
+	if string(data[0:4]) != "RIFF" {
+		return header, nil, fmt.Errorf("missing RIFF signature")
+	}
+	if string(data[8:12]) != "WAVE" {
+		return header, nil, fmt.Errorf("RIFF file is not WAVE but %q", data[8:12])
+	}
+	copy(header.RiffHeader[:], data[0:4])
+	header.FileSize = binary.LittleEndian.Uint32(data[4:8])
+	copy(header.WaveHeader[:], data[8:12])
+
+	haveFmt := false
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		if id != "data" && body+size > len(data) {
+			return header, nil, fmt.Errorf("%q chunk of %d bytes runs past end of file", id, size)
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return header, nil, fmt.Errorf("fmt chunk too short: %d bytes", size)
+			}
+			chunk := data[body : body+size]
+			copy(header.FmtHeader[:], id)
+			header.FmtChunkSize = uint32(size)
+			header.AudioFormat = binary.LittleEndian.Uint16(chunk[0:2])
+			header.NumChannels = binary.LittleEndian.Uint16(chunk[2:4])
+			header.SampleRate = binary.LittleEndian.Uint32(chunk[4:8])
+			header.ByteRate = binary.LittleEndian.Uint32(chunk[8:12])
+			header.BlockAlign = binary.LittleEndian.Uint16(chunk[12:14])
+			header.BitsPerSample = binary.LittleEndian.Uint16(chunk[14:16])
+			if header.AudioFormat == wavFormatExtensible {
+				// cbSize, valid bits and channel mask come before the SubFormat GUID
+				if size < 40 {
+					return header, nil, fmt.Errorf("extensible fmt chunk too short: %d bytes", size)
+				}
+				header.AudioFormat = binary.LittleEndian.Uint16(chunk[24:26])
+			}
+			if header.NumChannels == 0 || header.BlockAlign == 0 {
+				return header, nil, fmt.Errorf("fmt chunk has %d channels and block align %d", header.NumChannels, header.BlockAlign)
+			}
+			haveFmt = true
+		case "data":
+			if !haveFmt {
+				return header, nil, fmt.Errorf("data chunk before fmt chunk")
+			}
+			if body+size > len(data) {
+				return header, nil, fmt.Errorf("data chunk truncated: header says %d bytes, %d present", size, len(data)-body)
+			}
+			copy(header.DataHeader[:], id)
+			header.DataSize = uint32(size)
+			return header, data[body : body+size], nil
+		}
+
+		// Chunks are padded to an even number of bytes
+		offset = body + size + size%2
+	}
+
+	if !haveFmt {
+		return header, nil, fmt.Errorf("missing fmt chunk")
+	}
+	return header, nil, fmt.Errorf("missing data chunk")
+}
+
+// checkSampleRate logs a warning when the rate a caller claims for audio differs from
+// the rate it was actually recorded at. A WAV header with the wrong rate makes Whisper
+// hear the audio sped up or slowed down. Returns true if the rates match.
+func checkSampleRate(caller string, claimed uint32, actual uint32) bool {
+	if claimed == actual {
+		return true
+	}
+	Warnf("%s: sample rate mismatch, claimed %d Hz but audio is %d Hz", caller, claimed, actual)
+	return false
+}
+
+// wavDuration returns the duration of a WAV file written by CreateWAVFile, read from its header
+func wavDuration(path string) (time.Duration, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var header WAVHeader
+	if err := binary.Read(file, binary.LittleEndian, &header); err != nil {
+		return 0, fmt.Errorf("failed to read WAV header: %v", err)
+	}
+	if string(header.RiffHeader[:]) != "RIFF" || header.ByteRate == 0 {
+		return 0, fmt.Errorf("invalid WAV header in %s", path)
+	}
+	return time.Duration(header.DataSize) * time.Second / time.Duration(header.ByteRate), nil
+}
+
+// downmixToMono averages interleaved samples of numChannels channels into one channel.
+// Mono input is returned unchanged.
+func downmixToMono(samples []int16, numChannels uint16) []int16 {
+	channels := int(numChannels)
+	if channels <= 1 {
+		return samples
+	}
+	mono := make([]int16, len(samples)/channels)
+	for i := range mono {
+		sum := 0
+		for c := 0; c < channels; c++ {
+			sum += int(samples[i*channels+c])
+		}
+		mono[i] = int16(sum / channels)
+	}
+	return mono
+}
@@ -0,0 +1,236 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"strings"
+	"testing"
+)
+
+// wavChunk returns a RIFF chunk with its id, little-endian size and padding byte
+func wavChunk(id string, body []byte) []byte {
+	chunk := make([]byte, 8, 8+len(body)+1)
+	copy(chunk, id)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(body)))
+	chunk = append(chunk, body...)
+	if len(body)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// riffWAVE wraps chunks in a RIFF/WAVE container
+func riffWAVE(chunks ...[]byte) []byte {
+	body := []byte("WAVE")
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+	return wavChunk("RIFF", body)
+}
+
+// pcmFmt returns the body of a 16-byte fmt chunk
+func pcmFmt(format, channels uint16, sampleRate uint32, bits uint16) []byte {
+	body := make([]byte, 16)
+	blockAlign := channels * bits / 8
+	binary.LittleEndian.PutUint16(body[0:], format)
+	binary.LittleEndian.PutUint16(body[2:], channels)
+	binary.LittleEndian.PutUint32(body[4:], sampleRate)
+	binary.LittleEndian.PutUint32(body[8:], sampleRate*uint32(blockAlign))
+	binary.LittleEndian.PutUint16(body[12:], blockAlign)
+	binary.LittleEndian.PutUint16(body[14:], bits)
+	return body
+}
+
+func TestParseWAVCreateWAVFile(t *testing.T) {
+	pcm := samplesToPCM([]int16{0, 1000, -1000, 32767, -32768, 42})
+	wav := CreateWAVFile(pcm, 22050, 2)
+
+	header, data, err := ParseWAV(wav)
+	if err != nil {
+		t.Fatalf("ParseWAV: %v", err)
+	}
+	if !bytes.Equal(data, pcm) {
+		t.Errorf("ParseWAV returned %d bytes of PCM that differ from the %d written", len(data), len(pcm))
+	}
+
+	// The parsed header must match the one CreateWAVFile wrote byte for byte
+	var written WAVHeader
+	if err := binary.Read(bytes.NewReader(wav), binary.LittleEndian, &written); err != nil {
+		t.Fatalf("reading the written header: %v", err)
+	}
+	if header != written {
+		t.Errorf("ParseWAV header = %+v, want %+v", header, written)
+	}
+}
+
+func TestParseWAVSkipsChunksBeforeData(t *testing.T) {
+	pcm := samplesToPCM([]int16{1, 2, 3, 4})
+	wav := riffWAVE(
+		wavChunk("fmt ", pcmFmt(wavFormatPCM, 1, 16000, 16)),
+		wavChunk("LIST", []byte("INFOISFT\x05\x00\x00\x00Lavf\x00")), // Odd size, padded
+		wavChunk("fact", []byte{4, 0, 0, 0}),
+		wavChunk("data", pcm),
+	)
+
+	header, data, err := ParseWAV(wav)
+	if err != nil {
+		t.Fatalf("ParseWAV: %v", err)
+	}
+	if !bytes.Equal(data, pcm) {
+		t.Errorf("ParseWAV data = %v, want %v", data, pcm)
+	}
+	if header.SampleRate != 16000 || header.NumChannels != 1 || header.BitsPerSample != 16 || header.DataSize != uint32(len(pcm)) {
+		t.Errorf("ParseWAV header = %+v", header)
+	}
+}
+
+func TestParseWAVExtensibleFormat(t *testing.T) {
+	// WAVE_FORMAT_EXTENSIBLE as written by ffmpeg for 32-bit float
+	fmtBody := pcmFmt(wavFormatExtensible, 2, 48000, 32)
+	extension := make([]byte, 24)
+	binary.LittleEndian.PutUint16(extension[0:], 22)                 // cbSize
+	binary.LittleEndian.PutUint16(extension[2:], 32)                 // Valid bits per sample
+	binary.LittleEndian.PutUint32(extension[4:], 0x3)                // Front left and right
+	binary.LittleEndian.PutUint16(extension[8:], wavFormatIEEEFloat) // SubFormat GUID starts with the format code
+	fmtBody = append(fmtBody, extension...)
+
+	header, _, err := ParseWAV(riffWAVE(wavChunk("fmt ", fmtBody), wavChunk("data", make([]byte, 16))))
+	if err != nil {
+		t.Fatalf("ParseWAV: %v", err)
+	}
+	if header.AudioFormat != wavFormatIEEEFloat || header.FmtChunkSize != 40 {
+		t.Errorf("ParseWAV AudioFormat = %d, FmtChunkSize = %d; want %d, 40", header.AudioFormat, header.FmtChunkSize, wavFormatIEEEFloat)
+	}
+}
+
+func TestParseWAVRejectsMalformedFiles(t *testing.T) {
+	fmtChunk := wavChunk("fmt ", pcmFmt(wavFormatPCM, 1, 16000, 16))
+	truncatedData := wavChunk("data", make([]byte, 8))[:12]
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"too short", []byte("RIFF"), "too short"},
+		{"not RIFF", append([]byte("RIFX\x00\x00\x00\x00WAVE"), fmtChunk...), "RIFF signature"},
+		{"not WAVE", []byte("RIFF\x04\x00\x00\x00AVI "), "not WAVE"},
+		{"no fmt", riffWAVE(wavChunk("LIST", []byte("INFO"))), "missing fmt"},
+		{"no data", riffWAVE(fmtChunk), "missing data"},
+		{"data before fmt", riffWAVE(wavChunk("data", make([]byte, 4)), fmtChunk), "before fmt"},
+		{"short fmt", riffWAVE(wavChunk("fmt ", make([]byte, 12))), "fmt chunk too short"},
+		{"zero channels", riffWAVE(wavChunk("fmt ", pcmFmt(wavFormatPCM, 0, 16000, 16))), "channels"},
+		{"truncated data", riffWAVE(fmtChunk, truncatedData), "truncated"},
+		{"chunk past end", riffWAVE(wavChunk("LIST", []byte("INFO")))[:22], "past end"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseWAV(tt.data)
+			if err == nil {
+				t.Fatalf("ParseWAV accepted a malformed file")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseWAV error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateWAVFileBitsRoundTrip(t *testing.T) {
+	tests := []struct {
+		bits       uint16
+		channels   uint16
+		wantFormat uint16
+	}{
+		{16, 1, wavFormatPCM},
+		{24, 2, wavFormatPCM},
+		{32, 2, wavFormatIEEEFloat},
+	}
+	for _, tt := range tests {
+		blockAlign := int(tt.channels) * int(tt.bits) / 8
+		audio := make([]byte, 10*blockAlign)
+		for i := range audio {
+			audio[i] = byte(i * 7)
+		}
+
+		wav, err := CreateWAVFileBits(audio, 44100, tt.channels, tt.bits)
+		if err != nil {
+			t.Fatalf("CreateWAVFileBits(%d bits): %v", tt.bits, err)
+		}
+		header, data, err := ParseWAV(wav)
+		if err != nil {
+			t.Fatalf("ParseWAV(%d bits): %v", tt.bits, err)
+		}
+		if header.AudioFormat != tt.wantFormat || header.BitsPerSample != tt.bits || header.NumChannels != tt.channels {
+			t.Errorf("%d bits: format %d, %d bits, %d channels; want %d, %d, %d",
+				tt.bits, header.AudioFormat, header.BitsPerSample, header.NumChannels, tt.wantFormat, tt.bits, tt.channels)
+		}
+		if header.BlockAlign != uint16(blockAlign) || header.ByteRate != 44100*uint32(blockAlign) {
+			t.Errorf("%d bits: BlockAlign %d, ByteRate %d; want %d, %d",
+				tt.bits, header.BlockAlign, header.ByteRate, blockAlign, 44100*blockAlign)
+		}
+		if !bytes.Equal(data, audio) {
+			t.Errorf("%d bits: audio changed in the round trip", tt.bits)
+		}
+	}
+}
+
+func TestCreateWAVFileBitsRejectsInvalidInput(t *testing.T) {
+	if _, err := CreateWAVFileBits(make([]byte, 8), 16000, 1, 8); err == nil {
+		t.Error("CreateWAVFileBits accepted 8 bits per sample")
+	}
+	if _, err := CreateWAVFileBits(make([]byte, 8), 16000, 0, 16); err == nil {
+		t.Error("CreateWAVFileBits accepted 0 channels")
+	}
+	// 24-bit stereo frames are 6 bytes
+	if _, err := CreateWAVFileBits(make([]byte, 8), 16000, 2, 24); err == nil {
+		t.Error("CreateWAVFileBits accepted data that is not a whole number of frames")
+	}
+}
+
+func TestCheckSampleRateCatchesWrongRateWAV(t *testing.T) {
+	var buf bytes.Buffer
+	GetLogger().logger.SetOutput(&buf)
+	defer GetLogger().logger.SetOutput(os.Stderr)
+
+	// One second recorded at 48 kHz, but labelled with the old hardcoded 16 kHz
+	const streamRate = 48000
+	pcm := make([]byte, 2*streamRate)
+	header, _, err := ParseWAV(CreateWAVFile(pcm, 16000, 1))
+	if err != nil {
+		t.Fatalf("ParseWAV: %v", err)
+	}
+
+	if checkSampleRate("test", header.SampleRate, streamRate) {
+		t.Error("checkSampleRate accepted a WAV labelled 16000 Hz for 48000 Hz audio")
+	}
+	if !strings.Contains(buf.String(), "sample rate mismatch, claimed 16000 Hz but audio is 48000 Hz") {
+		t.Errorf("mismatch was not logged, got %q", buf.String())
+	}
+
+	buf.Reset()
+	header, _, err = ParseWAV(CreateWAVFile(pcm, streamRate, 1))
+	if err != nil {
+		t.Fatalf("ParseWAV: %v", err)
+	}
+	if !checkSampleRate("test", header.SampleRate, streamRate) {
+		t.Error("checkSampleRate rejected a WAV with the stream's rate")
+	}
+	if strings.Contains(buf.String(), "sample rate mismatch") {
+		t.Errorf("matching rates logged %q", buf.String())
+	}
+}
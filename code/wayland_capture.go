@@ -0,0 +1,79 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+var (
+	grimOnce  sync.Once
+	grimFound bool
+)
+
+// waylandSession reports whether the app runs under Wayland, where robotgo's
+// X11 screen capture only returns black frames
+func waylandSession() bool {
+	return os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// grimAvailable reports whether the grim screenshot tool can be run. The lookup is done once.
+func grimAvailable() bool {
+	grimOnce.Do(func() {
+		path, err := exec.LookPath("grim")
+		grimFound = err == nil
+		if grimFound {
+			Infof("Using grim at %s for Wayland screen capture", path)
+		} else {
+			Warnf("Wayland session detected but grim is not installed; screenshots may be black")
+		}
+	})
+	return grimFound
+}
+
+// captureRegionWithGrim captures a region given in global coordinates with grim
+// and returns it as PNG
+func captureRegionWithGrim(x, y, width, height int) ([]byte, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid capture region %dx%d", width, height)
+	}
+
+	cmd := exec.Command("grim", "-g", fmt.Sprintf("%d,%d %dx%d", x, y, width, height), "-t", "png", "-")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("grim failed: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// captureWayland captures a region with grim when running under Wayland.
+// It returns false when the X11 (robotgo) path should be used instead.
+func captureWayland(x, y, width, height int) ([]byte, bool) {
+	if !waylandSession() || !grimAvailable() {
+		return nil, false
+	}
+	data, err := captureRegionWithGrim(x, y, width, height)
+	if err != nil {
+		Warnf("Wayland capture failed, falling back to robotgo: %v", err)
+		return nil, false
+	}
+	Infof("Captured %dx%d region at %d,%d with grim (%d bytes)", width, height, x, y, len(data))
+	return data, true
+}
@@ -0,0 +1,182 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultWhisperCppBinary is the whisper.cpp command-line tool looked up in PATH
+// ("main" in whisper.cpp releases before it was renamed)
+const defaultWhisperCppBinary = "whisper-cli"
+
+// whisperCppSampleRate is the only sample rate whisper.cpp accepts
+const whisperCppSampleRate = 16000
+
+// errWhisperCppFailed marks failures of the whisper.cpp process. A missing model or
+// unsupported audio fails the same way every time, so these are not retried.
+var errWhisperCppFailed = errors.New("whisper.cpp transcription failed")
+
+// WhisperCppTranscriber transcribes offline by running the whisper.cpp command-line tool
+type WhisperCppTranscriber struct {
+	binary  string // Resolved path of the whisper.cpp executable
+	model   string // ggml model file passed with -m
+	storage *AudioStorage
+}
+
+// whisperCppOutput is the file whisper.cpp writes with -oj
+type whisperCppOutput struct {
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"` // Milliseconds
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+// NewWhisperCppTranscriber checks that the whisper.cpp binary and model exist.
+// storage converts recordings to the 16 kHz WAV whisper.cpp reads.
+func NewWhisperCppTranscriber(binary string, model string, storage *AudioStorage) (*WhisperCppTranscriber, error) {
+	if model == "" {
+		return nil, fmt.Errorf("MICAPP_WHISPER_CPP_MODEL is not set: point it to a ggml model file such as ggml-base.bin")
+	}
+	if _, err := os.Stat(model); err != nil {
+		return nil, fmt.Errorf("whisper.cpp model not found: %v", err)
+	}
+
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp binary %q not found: %v", binary, err)
+	}
+
+	return &WhisperCppTranscriber{
+		binary:  path,
+		model:   model,
+		storage: storage,
+	}, nil
+}
+
+// Transcribe runs whisper.cpp on the audio and returns the text
+func (w *WhisperCppTranscriber) Transcribe(ctx context.Context, audio []byte, filename string, language string, prompt string, onRequestSent ...func()) (string, error) {
+	text, _, err := w.TranscribeWithSegments(ctx, audio, filename, language, prompt, onRequestSent...)
+	return text, err
+}
+
+// TranscribeWithSegments runs whisper.cpp on the audio and returns the text with
+// its timed segments, read from the JSON file whisper.cpp writes
+func (w *WhisperCppTranscriber) TranscribeWithSegments(ctx context.Context, audio []byte, filename string, language string, prompt string, onRequestSent ...func()) (string, []TranscriptSegment, error) {
+	wavData, err := w.toWAV(audio, filename)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "micapp-whisper-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	inputPath := filepath.Join(dir, "audio.wav")
+	if err := os.WriteFile(inputPath, wavData, 0600); err != nil {
+		return "", nil, fmt.Errorf("failed to write audio for whisper.cpp: %v", err)
+	}
+	outputBase := filepath.Join(dir, "transcript")
+
+	if language == "" {
+		language = "auto"
+	}
+	args := []string{
+		"-m", w.model,
+		"-f", inputPath,
+		"-l", language,
+		"-oj",
+		"-of", outputBase,
+		"-np",
+	}
+	if prompt = truncatePrompt(prompt); prompt != "" {
+		args = append(args, "--prompt", prompt)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, localTranscriptionTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, w.binary, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", errWhisperCppFailed, err)
+	}
+	if len(onRequestSent) > 0 && onRequestSent[0] != nil {
+		onRequestSent[0]()
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+		Errorf("whisper.cpp failed: %v, stderr: %s", err, stderr.String())
+		return "", nil, fmt.Errorf("%w: %v", errWhisperCppFailed, err)
+	}
+
+	data, err := os.ReadFile(outputBase + ".json")
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: no output written: %v", errWhisperCppFailed, err)
+	}
+	return parseWhisperCppOutput(data)
+}
+
+// toWAV converts audio to the 16 kHz mono WAV whisper.cpp expects. Without
+// ffmpeg, WAV input is passed through as is and other formats are rejected.
+func (w *WhisperCppTranscriber) toWAV(audio []byte, filename string) ([]byte, error) {
+	pcm, err := w.storage.DecodeToPCM(audio, whisperCppSampleRate)
+	if err == nil {
+		return CreateWAVFile(pcm, whisperCppSampleRate, 1), nil
+	}
+	if strings.EqualFold(filepath.Ext(filename), ".wav") {
+		Warnf("Passing WAV to whisper.cpp unconverted: %v", err)
+		return audio, nil
+	}
+	return nil, fmt.Errorf("%w: cannot convert %s to WAV: %v", errWhisperCppFailed, filename, err)
+}
+
+// parseWhisperCppOutput reads the text and segments from whisper.cpp's JSON output
+func parseWhisperCppOutput(data []byte) (string, []TranscriptSegment, error) {
+	var output whisperCppOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return "", nil, fmt.Errorf("%w: failed to parse output JSON: %v", errWhisperCppFailed, err)
+	}
+
+	var text strings.Builder
+	segments := make([]TranscriptSegment, 0, len(output.Transcription))
+	for _, chunk := range output.Transcription {
+		text.WriteString(chunk.Text)
+		segments = append(segments, TranscriptSegment{
+			Start: time.Duration(chunk.Offsets.From) * time.Millisecond,
+			End:   time.Duration(chunk.Offsets.To) * time.Millisecond,
+			Text:  strings.TrimSpace(chunk.Text),
+		})
+	}
+
+	return strings.TrimSpace(text.String()), segments, nil
+}
@@ -0,0 +1,143 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Preference keys for the main window geometry, saved when the window is closed
+const (
+	windowWidthPrefKey  = "mainWindowWidth"
+	windowHeightPrefKey = "mainWindowHeight"
+	windowXPrefKey      = "mainWindowX"
+	windowYPrefKey      = "mainWindowY"
+	defaultWindowWidth  = 300
+	defaultWindowHeight = 700
+)
+
+// noWindowPosition marks a position that has never been saved. Negative
+// coordinates are valid on multi-monitor setups, so -1 can't be used.
+const noWindowPosition = math.MinInt32
+
+// restoreWindowSize sizes window as it was when last closed, or 300x700 the first time
+func restoreWindowSize(window fyne.Window, prefs fyne.Preferences) {
+	width := prefs.FloatWithFallback(windowWidthPrefKey, defaultWindowWidth)
+	height := prefs.FloatWithFallback(windowHeightPrefKey, defaultWindowHeight)
+	window.Resize(fyne.NewSize(float32(width), float32(height)))
+}
+
+// saveWindowGeometry remembers the window size. Fyne can't report where a
+// window is, so the position is only saved with the external fallback.
+func saveWindowGeometry(window fyne.Window, prefs fyne.Preferences, title string, externalPosition bool) {
+	if size := window.Canvas().Size(); size.Width > 0 && size.Height > 0 {
+		prefs.SetFloat(windowWidthPrefKey, float64(size.Width))
+		prefs.SetFloat(windowHeightPrefKey, float64(size.Height))
+	}
+	if !externalPosition || runtime.GOOS != "linux" {
+		return
+	}
+	x, y, err := externalWindowPosition(title)
+	if err != nil {
+		Warnf("Window position not saved: %v", err)
+		return
+	}
+	prefs.SetInt(windowXPrefKey, x)
+	prefs.SetInt(windowYPrefKey, y)
+	Debugf("Saved window position %d,%d", x, y)
+}
+
+// externalWindowPosition asks xdotool where the window titled title is
+func externalWindowPosition(title string) (int, int, error) {
+	out, err := exec.Command("xdotool", "search", "--limit", "1", "--name", "^"+title+"$", "getwindowgeometry", "--shell").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("xdotool failed: %v", err)
+	}
+	x, y := noWindowPosition, noWindowPosition
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "X":
+			x = n
+		case "Y":
+			y = n
+		}
+	}
+	if x == noWindowPosition || y == noWindowPosition {
+		return 0, 0, fmt.Errorf("no position in xdotool output %q", strings.TrimSpace(string(out)))
+	}
+	return x, y, nil
+}
+
+// restoreWindowPosition moves the window titled title back to its saved position
+// with wmctrl, or xdotool if wmctrl is missing. Fyne can't place windows itself,
+// so this is a Linux-only fallback; it runs shortly after the window is shown.
+func restoreWindowPosition(ctx context.Context, prefs fyne.Preferences, title string) {
+	if runtime.GOOS != "linux" {
+		return
+	}
+	x := prefs.IntWithFallback(windowXPrefKey, noWindowPosition)
+	y := prefs.IntWithFallback(windowYPrefKey, noWindowPosition)
+	if x == noWindowPosition || y == noWindowPosition {
+		return // Let the window manager place it
+	}
+
+	// Small delay to ensure window is fully created
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Static gravity (10) places the client area at x,y, which is what xdotool
+	// reported, so the window doesn't creep down by its title bar every launch
+	err := exec.Command("wmctrl", "-F", "-r", title, "-e", fmt.Sprintf("10,%d,%d,-1,-1", x, y)).Run()
+	if err == nil {
+		Debugf("Restored window position %d,%d", x, y)
+		return
+	}
+	Warnf("wmctrl failed, trying xdotool: %v", err)
+	err2 := exec.Command("xdotool", "search", "--limit", "1", "--name", "^"+title+"$", "windowmove", strconv.Itoa(x), strconv.Itoa(y)).Run()
+	if err2 != nil {
+		Warnf("Failed to restore window position: %v (wmctrl), %v (xdotool). Window may appear at default position.", err, err2)
+	}
+}
+
+// newWindowPositionCheck toggles saving and restoring the window position with wmctrl/xdotool
+func (a *AppState) newWindowPositionCheck(prefs fyne.Preferences) *widget.Check {
+	check := widget.NewCheck("Restore window position (Linux, needs wmctrl or xdotool)", nil)
+	check.SetChecked(a.config.WindowPosition)
+	check.OnChanged = func(enabled bool) {
+		a.config.WindowPosition = enabled
+		prefs.SetBool(windowPositionPrefKey, enabled)
+	}
+	return check
+}
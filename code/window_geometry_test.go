@@ -0,0 +1,47 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestWindowSizeRoundTrip(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	prefs := app.Preferences()
+
+	window := app.NewWindow("first run")
+	window.SetContent(widget.NewLabel(""))
+	restoreWindowSize(window, prefs)
+	if got := window.Canvas().Size(); got != fyne.NewSize(defaultWindowWidth, defaultWindowHeight) {
+		t.Errorf("first run size = %v, want %dx%d", got, defaultWindowWidth, defaultWindowHeight)
+	}
+
+	window.Resize(fyne.NewSize(420, 640))
+	saveWindowGeometry(window, prefs, "first run", false)
+	if prefs.IntWithFallback(windowXPrefKey, noWindowPosition) != noWindowPosition {
+		t.Error("position saved without the external fallback")
+	}
+
+	reopened := app.NewWindow("second run")
+	reopened.SetContent(widget.NewLabel(""))
+	restoreWindowSize(reopened, prefs)
+	if got := reopened.Canvas().Size(); got != fyne.NewSize(420, 640) {
+		t.Errorf("restored size = %v, want 420x640", got)
+	}
+}
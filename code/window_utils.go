@@ -0,0 +1,83 @@
+// MIT License
+// Copyright (c) 2024 VoiceTranscriber
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+package main
+
+import (
+	"os/exec"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"github.com/go-vgo/robotgo"
+)
+
+// appWindowTitle is the title of the main window
+const appWindowTitle = "MICAPP"
+
+// trackOtherWindow remembers which window takes the focus whenever MICAPP loses
+// it. Fyne reports this shortly after the focus has moved.
+func (a *AppState) trackOtherWindow(app fyne.App) {
+	app.Lifecycle().SetOnExitedForeground(func() {
+		// Reading the title may run xdotool, so it stays off the driver's goroutine
+		go func() {
+			if title := activeWindowTitle(); title != "" && title != appWindowTitle {
+				a.otherWindow.Store(title)
+			}
+		}()
+	})
+}
+
+// dictationWindowTitle returns the title of the window the user is dictating
+// into. That is the focused window, unless MICAPP itself has the focus because
+// the recording was started with its buttons; then it is the window that had
+// the focus before. An empty string is returned when neither is known.
+func (a *AppState) dictationWindowTitle() string {
+	title := activeWindowTitle()
+	if title != appWindowTitle {
+		return title
+	}
+	if other, ok := a.otherWindow.Load().(string); ok {
+		return other
+	}
+	return ""
+}
+
+// activeWindowTitle returns the title of the currently focused window.
+// It tries robotgo first and falls back to xdotool. An empty string is
+// returned when no title can be determined (e.g. headless sessions).
+func activeWindowTitle() (title string) {
+	defer func() {
+		// robotgo talks to the display server via cgo and may panic without one
+		if r := recover(); r != nil {
+			Infof("activeWindowTitle: robotgo panicked: %v", r)
+			title = activeWindowTitleXdotool()
+		}
+	}()
+
+	title = strings.TrimSpace(robotgo.GetTitle())
+	if title != "" {
+		return title
+	}
+
+	return activeWindowTitleXdotool()
+}
+
+// activeWindowTitleXdotool returns the focused window title using xdotool
+func activeWindowTitleXdotool() string {
+	out, err := exec.Command("xdotool", "getactivewindow", "getwindowname").Output()
+	if err != nil {
+		Warnf("activeWindowTitle: xdotool failed: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}